@@ -0,0 +1,78 @@
+/*
+Copyright (c) Facebook, Inc. and its affiliates.
+All rights reserved.
+
+This source code is licensed under the BSD-style license found in the
+LICENSE file in the root directory of this source tree.
+*/
+
+package cmd
+
+import (
+	"context"
+
+	"github.com/facebookincubator/fbender/cmd/core/agent"
+	"github.com/facebookincubator/fbender/cmd/scenario"
+	"github.com/spf13/cobra"
+)
+
+//nolint:gochecknoglobals
+var agentCmd = &cobra.Command{
+	Use:   "agent",
+	Short: "Runs load tests on behalf of a coordinator",
+	Long: `Runs load tests on behalf of a coordinator.
+
+A test command run with the agents flag becomes a coordinator. It splits the
+test value (QPS or workers) evenly among the agents, starts the test on all of
+them at once and merges the results they send back, so the summary and the
+constraints cover the whole load. Every job is run in a separate process with
+the protocol, targets and load generation flags of the coordinator, so the input
+files have to be present on the agents under the same paths. Flags writing files
+or running commands are never accepted from the coordinator, flags reading input
+or scenario files only if the agent has a secret.
+
+The agent listens on the loopback interface by default. Listening on any other
+address requires a secret shared with the coordinators (--secret-file on the
+agent and --agents-secret-file on the coordinator), the coordinator proves it
+knows the secret before the agent runs its job.`,
+	Example: `  fbender agent
+  fbender agent -l :7878 --secret-file secret.txt
+  fbender dns throughput fixed -t $TARGET --agents host1:7878,host2:7878 --agents-secret-file secret.txt 20000`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		listen, err := cmd.Flags().GetString("listen")
+		if err != nil {
+			//nolint:wrapcheck
+			return err
+		}
+
+		filename, err := cmd.Flags().GetString("secret-file")
+		if err != nil {
+			//nolint:wrapcheck
+			return err
+		}
+
+		secret, err := agent.ReadSecret(filename)
+		if err != nil {
+			//nolint:wrapcheck
+			return err
+		}
+
+		protocols := []string{scenario.Command.Name()}
+		for _, subcommand := range Subcommands {
+			protocols = append(protocols, subcommand.Name())
+		}
+
+		return agent.Serve(context.Background(), &agent.Config{Address: listen, Secret: secret, Protocols: protocols})
+	},
+}
+
+//nolint:gochecknoinits
+func init() {
+	agentCmd.Flags().StringP("listen", "l", "localhost:7878", "address to listen on for coordinators")
+	agentCmd.Flags().String("secret-file", "", "file with the secret shared with the coordinators")
+
+	if err := agentCmd.MarkFlagFilename("secret-file"); err != nil {
+		panic(err)
+	}
+}
@@ -18,6 +18,7 @@ import (
 	"github.com/facebookincubator/fbender/cmd/dhcpv6"
 	"github.com/facebookincubator/fbender/cmd/dns"
 	"github.com/facebookincubator/fbender/cmd/http"
+	"github.com/facebookincubator/fbender/cmd/scenario"
 	"github.com/facebookincubator/fbender/cmd/tftp"
 	"github.com/facebookincubator/fbender/cmd/udp"
 	"github.com/facebookincubator/fbender/flags"
@@ -47,11 +48,17 @@ control over the throughput (QPS), but not over concurrency. The second gives
 the user control over the concurrency but not over the throughput.
   * fixed - runs a single test for each of the specified values.
   * constraint - runs tests adjusting load based on the growth and constraints.
+  * profile - runs a single throughput test following a staged load profile.
+The scenario command mixes the load of multiple protocols and targets in a test.
 
 Target:
 Target format may vary depending on the protocol, however most of them accept
 ipv4, ipv6, hostname with an optional port. Use "fbender protocol --help" to get
-the documentation on the target format for a specific protocol.
+the documentation on the target format for a specific protocol. The load can be
+spread among multiple targets by repeating the target flag or by listing them in
+a targets file ("Target [Weight]" lines). Targets are selected round-robin,
+at random or at random proportionally to their weights (default 1), the summary
+then contains a per-target breakdown.
 
 Input:
 Unless explicitly stated in the command documentation one request is generated
@@ -76,23 +83,40 @@ other output. Available levels (both numbers and literals are accepted):
   fbender udp throughput fixed -t $TARGET -d 5m 100 200 300
   fbender http concurrency constraints -t $TARGET 20 -c "MAX(errors)<5"
   fbender dhcpv6 throughput constraints -t $TARGET 50 -c "MIN(latency)<20"
-  fbender dns throughput constraints -t $TARGET 40 -c -g ^10 "MAX(errors)<5"`,
+  fbender dns throughput constraints -t $TARGET 40 -c -g ^10 "MAX(errors)<5"
+  fbender dns throughput profile -t $TARGET "ramp 0->5000 over 2m, hold 10m"
+  fbender dns throughput fixed -t $TARGET1 -t "$TARGET2 3" --select weighted 100
+  fbender scenario throughput fixed -s scenario.yaml 1000
+  fbender run plan.yaml`,
 }
 
-func initIOFlags() {
+func initIOFlags(command *cobra.Command) {
 	// Input
-	Command.PersistentFlags().StringP("input", "i", "", "load test input data from a file (default <stdin>)")
+	command.PersistentFlags().StringP("input", "i", "", "load test input data from a file (default <stdin>)")
 
-	if err := Command.MarkPersistentFlagFilename("input"); err != nil {
+	if err := command.MarkPersistentFlagFilename("input"); err != nil {
 		panic(err)
 	}
 
+	// Input order
+	order := flags.NewDefaultOrder()
+	orderChoices := flags.ChoicesString(flags.OrderChoices())
+
+	command.PersistentFlags().Var(order, "order", fmt.Sprintf("order of the input lines %s", orderChoices))
+
+	if err := flags.BashCompletionOrder(command, command.PersistentFlags(), "order"); err != nil {
+		panic(err)
+	}
+
+	command.PersistentFlags().Int64("seed", 0, "seed of the random input orders (0 means random)")
+	command.PersistentFlags().Bool("stream", false, "read the input file lazily instead of loading it into memory")
+
 	// Output
 	logOutput := flags.NewLogOutput(logrus.StandardLogger())
 
-	Command.PersistentFlags().VarP(logOutput, "output", "o", "log test output to a file")
+	command.PersistentFlags().VarP(logOutput, "output", "o", "log test output to a file")
 
-	if err := Command.MarkPersistentFlagFilename("output"); err != nil {
+	if err := command.MarkPersistentFlagFilename("output"); err != nil {
 		panic(err)
 	}
 
@@ -100,9 +124,9 @@ func initIOFlags() {
 	logLevel := &flags.LogLevel{Logger: logrus.StandardLogger()}
 	logLevelChoices := flags.ChoicesString(flags.LogLevelChoices())
 
-	Command.PersistentFlags().VarP(logLevel, "verbosity", "v", fmt.Sprintf("verbosity level %s", logLevelChoices))
+	command.PersistentFlags().VarP(logLevel, "verbosity", "v", fmt.Sprintf("verbosity level %s", logLevelChoices))
 
-	if err := flags.BashCompletionLogLevel(Command, Command.PersistentFlags(), "verbosity"); err != nil {
+	if err := flags.BashCompletionLogLevel(command, command.PersistentFlags(), "verbosity"); err != nil {
 		panic(err)
 	}
 
@@ -110,50 +134,88 @@ func initIOFlags() {
 	logFormat := &flags.LogFormat{Logger: logrus.StandardLogger(), Format: "json"}
 	logFormatChoices := flags.ChoicesString(flags.LogFormatChoices())
 
-	Command.PersistentFlags().VarP(logFormat, "format", "f", fmt.Sprintf("output format %s", logFormatChoices))
+	command.PersistentFlags().VarP(logFormat, "format", "f", fmt.Sprintf("output format %s", logFormatChoices))
 
-	if err := flags.BashCompletionLogFormat(Command, Command.PersistentFlags(), "format"); err != nil {
+	if err := flags.BashCompletionLogFormat(command, command.PersistentFlags(), "format"); err != nil {
 		panic(err)
 	}
 }
 
-func initExecutionFlags() {
+func initExecutionFlags(command *cobra.Command) {
 	// Test duration
-	Command.PersistentFlags().DurationP("duration", "d", 1*time.Minute, "single test duration")
+	command.PersistentFlags().DurationP("duration", "d", 1*time.Minute, "single test duration")
+	command.PersistentFlags().Int("requests", 0, "end a test after this many requests instead (0 means duration bound)")
+	command.PersistentFlags().Duration("warmup", 0, "warm-up duration before each test, excluded from statistics")
+	command.PersistentFlags().Int("repeat", 1, "number of trials of every test")
+	command.PersistentFlags().Duration("cooldown", 0, "pause between the trials of a test")
 
 	// Requests distribution
 	distribution := flags.NewDefaultDistribution()
 	distributionChoices := flags.ChoicesString(flags.DistributionChoices())
 
-	Command.PersistentFlags().VarP(distribution, "dist", "D", fmt.Sprintf("requests distribution %s", distributionChoices))
+	command.PersistentFlags().VarP(distribution, "dist", "D", fmt.Sprintf("requests distribution %s", distributionChoices))
 
-	if err := flags.BashCompletionDistribution(Command, Command.PersistentFlags(), "dist"); err != nil {
+	if err := flags.BashCompletionDistribution(command, command.PersistentFlags(), "dist"); err != nil {
 		panic(err)
 	}
 
 	// Other settings
-	Command.PersistentFlags().IntP("buffer", "b", 2048, "buffer size of the requests generator channel")
-	Command.PersistentFlags().DurationP("timeout", "w", 1*time.Second, "wait timeout on requests")
-	Command.PersistentFlags().DurationP("unit", "u", 1*time.Millisecond, "histogram scaling unit")
-	Command.PersistentFlags().Bool("nostats", false, "disable statistics")
+	command.PersistentFlags().IntP("buffer", "b", 2048, "buffer size of the requests generator channel")
+	command.PersistentFlags().DurationP("timeout", "w", 1*time.Second, "wait timeout on requests")
+	command.PersistentFlags().Int("max-inflight", 0, "limit of in-flight requests in throughput tests (0 means no limit)")
+	command.PersistentFlags().Bool("drop", false, "drop requests over the in-flight limit instead of waiting")
+	command.PersistentFlags().Bool("fail-saturated", false, "fail a test if the load generator can't keep up with the QPS")
+	command.PersistentFlags().DurationP("unit", "u", 1*time.Millisecond, "histogram scaling unit")
+	command.PersistentFlags().Bool("nostats", false, "disable statistics")
+	command.PersistentFlags().Bool("dry-run", false, "check the test options without running the tests")
+	command.PersistentFlags().Duration("report-interval", 0, "print interim reports and detect drifts (soak tests)")
+	command.PersistentFlags().StringArray("proc", []string{}, "sample the resource usage of a local process (pid or name)")
+
+	// Distributed tests
+	command.PersistentFlags().StringSlice("agents", []string{}, "generate the load on the agents (host:port) instead")
+	command.PersistentFlags().String("agents-secret-file", "", "file with the secret shared with the agents")
+
+	if err := command.MarkPersistentFlagFilename("agents-secret-file"); err != nil {
+		panic(err)
+	}
+}
+
+func initTargetFlags(subcommand *cobra.Command) {
+	// Targets
+	subcommand.PersistentFlags().StringArrayP("target", "t", []string{},
+		"endpoint to load test, repeat to spread the load (optionally weighted: \"target weight\")")
+	subcommand.PersistentFlags().String("targets-file", "", "load test endpoints from a file")
+
+	if err := subcommand.MarkPersistentFlagFilename("targets-file"); err != nil {
+		panic(err)
+	}
+
+	// Target selection
+	selection := flags.NewDefaultSelection()
+	selectionChoices := flags.ChoicesString(flags.SelectionChoices())
+
+	subcommand.PersistentFlags().Var(selection, "select", fmt.Sprintf("target selection %s", selectionChoices))
+
+	if err := flags.BashCompletionSelection(subcommand, subcommand.PersistentFlags(), "select"); err != nil {
+		panic(err)
+	}
 }
 
 //nolint:gochecknoinits
 func init() {
 	cobra.EnablePrefixMatching = true
 
-	initIOFlags()
-	initExecutionFlags()
+	initIOFlags(Command)
+	initExecutionFlags(Command)
 
 	for _, subcommand := range Subcommands {
 		Command.AddCommand(subcommand)
-		subcommand.PersistentFlags().StringP("target", "t", "", "endpoint to load test")
-
-		if err := subcommand.MarkPersistentFlagRequired("target"); err != nil {
-			panic(err)
-		}
+		initTargetFlags(subcommand)
 	}
 
+	Command.AddCommand(agentCmd)
+	Command.AddCommand(runCmd)
+	Command.AddCommand(scenario.Command)
 	Command.AddCommand(completionCmd)
 	core.StartPostInit()
 }
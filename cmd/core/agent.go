@@ -0,0 +1,214 @@
+/*
+Copyright (c) Facebook, Inc. and its affiliates.
+All rights reserved.
+
+This source code is licensed under the BSD-style license found in the
+LICENSE file in the root directory of this source tree.
+*/
+
+package core
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/facebookincubator/fbender/cmd/core/agent"
+	"github.com/facebookincubator/fbender/cmd/core/options"
+	"github.com/facebookincubator/fbender/cmd/core/runner"
+	"github.com/facebookincubator/fbender/recorders"
+	"github.com/facebookincubator/fbender/tester"
+	"github.com/facebookincubator/fbender/tester/run"
+	"github.com/pinterest/bender"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// throughputRunner returns a runner generating the load either locally or on
+// the agents.
+func throughputRunner(p *runner.Params, o *options.Options) tester.ThroughputRunner {
+	if len(o.Agents) > 0 {
+		return runner.NewRemoteRunner(p, agent.Throughput, o.Agents)
+	}
+
+	return localThroughputRunner(p, agent.Throughput)
+}
+
+// profileRunner returns a runner following the load profile either locally or
+// on the agents.
+func profileRunner(p *runner.Params, o *options.Options) tester.ThroughputRunner {
+	if len(o.Agents) > 0 {
+		return runner.NewRemoteRunner(p, agent.Profile, o.Agents)
+	}
+
+	return localThroughputRunner(p, agent.Profile)
+}
+
+// concurrencyRunner returns a runner generating the load either locally or on
+// the agents.
+func concurrencyRunner(p *runner.Params, o *options.Options) tester.ConcurrencyRunner {
+	if len(o.Agents) > 0 {
+		return runner.NewRemoteRunner(p, agent.Concurrency, o.Agents)
+	}
+
+	return localConcurrencyRunner(p)
+}
+
+// saturationRunner is a throughput runner which reports how well it kept up
+// with the schedule.
+type saturationRunner interface {
+	tester.ThroughputRunner
+	Saturation() *recorders.Saturation
+}
+
+// localThroughputRunner returns a runner generating the load of the given kind
+// locally. The parts of a scenario are run at once by a scenario runner.
+func localThroughputRunner(p *runner.Params, kind string) saturationRunner {
+	if len(p.Parts) > 0 {
+		return runner.NewScenarioRunner(p, kind)
+	}
+
+	if kind == agent.Profile {
+		return runner.NewProfileRunner(p)
+	}
+
+	return runner.NewThroughputRunner(p)
+}
+
+// localConcurrencyRunner returns a runner generating the load locally. The parts
+// of a scenario are run at once by a scenario runner.
+func localConcurrencyRunner(p *runner.Params) tester.ConcurrencyRunner {
+	if len(p.Parts) > 0 {
+		return runner.NewScenarioRunner(p, agent.Concurrency)
+	}
+
+	return runner.NewConcurrencyRunner(p)
+}
+
+// remoteParams returns params for the coordinator, the agents create their own
+// testers and requests. The jobs sent to the agents carry the protocol of the
+// command and the allowed flags of the command line arguments.
+func remoteParams(cmd *cobra.Command, args []string, o *options.Options) (*runner.Params, error) {
+	p := &runner.Params{Job: &agent.Job{Protocol: cmd.Parent().Parent().Name()}}
+	if len(o.Targets) > 1 {
+		p.Targets = o.Targets.Addresses()
+	}
+
+	flags := pflag.NewFlagSet(cmd.Name(), pflag.ContinueOnError)
+	flags.AddFlagSet(cmd.Flags())
+
+	// The flags are only collected, their values are already set
+	err := flags.ParseAll(args, func(flag *pflag.Flag, value string) error {
+		if agent.AllowedFlag(flag.Name) {
+			p.Job.Flags = append(p.Job.Flags, agent.Flag{Name: flag.Name, Value: value})
+		}
+
+		return nil
+	})
+	if err != nil {
+		//nolint:wrapcheck
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// runWorker runs a single test of a job received by an agent. The cancel
+// function is called when the coordinator stops the test.
+func runWorker(ctx context.Context, cancel context.CancelFunc, w *agent.Worker, p *runner.Params,
+	o *options.Options) error {
+	share := w.Job.Share()
+
+	// The coordinator repeats the trials and reports the results, each of the
+	// trials is a separate job. It also decides if the combined load is
+	// saturated.
+	o.Repeat = 1
+	o.ReportInterval = 0
+	o.FailSaturated = false
+	o.Requests = w.Job.Split(o.Requests)
+
+	switch w.Job.Kind {
+	case agent.Throughput, agent.Profile:
+		if w.Job.Kind == agent.Profile {
+			o.Profile = o.Profile.Scale(w.Job.Fraction())
+		}
+
+		throughput := localThroughputRunner(p, w.Job.Kind)
+		var r tester.ThroughputRunner = &workerThroughputRunner{ThroughputRunner: throughput, worker: w, cancel: cancel}
+
+		if loader, ok := throughput.(tester.Loader); ok {
+			r = &workerThroughputLoader{ThroughputRunner: r, Loader: loader}
+		}
+
+		err := run.LoadTestThroughputFixed(ctx, r, o, share)
+		w.Saturation = throughput.Saturation()
+
+		return err
+	case agent.Concurrency:
+		concurrency := localConcurrencyRunner(p)
+		var r tester.ConcurrencyRunner = &workerConcurrencyRunner{ConcurrencyRunner: concurrency, worker: w, cancel: cancel}
+
+		if loader, ok := concurrency.(tester.Loader); ok {
+			r = &workerConcurrencyLoader{ConcurrencyRunner: r, Loader: loader}
+		}
+
+		return run.LoadTestConcurrencyFixed(ctx, r, o, share)
+	}
+
+	return fmt.Errorf("%w: unknown test kind: %q", agent.ErrAgent, w.Job.Kind)
+}
+
+// workerThroughputRunner waits for the coordinator to start the test and sends
+// the events back to it.
+type workerThroughputRunner struct {
+	tester.ThroughputRunner
+	worker *agent.Worker
+	cancel context.CancelFunc
+}
+
+func (r *workerThroughputRunner) Before(ctx context.Context, qps tester.QPS, options interface{}) error {
+	if err := r.ThroughputRunner.Before(ctx, qps, options); err != nil {
+		//nolint:wrapcheck
+		return err
+	}
+
+	return r.worker.Ready(r.cancel)
+}
+
+func (r *workerThroughputRunner) Recorders() []bender.Recorder {
+	return append(r.ThroughputRunner.Recorders(), r.worker.Recorder())
+}
+
+// workerConcurrencyRunner waits for the coordinator to start the test and sends
+// the events back to it.
+type workerConcurrencyRunner struct {
+	tester.ConcurrencyRunner
+	worker *agent.Worker
+	cancel context.CancelFunc
+}
+
+func (r *workerConcurrencyRunner) Before(ctx context.Context, workers tester.Workers, options interface{}) error {
+	if err := r.ConcurrencyRunner.Before(ctx, workers, options); err != nil {
+		//nolint:wrapcheck
+		return err
+	}
+
+	return r.worker.Ready(r.cancel)
+}
+
+func (r *workerConcurrencyRunner) Recorders() []bender.Recorder {
+	return append(r.ConcurrencyRunner.Recorders(), r.worker.Recorder())
+}
+
+// workerThroughputLoader is a workerThroughputRunner of a runner loading the
+// test on its own, e.g. the parts of a scenario.
+type workerThroughputLoader struct {
+	tester.ThroughputRunner
+	tester.Loader
+}
+
+// workerConcurrencyLoader is a workerConcurrencyRunner of a runner loading the
+// test on its own, e.g. the parts of a scenario.
+type workerConcurrencyLoader struct {
+	tester.ConcurrencyRunner
+	tester.Loader
+}
@@ -0,0 +1,348 @@
+/*
+Copyright (c) Facebook, Inc. and its affiliates.
+All rights reserved.
+
+This source code is licensed under the BSD-style license found in the
+LICENSE file in the root directory of this source tree.
+*/
+
+/*
+Package agent implements distributed load generation. A coordinator connects to
+every agent and sends it a job - the protocol, the targets and the allowed flags
+of the test along with the agent's share of the test value. Each agent runs the
+job in a separate worker process and streams the events back, the coordinator
+merges them and evaluates the constraints as if the whole load was generated
+locally.
+
+The messages are exchanged as JSON lines over TCP:
+  agent -> coordinator: challenge
+  coordinator -> agent: job (authenticated with the shared secret)
+  agent -> coordinator: ready (or done with an error)
+  coordinator -> agent: start (or stop)
+  agent -> coordinator: event, event, ..., done (with the saturation statistics)
+The coordinator may send stop at any time to interrupt the test.
+*/
+package agent
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"time"
+
+	"github.com/facebookincubator/fbender/recorders"
+	"github.com/facebookincubator/fbender/tester"
+	"github.com/pinterest/bender"
+)
+
+// Test kinds which determine the runner used by the worker.
+const (
+	Throughput  = "throughput"
+	Profile     = "profile"
+	Concurrency = "concurrency"
+)
+
+// Message types.
+const (
+	challengeMessage = "challenge"
+	jobMessage       = "job"
+	readyMessage     = "ready"
+	startMessage     = "start"
+	stopMessage      = "stop"
+	eventMessage     = "event"
+	doneMessage      = "done"
+)
+
+// allowedFlags lists the flags a coordinator may pass to the agents. Flags
+// writing files or running commands are never passed. The coordinator decides
+// whether the combined load is saturated on its own.
+//nolint:gochecknoglobals
+var allowedFlags = map[string]bool{
+	// Common flags
+	"input":        true,
+	"order":        true,
+	"seed":         true,
+	"stream":       true,
+	"requests":     true,
+	"warmup":       true,
+	"dist":         true,
+	"buffer":       true,
+	"timeout":      true,
+	"max-inflight": true,
+	"drop":         true,
+	"unit":         true,
+	"nostats":      true,
+	"select":       true,
+	// Protocol flags
+	"blocksize": true,
+	"oro":       true,
+	"protocol":  true,
+	"randomize": true,
+	"scenario":  true,
+	"ssl":       true,
+}
+
+// fileFlags lists the allowed flags making the agents send the contents of
+// their files to the targets. They are accepted only from the coordinators
+// authenticated with the secret, otherwise any local user could make
+// a loopback agent send any of its files to any target.
+//nolint:gochecknoglobals
+var fileFlags = map[string]bool{
+	"input":    true,
+	"scenario": true,
+}
+
+// AllowedFlag checks whether the flag may be passed to the agents.
+func AllowedFlag(name string) bool {
+	return allowedFlags[name]
+}
+
+// Flag is a command line flag of a job.
+type Flag struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Job describes a single test run by an agent.
+type Job struct {
+	// Protocol command and the kind of the test
+	Protocol string `json:"protocol"`
+	Kind     string `json:"kind"`
+	// Targets and duration of the test
+	Targets  tester.Targets `json:"targets,omitempty"`
+	Duration time.Duration  `json:"duration"`
+	// Stages of a profile test
+	Profile string `json:"profile,omitempty"`
+	// Other flags of the test, only the allowed flags are accepted
+	Flags []Flag `json:"flags,omitempty"`
+	// Test value (QPS or workers) for all the agents together
+	Test int `json:"test"`
+	// Index of the agent and the number of all agents
+	Agent  int `json:"agent"`
+	Agents int `json:"agents"`
+}
+
+// Share returns the part of the test value the agent is responsible for.
+func (j *Job) Share() int {
+	return j.Split(j.Test)
+}
+
+// Split returns the part of n the agent is responsible for, the parts of all
+// the agents add up to n.
+func (j *Job) Split(n int) int {
+	share := n / j.Agents
+	if j.Agent < n%j.Agents {
+		share++
+	}
+
+	return share
+}
+
+// Fraction returns the fraction of the load the agent is responsible for, the
+// fractions of all the agents add up to 1.
+func (j *Job) Fraction() float64 {
+	if j.Test <= 0 {
+		return 1. / float64(j.Agents)
+	}
+
+	return float64(j.Share()) / float64(j.Test)
+}
+
+// SetProfile sets the stages of a profile test.
+func (j *Job) SetProfile(profile tester.Profile) {
+	j.Profile = ""
+
+	for i, stage := range profile {
+		if i > 0 {
+			j.Profile += ", "
+		}
+
+		j.Profile += fmt.Sprintf("ramp %s->%s over %s",
+			strconv.FormatFloat(stage.From, 'f', -1, 64), strconv.FormatFloat(stage.To, 'f', -1, 64), stage.Duration)
+	}
+}
+
+// Command returns the command line arguments of the worker running the job.
+// Jobs of other than the configured protocols and jobs with flags which are
+// not allowed are rejected. The flags reading input files are allowed only if
+// the agent has a secret.
+func (j *Job) Command(config *Config) ([]string, error) {
+	known := false
+
+	for _, protocol := range config.Protocols {
+		known = known || protocol == j.Protocol
+	}
+
+	if !known {
+		return nil, fmt.Errorf("%w: unknown protocol: %q", ErrAgent, j.Protocol)
+	}
+
+	if j.Agents < 1 || j.Agent < 0 || j.Agent >= j.Agents {
+		return nil, fmt.Errorf("%w: invalid agent: %d/%d", ErrAgent, j.Agent, j.Agents)
+	}
+
+	var args []string
+
+	switch j.Kind {
+	case Throughput:
+		args = []string{j.Protocol, "throughput", "fixed"}
+	case Profile:
+		args = []string{j.Protocol, "throughput", "profile"}
+	case Concurrency:
+		args = []string{j.Protocol, "concurrency", "fixed"}
+	default:
+		return nil, fmt.Errorf("%w: unknown test kind: %q", ErrAgent, j.Kind)
+	}
+
+	args = append(args, fmt.Sprintf("--duration=%s", j.Duration))
+
+	for _, target := range j.Targets {
+		args = append(args, fmt.Sprintf("--target=%s %s",
+			target.Address, strconv.FormatFloat(target.Weight, 'f', -1, 64)))
+	}
+
+	for _, flag := range j.Flags {
+		if !AllowedFlag(flag.Name) {
+			return nil, fmt.Errorf("%w: flag not allowed: %q", ErrAgent, flag.Name)
+		}
+
+		if fileFlags[flag.Name] && len(config.Secret) == 0 {
+			return nil, fmt.Errorf("%w: flag %q requires the agent secret", ErrAgent, flag.Name)
+		}
+
+		args = append(args, fmt.Sprintf("--%s=%s", flag.Name, flag.Value))
+	}
+
+	args = append(args, "--")
+	if j.Kind == Profile {
+		return append(args, j.Profile), nil
+	}
+
+	return append(args, strconv.Itoa(j.Test)), nil
+}
+
+// Event is a serializable bender event. Only the events required to build the
+// statistics are sent, requests and responses are dropped apart from the
+// target of a multi target test.
+type Event struct {
+	Wait      int64  `json:"wait,omitempty"`
+	Overage   int64  `json:"overage,omitempty"`
+	Start     int64  `json:"start,omitempty"`
+	End       int64  `json:"end,omitempty"`
+	Scheduled int64  `json:"scheduled,omitempty"`
+	Target    string `json:"target,omitempty"`
+	Error     string `json:"error,omitempty"`
+	Class     string `json:"class,omitempty"`
+	Dropped   int64  `json:"dropped,omitempty"`
+}
+
+type message struct {
+	Type  string `json:"type"`
+	Job   *Job   `json:"job,omitempty"`
+	Event *Event `json:"event,omitempty"`
+	Error string `json:"error,omitempty"`
+	Nonce string `json:"nonce,omitempty"`
+	Auth  string `json:"auth,omitempty"`
+
+	Saturation *recorders.Saturation `json:"saturation,omitempty"`
+}
+
+// authenticate returns the proof of knowing the secret for the challenge
+// nonce.
+func authenticate(secret []byte, nonce string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(nonce))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// ReadSecret reads the secret shared by the agents and the coordinator from
+// a file, surrounding whitespace is ignored. Empty filename means no secret.
+func ReadSecret(filename string) ([]byte, error) {
+	if len(filename) == 0 {
+		return nil, nil
+	}
+
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		//nolint:wrapcheck
+		return nil, err
+	}
+
+	secret := bytes.TrimSpace(data)
+	if len(secret) == 0 {
+		return nil, fmt.Errorf("%w: empty secret file: %s", ErrAgent, filename)
+	}
+
+	return secret, nil
+}
+
+// ErrAgent is returned when an agent fails to run a job.
+var ErrAgent = errors.New("agent error")
+
+// encodeEvent converts a bender event to a serializable event, it returns nil
+// for events which are not sent.
+func encodeEvent(msg interface{}) *Event {
+	switch msg := msg.(type) {
+	case *bender.WaitEvent:
+		return &Event{Wait: msg.Wait, Overage: msg.Overage}
+	case *bender.EndRequestEvent:
+		event := &Event{Start: msg.Start, End: msg.End}
+		if response, ok := msg.Response.(*tester.TargetedResponse); ok {
+			event.Target = response.Target
+		}
+
+		if msg.Err != nil {
+			event.Error, event.Class = msg.Err.Error(), tester.ClassifyError(msg.Err)
+		}
+
+		return event
+	case *tester.ScheduledRequestEvent:
+		event := &Event{Scheduled: msg.Scheduled, Start: msg.Start, End: msg.End}
+		if msg.Err != nil {
+			event.Error, event.Class = msg.Err.Error(), tester.ClassifyError(msg.Err)
+		}
+
+		return event
+	case *tester.DroppedRequestEvent:
+		return &Event{Dropped: msg.Time}
+	}
+
+	return nil
+}
+
+// remoteError is an error received from an agent.
+type remoteError string
+
+func (e remoteError) Error() string {
+	return string(e)
+}
+
+// decode converts a serializable event back to a bender event. The errors keep
+// the class they were given by the agent.
+func (e *Event) decode() interface{} {
+	var err error
+	if len(e.Error) > 0 {
+		err = tester.WithErrorClass(remoteError(e.Error), e.Class)
+	}
+
+	switch {
+	case e.Dropped != 0:
+		return &tester.DroppedRequestEvent{Time: e.Dropped}
+	case e.End == 0:
+		return &bender.WaitEvent{Wait: e.Wait, Overage: e.Overage}
+	case e.Scheduled != 0:
+		return &tester.ScheduledRequestEvent{Scheduled: e.Scheduled, Start: e.Start, End: e.End, Err: err}
+	case len(e.Target) > 0:
+		return &bender.EndRequestEvent{
+			Start: e.Start, End: e.End, Response: &tester.TargetedResponse{Target: e.Target}, Err: err,
+		}
+	default:
+		return &bender.EndRequestEvent{Start: e.Start, End: e.End, Err: err}
+	}
+}
@@ -0,0 +1,100 @@
+/*
+Copyright (c) Facebook, Inc. and its affiliates.
+All rights reserved.
+
+This source code is licensed under the BSD-style license found in the
+LICENSE file in the root directory of this source tree.
+*/
+
+package agent_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/facebookincubator/fbender/cmd/core/agent"
+	"github.com/facebookincubator/fbender/tester"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJob__Share(t *testing.T) {
+	shares := []int{}
+
+	for i := 0; i < 3; i++ {
+		job := &agent.Job{Test: 100, Agent: i, Agents: 3}
+		shares = append(shares, job.Share())
+	}
+
+	assert.Equal(t, []int{34, 33, 33}, shares)
+}
+
+func TestJob__Fraction(t *testing.T) {
+	fractions := []float64{}
+
+	for i := 0; i < 3; i++ {
+		job := &agent.Job{Test: 100, Agent: i, Agents: 3}
+		fractions = append(fractions, job.Fraction())
+	}
+
+	// The fractions follow the shares, so the scaled profiles add up
+	assert.Equal(t, []float64{0.34, 0.33, 0.33}, fractions)
+}
+
+func TestJob__Split(t *testing.T) {
+	parts := []int{}
+
+	for i := 0; i < 4; i++ {
+		job := &agent.Job{Test: 100, Agent: i, Agents: 4}
+		parts = append(parts, job.Split(1001))
+	}
+
+	assert.Equal(t, []int{251, 250, 250, 250}, parts)
+}
+
+func TestJob__Command(t *testing.T) {
+	job := &agent.Job{
+		Protocol: "dns",
+		Kind:     agent.Throughput,
+		Targets:  tester.Targets{{Address: "::1", Weight: 2}},
+		Duration: time.Minute,
+		Flags:    []agent.Flag{{Name: "input", Value: "queries.txt"}},
+		Test:     100,
+		Agent:    1,
+		Agents:   2,
+	}
+
+	config := &agent.Config{Secret: []byte("secret"), Protocols: []string{"dns", "http"}}
+	args, err := job.Command(config)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"dns", "throughput", "fixed", "--duration=1m0s", "--target=::1 2", "--input=queries.txt", "--", "100",
+	}, args)
+
+	job.Kind = agent.Profile
+	job.SetProfile(tester.Profile{{From: 0, To: 1e6, Duration: time.Minute}, {From: 1e6, To: 1e6, Duration: time.Hour}})
+	args, err = job.Command(config)
+	require.NoError(t, err)
+	assert.Equal(t, "ramp 0->1000000 over 1m0s, ramp 1000000->1000000 over 1h0m0s", args[len(args)-1])
+}
+
+func TestJob__Command_Rejected(t *testing.T) {
+	jobs := []*agent.Job{
+		{Protocol: "run", Kind: agent.Throughput, Agents: 1},
+		{Protocol: "dns", Kind: "shell", Agents: 1},
+		{Protocol: "dns", Kind: agent.Throughput, Agent: 1, Agents: 1},
+		{Protocol: "dns", Kind: agent.Throughput, Agents: 1, Flags: []agent.Flag{{Name: "output", Value: "/etc/passwd"}}},
+	}
+
+	for _, job := range jobs {
+		_, err := job.Command(&agent.Config{Secret: []byte("secret"), Protocols: []string{"dns"}})
+		assert.ErrorIs(t, err, agent.ErrAgent)
+	}
+
+	// The input files are read only for the coordinators knowing the secret
+	for _, name := range []string{"input", "scenario"} {
+		job := &agent.Job{Protocol: "dns", Kind: agent.Throughput, Agents: 1, Flags: []agent.Flag{{Name: name}}}
+		_, err := job.Command(&agent.Config{Protocols: []string{"dns"}})
+		assert.ErrorIs(t, err, agent.ErrAgent, name)
+	}
+}
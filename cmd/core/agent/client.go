@@ -0,0 +1,131 @@
+/*
+Copyright (c) Facebook, Inc. and its affiliates.
+All rights reserved.
+
+This source code is licensed under the BSD-style license found in the
+LICENSE file in the root directory of this source tree.
+*/
+
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/facebookincubator/fbender/recorders"
+)
+
+// dialTimeout is the timeout for connecting to an agent.
+const dialTimeout = 10 * time.Second
+
+// Conn is a coordinator connection to an agent.
+type Conn struct {
+	Address string
+	// Saturation is the saturation reported by the agent once a throughput
+	// test is done
+	Saturation *recorders.Saturation
+
+	conn    net.Conn
+	encoder *json.Encoder
+	decoder *json.Decoder
+	lock    sync.Mutex
+}
+
+// Dial connects to the agent, sends it the job authenticated with the secret
+// and waits until the agent is ready to start the test.
+func Dial(address string, job *Job, secret []byte) (*Conn, error) {
+	conn, err := net.DialTimeout("tcp", address, dialTimeout)
+	if err != nil {
+		//nolint:wrapcheck
+		return nil, err
+	}
+
+	c := &Conn{
+		Address: address,
+		conn:    conn,
+		encoder: json.NewEncoder(conn),
+		decoder: json.NewDecoder(conn),
+	}
+
+	challenge := new(message)
+	if err := c.decoder.Decode(challenge); err != nil || challenge.Type != challengeMessage {
+		c.Close()
+
+		return nil, fmt.Errorf("%w, %s: invalid challenge", ErrAgent, address)
+	}
+
+	auth := authenticate(secret, challenge.Nonce)
+	if err := c.send(&message{Type: jobMessage, Job: job, Auth: auth}); err != nil {
+		c.Close()
+
+		return nil, err
+	}
+
+	msg := new(message)
+	if err := c.decoder.Decode(msg); err != nil {
+		c.Close()
+
+		return nil, fmt.Errorf("%w, %s: %v", ErrAgent, address, err)
+	}
+
+	if msg.Type != readyMessage {
+		c.Close()
+
+		return nil, fmt.Errorf("%w, %s: %s", ErrAgent, address, msg.Error)
+	}
+
+	return c, nil
+}
+
+func (c *Conn) send(msg *message) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	//nolint:wrapcheck
+	return c.encoder.Encode(msg)
+}
+
+// Start starts the test.
+func (c *Conn) Start() error {
+	return c.send(&message{Type: startMessage})
+}
+
+// Stop interrupts the test.
+func (c *Conn) Stop() error {
+	return c.send(&message{Type: stopMessage})
+}
+
+// Events reads the events sent by the agent and passes them to the recorder
+// until the test is done. It returns an error if the agent fails to run it.
+func (c *Conn) Events(recorder chan interface{}) error {
+	for {
+		msg := new(message)
+		if err := c.decoder.Decode(msg); err != nil {
+			return fmt.Errorf("%w, %s: %v", ErrAgent, c.Address, err)
+		}
+
+		switch msg.Type {
+		case eventMessage:
+			if msg.Event != nil {
+				recorder <- msg.Event.decode()
+			}
+		case doneMessage:
+			c.Saturation = msg.Saturation
+
+			if len(msg.Error) > 0 {
+				return fmt.Errorf("%w, %s: %s", ErrAgent, c.Address, msg.Error)
+			}
+
+			return nil
+		}
+	}
+}
+
+// Close closes the connection.
+func (c *Conn) Close() {
+	// Errors are irrelevant at this point, the agent cleans up on its own
+	_ = c.conn.Close()
+}
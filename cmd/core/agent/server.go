@@ -0,0 +1,191 @@
+/*
+Copyright (c) Facebook, Inc. and its affiliates.
+All rights reserved.
+
+This source code is licensed under the BSD-style license found in the
+LICENSE file in the root directory of this source tree.
+*/
+
+package agent
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/facebookincubator/fbender/log"
+)
+
+// Every job is run in a separate worker process, the job is passed in the
+// environment and the connection to the coordinator as an extra file.
+const (
+	envJob   = "FBENDER_AGENT_JOB"
+	workerFD = 3
+)
+
+// nonceSize is the size of the challenge nonce in bytes.
+const nonceSize = 32
+
+// Config represents the agent configuration.
+type Config struct {
+	// Address to listen on for coordinators
+	Address string
+	// Secret shared with the coordinators, required unless the agent listens
+	// on a loopback address and to accept the flags reading input files
+	Secret []byte
+	// Protocols the coordinators may run the tests of
+	Protocols []string
+}
+
+// Serve accepts coordinator connections and runs their jobs. It returns once
+// the context is canceled.
+func Serve(ctx context.Context, config *Config) error {
+	listener, err := net.Listen("tcp", config.Address)
+	if err != nil {
+		//nolint:wrapcheck
+		return err
+	}
+
+	if addr, ok := listener.Addr().(*net.TCPAddr); len(config.Secret) == 0 && (!ok || !addr.IP.IsLoopback()) {
+		// Errors are irrelevant at this point, the agent is not started
+		_ = listener.Close()
+
+		return fmt.Errorf("%w: a secret is required to listen on %s", ErrAgent, listener.Addr())
+	}
+
+	log.Errorf("Agent listening on %s\n", listener.Addr())
+
+	go func() {
+		<-ctx.Done()
+
+		if err := listener.Close(); err != nil {
+			log.Errorf("Warning: Error closing the listener: %v\n", err)
+		}
+	}()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			//nolint:wrapcheck
+			return err
+		}
+
+		go handle(conn, config)
+	}
+}
+
+// handle authenticates the coordinator, reads a job from the connection and
+// runs it in a worker process. The coordinator is notified if the job is
+// rejected or the worker exits without reporting the result.
+func handle(conn net.Conn, config *Config) {
+	defer func() {
+		if err := conn.Close(); err != nil {
+			log.Errorf("Warning: Error closing the connection: %v\n", err)
+		}
+	}()
+
+	nonce := make([]byte, nonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		log.Errorf("Warning: Error generating the challenge: %v\n", err)
+
+		return
+	}
+
+	challenge := &message{Type: challengeMessage, Nonce: hex.EncodeToString(nonce)}
+	if err := json.NewEncoder(conn).Encode(challenge); err != nil {
+		log.Errorf("Warning: Error sending the challenge: %v\n", err)
+
+		return
+	}
+
+	msg := new(message)
+	if err := json.NewDecoder(conn).Decode(msg); err != nil || msg.Type != jobMessage || msg.Job == nil {
+		log.Errorf("Warning: Invalid job from %s\n", conn.RemoteAddr())
+
+		return
+	}
+
+	if len(config.Secret) > 0 && !hmac.Equal([]byte(msg.Auth), []byte(authenticate(config.Secret, challenge.Nonce))) {
+		log.Errorf("Warning: Unauthenticated job from %s\n", conn.RemoteAddr())
+		reject(conn, fmt.Errorf("%w: authentication failed", ErrAgent))
+
+		return
+	}
+
+	args, err := msg.Job.Command(config)
+	if err != nil {
+		log.Errorf("Warning: Invalid job from %s: %v\n", conn.RemoteAddr(), err)
+		reject(conn, err)
+
+		return
+	}
+
+	log.Errorf("Running job from %s: %s (%s %d/%d)\n", conn.RemoteAddr(),
+		strings.Join(args, " "), msg.Job.Kind, msg.Job.Share(), msg.Job.Test)
+
+	if err := spawn(conn, msg.Job, args); err != nil {
+		reject(conn, fmt.Errorf("worker failed: %w", err))
+	}
+}
+
+// reject reports the job failure to the coordinator.
+func reject(conn net.Conn, err error) {
+	done := &message{Type: doneMessage, Error: err.Error()}
+	if err := json.NewEncoder(conn).Encode(done); err != nil {
+		log.Errorf("Warning: Error sending the result: %v\n", err)
+	}
+}
+
+// spawn runs the job in a worker process with the arguments and waits for it
+// to finish.
+func spawn(conn net.Conn, job *Job, args []string) error {
+	tcp, ok := conn.(*net.TCPConn)
+	if !ok {
+		return fmt.Errorf("%w: unsupported connection: %T", ErrAgent, conn)
+	}
+
+	file, err := tcp.File()
+	if err != nil {
+		//nolint:wrapcheck
+		return err
+	}
+
+	defer func() {
+		if err := file.Close(); err != nil {
+			log.Errorf("Warning: Error closing the connection file: %v\n", err)
+		}
+	}()
+
+	data, err := json.Marshal(job)
+	if err != nil {
+		//nolint:wrapcheck
+		return err
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		//nolint:wrapcheck
+		return err
+	}
+
+	//nolint:gosec
+	cmd := exec.Command(executable, args...)
+	cmd.Env = append(os.Environ(), fmt.Sprintf("%s=%s", envJob, data))
+	cmd.ExtraFiles = []*os.File{file}
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	//nolint:wrapcheck
+	return cmd.Run()
+}
@@ -0,0 +1,134 @@
+/*
+Copyright (c) Facebook, Inc. and its affiliates.
+All rights reserved.
+
+This source code is licensed under the BSD-style license found in the
+LICENSE file in the root directory of this source tree.
+*/
+
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+
+	"github.com/facebookincubator/fbender/log"
+	"github.com/facebookincubator/fbender/recorders"
+	"github.com/pinterest/bender"
+)
+
+// Worker runs a job received by an agent and reports back to the coordinator.
+type Worker struct {
+	Job *Job
+	// Saturation is reported to the coordinator with the result of a throughput
+	// test, so it can tell whether all the agents kept up with the schedule
+	Saturation *recorders.Saturation
+
+	conn    net.Conn
+	encoder *json.Encoder
+	decoder *json.Decoder
+	lock    sync.Mutex
+}
+
+// CurrentWorker returns the worker if the process has been started by an
+// agent to run a job, nil otherwise.
+func CurrentWorker() (*Worker, error) {
+	data, ok := os.LookupEnv(envJob)
+	if !ok {
+		return nil, nil
+	}
+
+	job := new(Job)
+	if err := json.Unmarshal([]byte(data), job); err != nil {
+		return nil, fmt.Errorf("%w: invalid job: %v", ErrAgent, err)
+	}
+
+	conn, err := net.FileConn(os.NewFile(workerFD, "coordinator"))
+	if err != nil {
+		//nolint:wrapcheck
+		return nil, err
+	}
+
+	return &Worker{
+		Job:     job,
+		conn:    conn,
+		encoder: json.NewEncoder(conn),
+		decoder: json.NewDecoder(conn),
+	}, nil
+}
+
+func (w *Worker) send(msg *message) error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	//nolint:wrapcheck
+	return w.encoder.Encode(msg)
+}
+
+// Ready notifies the coordinator the test is prepared and waits until it's
+// started. The cancel function is called when the coordinator stops the test
+// or the connection is lost.
+func (w *Worker) Ready(cancel context.CancelFunc) error {
+	if err := w.send(&message{Type: readyMessage}); err != nil {
+		return err
+	}
+
+	msg := new(message)
+	if err := w.decoder.Decode(msg); err != nil {
+		cancel()
+
+		//nolint:wrapcheck
+		return err
+	}
+
+	if msg.Type != startMessage {
+		cancel()
+
+		return nil
+	}
+
+	go func() {
+		defer cancel()
+
+		for {
+			msg := new(message)
+			if err := w.decoder.Decode(msg); err != nil || msg.Type == stopMessage {
+				return
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Recorder returns a recorder which sends the events to the coordinator.
+func (w *Worker) Recorder() bender.Recorder {
+	return func(msg interface{}) {
+		if event := encodeEvent(msg); event != nil {
+			if err := w.send(&message{Type: eventMessage, Event: event}); err != nil {
+				log.Errorf("Warning: Error sending an event: %v\n", err)
+			}
+		}
+	}
+}
+
+// Done reports the result of the job to the coordinator and closes the
+// connection.
+func (w *Worker) Done(err error) {
+	done := &message{Type: doneMessage, Saturation: w.Saturation}
+	if err != nil {
+		done.Error = err.Error()
+	}
+
+	if err := w.send(done); err != nil {
+		log.Errorf("Warning: Error sending the result: %v\n", err)
+	}
+
+	if err := w.conn.Close(); err != nil {
+		log.Errorf("Warning: Error closing the connection: %v\n", err)
+	}
+}
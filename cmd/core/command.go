@@ -15,7 +15,9 @@ import (
 	"github.com/facebookincubator/fbender/cmd/core/errors"
 	"github.com/facebookincubator/fbender/cmd/core/options"
 	"github.com/facebookincubator/fbender/cmd/core/runner"
+	"github.com/facebookincubator/fbender/tester"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 // CommandParams is used to generate params for the runner.
@@ -30,31 +32,113 @@ type CommandTemplate struct {
 	// Usage examples can containt {test} string which will be replaced with the
 	// actual test name (throughput|concurrency)
 	Fixed, Constraints string
+	// Flags selecting the target in the generated usage examples, defaults to
+	// "-t $TARGET"
+	Target string
+	// Flags defines the command flags, it's called for every command created
+	// from the template and for every scenario part tested with the protocol
+	Flags func(flags *pflag.FlagSet)
+	// Aliases of the command name
+	Aliases []string
 }
 
-// NewTestCommand creates a protocol command with all the test subcommands.
+// protocol groups a test command template with the function generating its
+// params.
+type protocol struct {
+	template *CommandTemplate
+	params   CommandParams
+}
+
+// commands lists the test commands, so they can be created anew.
+//nolint:gochecknoglobals
+var commands = make(map[string]*protocol)
+
+// NewTestCommand creates a protocol command with all the test subcommands. The
+// protocol can then be used in the scenarios.
+func NewTestCommand(c *CommandTemplate, p CommandParams) *cobra.Command {
+	protocols[c.Name] = &protocol{template: c, params: p}
+
+	return registerTestCommand(c, p)
+}
+
+// registerTestCommand creates a test command which can be created anew with
+// CreateTestCommand.
+func registerTestCommand(c *CommandTemplate, p CommandParams) *cobra.Command {
+	commands[c.Name] = &protocol{template: c, params: p}
+
+	return createTestCommand(c, p)
+}
+
+// CreateTestCommand creates a new test command with the given name or alias,
+// all of its flags have their default values. It returns nil if there's no
+// such command.
+func CreateTestCommand(name string) *cobra.Command {
+	for _, command := range commands {
+		if command.template.Name == name || contains(command.template.Aliases, name) {
+			return createTestCommand(command.template, command.params)
+		}
+	}
+
+	return nil
+}
+
+func createTestCommand(c *CommandTemplate, p CommandParams) *cobra.Command {
+	command := newTestCommand(c, p)
+	command.Aliases = c.Aliases
+
+	if c.Flags != nil {
+		c.Flags(command.PersistentFlags())
+	}
+
+	return command
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// newTestCommand creates a command with all the test subcommands.
 // ${protocol} throughput fixed
 // ${protocol} throughput constraints
+// ${protocol} throughput profile
+// ${protocol} throughput replay
 // ${protocol} concurrency fixed
 // ${protocol} concurrency constraints
 //nolint:funlen
-func NewTestCommand(c *CommandTemplate, p CommandParams) *cobra.Command {
+func newTestCommand(c *CommandTemplate, p CommandParams) *cobra.Command {
 	// Help messages
 	var (
 		tShort  = fmt.Sprintf("%s throughput (QPS)", c.Short)
 		tfShort = fmt.Sprintf("%s with fixed amount of QPS", tShort)
 		tcShort = fmt.Sprintf("%s with constraints", tShort)
+		tpShort = fmt.Sprintf("%s following a load profile", tShort)
+		trShort = fmt.Sprintf("%s replaying a trace", tShort)
 
 		cShort  = fmt.Sprintf("%s concurrent connections", c.Short)
 		cfShort = fmt.Sprintf("%s with fixed number of connections", cShort)
 		ccShort = fmt.Sprintf("%s with constraints", cShort)
 	)
 
+	target := c.Target
+	if len(target) == 0 {
+		target = "-t $TARGET"
+	}
+
 	// Examples
 	var (
 		tfExamples = strings.ReplaceAll(c.Fixed, "{test}", "throughput")
 		tcExamples = strings.ReplaceAll(c.Constraints, "{test}", "throughput")
-		tExamples  = fmt.Sprintf("%s\n%s", tfExamples, tcExamples)
+		tpExamples = fmt.Sprintf(`  fbender %[1]s throughput profile %[2]s "ramp 0->100 over 1m, hold 5m"
+  fbender %[1]s throughput profile %[2]s -P points.txt`, c.Name, target)
+		trExamples = fmt.Sprintf(`  fbender %[1]s throughput replay %[2]s -i trace.txt
+  fbender %[1]s throughput replay %[2]s -i trace.txt --order once --speed 2x -d 1h`, c.Name, target)
+		tExamples = fmt.Sprintf("%s\n%s\n%s\n%s", tfExamples, tcExamples, tpExamples, trExamples)
 
 		cfExamples = strings.ReplaceAll(c.Fixed, "{test}", "concurrency")
 		ccExamples = strings.ReplaceAll(c.Constraints, "{test}", "concurrency")
@@ -108,9 +192,31 @@ func NewTestCommand(c *CommandTemplate, p CommandParams) *cobra.Command {
 		RunE:    RunLoadTestThroughputConstraints(p),
 	}
 
+	tpCommand := &cobra.Command{
+		Use:     "profile",
+		Short:   tpShort,
+		Long:    fmt.Sprintf("%s.\n%s\n%s", tpShort, c.Long, tester.ProfileHelp),
+		Example: tpExamples,
+		Args:    profileArgs,
+		RunE:    RunLoadTestThroughputProfile(p),
+	}
+
+	trCommand := &cobra.Command{
+		Use:     "replay",
+		Short:   trShort,
+		Long:    fmt.Sprintf("%s.\n%s\n%s", trShort, c.Long, ReplayHelp),
+		Example: trExamples,
+		Args:    cobra.NoArgs,
+		RunE:    RunLoadTestThroughputReplay(p),
+	}
+
 	tcCommand.PersistentFlags().AddFlagSet(ConstraintsFlags)
+	tpCommand.PersistentFlags().AddFlagSet(ProfileFlags)
+	trCommand.PersistentFlags().AddFlagSet(ReplayFlags)
 	tCommand.AddCommand(tfCommand)
 	tCommand.AddCommand(tcCommand)
+	tCommand.AddCommand(tpCommand)
+	tCommand.AddCommand(trCommand)
 
 	// Concurrency subcommands
 	cfCommand := &cobra.Command{
@@ -159,3 +265,29 @@ func constraintsArgs(cmd *cobra.Command, args []string) error {
 
 	return err
 }
+
+// profileArgs validates arguments for a profile test.
+func profileArgs(cmd *cobra.Command, args []string) error {
+	points, err := cmd.Flags().GetString("points")
+	if err != nil {
+		//nolint:wrapcheck
+		return err
+	}
+
+	if len(points) > 0 {
+		if len(args) > 0 {
+			return fmt.Errorf("%w: profile stages and points file are mutually exclusive", errors.ErrInvalidArgument)
+		}
+
+		return nil
+	}
+
+	if len(args) < 1 {
+		return fmt.Errorf("%w: requires profile stages or a points file", errors.ErrInvalidArgument)
+	}
+
+	_, err = tester.ParseProfile(strings.Join(args, " "))
+
+	//nolint:wrapcheck
+	return err
+}
@@ -9,8 +9,10 @@ LICENSE file in the root directory of this source tree.
 package core
 
 import (
+	"context"
 	"os"
 
+	"github.com/facebookincubator/fbender/cmd/core/agent"
 	"github.com/facebookincubator/fbender/cmd/core/options"
 	"github.com/facebookincubator/fbender/cmd/core/runner"
 	"github.com/facebookincubator/fbender/log"
@@ -22,7 +24,7 @@ import (
 type cobraRunE = func(cmd *cobra.Command, args []string) error
 
 // executor invokes actual test function with proper params.
-type executor func(p *runner.Params, o *options.Options) error
+type executor func(ctx context.Context, p *runner.Params, o *options.Options) error
 
 func exec(p CommandParams, e executor, gs ...OptionsGenerator) cobraRunE {
 	return func(cmd *cobra.Command, args []string) error {
@@ -31,24 +33,92 @@ func exec(p CommandParams, e executor, gs ...OptionsGenerator) cobraRunE {
 			return err
 		}
 
-		params, err := p(cmd, o)
+		worker, err := agent.CurrentWorker()
 		if err != nil {
 			return err
 		}
 
+		if worker == nil && len(o.Agents) > 0 {
+			p = func(cmd *cobra.Command, o *options.Options) (*runner.Params, error) {
+				return remoteParams(cmd, os.Args[1:], o)
+			}
+		}
+
+		// The standard input can be read only once, it's left for the tests
+		if o.DryRun && len(o.Input) == 0 {
+			return nil
+		}
+
+		params, err := multiTargetParams(p)(cmd, o)
+		if err != nil {
+			if worker != nil {
+				worker.Done(err)
+			}
+
+			return err
+		}
+
+		if o.DryRun {
+			return nil
+		}
+
+		ctx, cancel := interruptContext()
+		defer cancel()
+
+		if worker != nil {
+			e = func(ctx context.Context, p *runner.Params, o *options.Options) error {
+				err := runWorker(ctx, cancel, worker, p, o)
+				worker.Done(err)
+
+				return err
+			}
+		}
+
 		// We want runtime errors to be logged and not trigger help message
-		if err := e(params, o); err != nil {
+		if err := e(ctx, params, o); err != nil {
 			log.Errorf("Error: %v\n", err)
-			os.Exit(1)
+
+			cmd.SilenceErrors = true
+			cmd.SilenceUsage = true
+
+			return err
 		}
 
 		return nil
 	}
 }
 
+// multiTargetParams creates params for every target and combines them, so the
+// load is spread among all the targets. The targets share the input.
+func multiTargetParams(p CommandParams) CommandParams {
+	return func(cmd *cobra.Command, o *options.Options) (*runner.Params, error) {
+		if len(o.Targets) < 2 || len(o.Agents) > 0 {
+			return p(cmd, o)
+		}
+
+		params := make([]*runner.Params, 0, len(o.Targets))
+		inputs := new(options.InputCache)
+
+		for _, target := range o.Targets {
+			targetOptions := *o
+			targetOptions.Target = target.Address
+			targetOptions.Inputs = inputs
+
+			targetParams, err := p(cmd, &targetOptions)
+			if err != nil {
+				return nil, err
+			}
+
+			params = append(params, targetParams)
+		}
+
+		return runner.NewMultiTargetParams(o.Targets, o.Selection(o.Targets.Weights()), params...), nil
+	}
+}
+
 func setupConstraints(o *options.Options, cmd *cobra.Command, args []string) (*options.Options, error) {
 	for _, constraint := range o.Constraints {
-		if err := constraint.Metric.Setup(o); err != nil {
+		if err := constraint.Setup(o); err != nil {
 			//nolint:wrapcheck
 			return nil, err
 		}
@@ -71,14 +141,26 @@ var constraintsOptionsGenerators = []OptionsGenerator{
 	setupConstraints,
 }
 
+//nolint:gochecknoglobals
+var profileOptionsGenerators = []OptionsGenerator{
+	ExtractOptions,
+	ExtractProfileOptions,
+}
+
+//nolint:gochecknoglobals
+var replayOptionsGenerators = []OptionsGenerator{
+	ExtractOptions,
+	ExtractReplayOptions,
+}
+
 // RunLoadTestThroughputFixed returns a new cobra RunE method for the load
 // tester with fixed QPS tests.
 func RunLoadTestThroughputFixed(p CommandParams) cobraRunE {
 	return exec(p, fixedThroughputExecutor, fixedOptionsGenerators...)
 }
 
-func fixedThroughputExecutor(p *runner.Params, o *options.Options) error {
-	return run.LoadTestThroughputFixed(runner.NewThroughputRunner(p), o, o.Tests...)
+func fixedThroughputExecutor(ctx context.Context, p *runner.Params, o *options.Options) error {
+	return run.LoadTestThroughputFixed(ctx, throughputRunner(p, o), o, o.Tests...)
 }
 
 // RunLoadTestThroughputConstraints returns a new cobra RunE method for the QPS
@@ -87,8 +169,32 @@ func RunLoadTestThroughputConstraints(p CommandParams) cobraRunE {
 	return exec(p, constraintsThroughputExecutor, constraintsOptionsGenerators...)
 }
 
-func constraintsThroughputExecutor(p *runner.Params, o *options.Options) error {
-	return run.LoadTestThroughputConstraints(runner.NewThroughputRunner(p), o, o.Start, o.Growth, o.Constraints...)
+func constraintsThroughputExecutor(ctx context.Context, p *runner.Params, o *options.Options) error {
+	return run.LoadTestThroughputConstraints(ctx, throughputRunner(p, o), o, o.Start, o.Growth, o.Constraints...)
+}
+
+// RunLoadTestThroughputProfile returns a new cobra RunE method for the QPS load
+// tester following a load profile.
+func RunLoadTestThroughputProfile(p CommandParams) cobraRunE {
+	return exec(p, profileThroughputExecutor, profileOptionsGenerators...)
+}
+
+func profileThroughputExecutor(ctx context.Context, p *runner.Params, o *options.Options) error {
+	return run.LoadTestThroughputFixed(ctx, profileRunner(p, o), o, int(o.Profile.Peak()))
+}
+
+// RunLoadTestThroughputReplay returns a new cobra RunE method for the QPS load
+// tester replaying a trace.
+func RunLoadTestThroughputReplay(p CommandParams) cobraRunE {
+	return exec(p, replayThroughputExecutor, replayOptionsGenerators...)
+}
+
+func replayThroughputExecutor(ctx context.Context, p *runner.Params, o *options.Options) error {
+	if len(p.Parts) > 0 {
+		return run.LoadTestThroughputFixed(ctx, runner.NewScenarioRunner(p, runner.Replay), o, 1)
+	}
+
+	return run.LoadTestThroughputFixed(ctx, runner.NewReplayRunner(p), o, 1)
 }
 
 // RunLoadTestConcurrencyFixed returns a new cobra RunE method for the load
@@ -97,8 +203,8 @@ func RunLoadTestConcurrencyFixed(p CommandParams) cobraRunE {
 	return exec(p, fixedConcurrencyExecutor, fixedOptionsGenerators...)
 }
 
-func fixedConcurrencyExecutor(p *runner.Params, o *options.Options) error {
-	return run.LoadTestConcurrencyFixed(runner.NewConcurrencyRunner(p), o, o.Tests...)
+func fixedConcurrencyExecutor(ctx context.Context, p *runner.Params, o *options.Options) error {
+	return run.LoadTestConcurrencyFixed(ctx, concurrencyRunner(p, o), o, o.Tests...)
 }
 
 // RunLoadTestConcurrencyConstraints returns a new cobra RunE method for the
@@ -107,6 +213,6 @@ func RunLoadTestConcurrencyConstraints(p CommandParams) cobraRunE {
 	return exec(p, constraintsConcurrencyExecutor, constraintsOptionsGenerators...)
 }
 
-func constraintsConcurrencyExecutor(p *runner.Params, o *options.Options) error {
-	return run.LoadTestConcurrencyConstraints(runner.NewConcurrencyRunner(p), o, o.Start, o.Growth, o.Constraints...)
+func constraintsConcurrencyExecutor(ctx context.Context, p *runner.Params, o *options.Options) error {
+	return run.LoadTestConcurrencyConstraints(ctx, concurrencyRunner(p, o), o, o.Start, o.Growth, o.Constraints...)
 }
@@ -9,10 +9,18 @@ LICENSE file in the root directory of this source tree.
 package core
 
 import (
+	"fmt"
+	"os"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/facebookincubator/fbender/cmd/core/agent"
+	"github.com/facebookincubator/fbender/cmd/core/errors"
 	"github.com/facebookincubator/fbender/cmd/core/options"
 	"github.com/facebookincubator/fbender/flags"
+	"github.com/facebookincubator/fbender/log"
+	"github.com/facebookincubator/fbender/tester"
 	"github.com/spf13/cobra"
 )
 
@@ -62,13 +70,43 @@ func ExtractOptions(o *options.Options, cmd *cobra.Command, _ []string) (*option
 		o = options.NewOptions()
 	}
 
-	o.Target, err = cmd.Flags().GetString("target")
+	if err = extractTargets(o, cmd); err != nil {
+		return nil, err
+	}
+
+	o.Duration, err = cmd.Flags().GetDuration("duration")
 	if err != nil {
 		//nolint:wrapcheck
 		return nil, err
 	}
 
-	o.Duration, err = cmd.Flags().GetDuration("duration")
+	o.Requests, err = cmd.Flags().GetInt("requests")
+	if err != nil {
+		//nolint:wrapcheck
+		return nil, err
+	}
+
+	if o.Requests < 0 {
+		return nil, fmt.Errorf("%w: requests must be non-negative, got: %d", errors.ErrInvalidArgument, o.Requests)
+	}
+
+	o.Warmup, err = cmd.Flags().GetDuration("warmup")
+	if err != nil {
+		//nolint:wrapcheck
+		return nil, err
+	}
+
+	o.Repeat, err = cmd.Flags().GetInt("repeat")
+	if err != nil {
+		//nolint:wrapcheck
+		return nil, err
+	}
+
+	if o.Repeat < 1 {
+		return nil, fmt.Errorf("%w: repeat must be positive, got: %d", errors.ErrInvalidArgument, o.Repeat)
+	}
+
+	o.Cooldown, err = cmd.Flags().GetDuration("cooldown")
 	if err != nil {
 		//nolint:wrapcheck
 		return nil, err
@@ -80,6 +118,35 @@ func ExtractOptions(o *options.Options, cmd *cobra.Command, _ []string) (*option
 		return nil, err
 	}
 
+	o.Order, err = flags.GetOrder(cmd.Flags(), "order")
+	if err != nil {
+		//nolint:wrapcheck
+		return nil, err
+	}
+
+	o.Seed, err = cmd.Flags().GetInt64("seed")
+	if err != nil {
+		//nolint:wrapcheck
+		return nil, err
+	}
+
+	if o.Seed == 0 {
+		o.Seed = time.Now().UnixNano()
+	}
+
+	o.Stream, err = cmd.Flags().GetBool("stream")
+	if err != nil {
+		//nolint:wrapcheck
+		return nil, err
+	}
+
+	// Streamed input is read sequentially, so the random orders are unavailable
+	if o.Stream {
+		if err := sequentialOrder(cmd, "streamed input"); err != nil {
+			return nil, err
+		}
+	}
+
 	o.BufferSize, err = cmd.Flags().GetInt("buffer")
 	if err != nil {
 		//nolint:wrapcheck
@@ -92,6 +159,28 @@ func ExtractOptions(o *options.Options, cmd *cobra.Command, _ []string) (*option
 		return nil, err
 	}
 
+	o.MaxInflight, err = cmd.Flags().GetInt("max-inflight")
+	if err != nil {
+		//nolint:wrapcheck
+		return nil, err
+	}
+
+	if o.MaxInflight < 0 {
+		return nil, fmt.Errorf("%w: max-inflight must be non-negative, got: %d", errors.ErrInvalidArgument, o.MaxInflight)
+	}
+
+	o.DropOverflow, err = cmd.Flags().GetBool("drop")
+	if err != nil {
+		//nolint:wrapcheck
+		return nil, err
+	}
+
+	o.FailSaturated, err = cmd.Flags().GetBool("fail-saturated")
+	if err != nil {
+		//nolint:wrapcheck
+		return nil, err
+	}
+
 	o.Distribution, err = flags.GetDistribution(cmd.Flags(), "dist")
 	if err != nil {
 		//nolint:wrapcheck
@@ -110,9 +199,128 @@ func ExtractOptions(o *options.Options, cmd *cobra.Command, _ []string) (*option
 		return nil, err
 	}
 
+	o.DryRun, err = cmd.Flags().GetBool("dry-run")
+	if err != nil {
+		//nolint:wrapcheck
+		return nil, err
+	}
+
+	o.ReportInterval, err = cmd.Flags().GetDuration("report-interval")
+	if err != nil {
+		//nolint:wrapcheck
+		return nil, err
+	}
+
+	processes, err := cmd.Flags().GetStringArray("proc")
+	if err != nil {
+		//nolint:wrapcheck
+		return nil, err
+	}
+
+	for _, process := range processes {
+		o.WatchProcess(process)
+	}
+
+	o.Agents, err = cmd.Flags().GetStringSlice("agents")
+	if err != nil {
+		//nolint:wrapcheck
+		return nil, err
+	}
+
+	filename, err := cmd.Flags().GetString("agents-secret-file")
+	if err != nil {
+		//nolint:wrapcheck
+		return nil, err
+	}
+
+	o.AgentsSecret, err = agent.ReadSecret(filename)
+	if err != nil {
+		//nolint:wrapcheck
+		return nil, err
+	}
+
+	// Every agent sends a part of the requests
+	if o.Requests > 0 && o.Requests < len(o.Agents) {
+		return nil, fmt.Errorf("%w: requests must be at least the number of agents, got: %d < %d",
+			errors.ErrInvalidArgument, o.Requests, len(o.Agents))
+	}
+
 	return o, nil
 }
 
+// extractTargets extracts targets given either as flags or in a targets file.
+// The first target is set as the options target. Commands without the target
+// flags (scenarios) define the targets on their own.
+func extractTargets(o *options.Options, cmd *cobra.Command) error {
+	if cmd.Flags().Lookup("target") == nil {
+		return nil
+	}
+
+	values, err := cmd.Flags().GetStringArray("target")
+	if err != nil {
+		//nolint:wrapcheck
+		return err
+	}
+
+	filename, err := cmd.Flags().GetString("targets-file")
+	if err != nil {
+		//nolint:wrapcheck
+		return err
+	}
+
+	o.Targets = tester.Targets{}
+
+	for _, value := range values {
+		target, err := tester.ParseTarget(value)
+		if err != nil {
+			//nolint:wrapcheck
+			return err
+		}
+
+		o.Targets = append(o.Targets, target)
+	}
+
+	if len(filename) > 0 {
+		targets, err := readTargets(filename)
+		if err != nil {
+			return err
+		}
+
+		o.Targets = append(o.Targets, targets...)
+	}
+
+	if len(o.Targets) == 0 {
+		return fmt.Errorf("%w: at least one target is required", errors.ErrInvalidArgument)
+	}
+
+	o.Target = o.Targets[0].Address
+
+	o.Selection, err = flags.GetSelection(cmd.Flags(), "select")
+	if err != nil {
+		//nolint:wrapcheck
+		return err
+	}
+
+	return nil
+}
+
+func readTargets(filename string) (tester.Targets, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		//nolint:wrapcheck
+		return nil, err
+	}
+
+	defer func() {
+		if err := file.Close(); err != nil {
+			log.Errorf("Warning: Error closing targets file: %v\n", err)
+		}
+	}()
+
+	//nolint:wrapcheck
+	return tester.ParseTargets(file)
+}
+
 // ExtractConstraintsOptions extracts flag commonly used options across constraints test commands.
 func ExtractConstraintsOptions(o *options.Options, cmd *cobra.Command, _ []string) (*options.Options, error) {
 	var err error
@@ -133,9 +341,168 @@ func ExtractConstraintsOptions(o *options.Options, cmd *cobra.Command, _ []strin
 		return nil, err
 	}
 
+	o.AbortOn, err = cmd.Flags().GetDuration("abort-on")
+	if err != nil {
+		//nolint:wrapcheck
+		return nil, err
+	}
+
+	o.AbortWindow, err = cmd.Flags().GetDuration("abort-window")
+	if err != nil {
+		//nolint:wrapcheck
+		return nil, err
+	}
+
+	if o.AbortOn > 0 && o.AbortWindow <= 0 {
+		return nil, fmt.Errorf("%w: abort-window must be positive, got: %s", errors.ErrInvalidArgument, o.AbortWindow)
+	}
+
+	o.PrometheusURL, err = cmd.Flags().GetString("prometheus")
+	if err != nil {
+		//nolint:wrapcheck
+		return nil, err
+	}
+
+	o.PrometheusStep, err = cmd.Flags().GetDuration("prometheus-step")
+	if err != nil {
+		//nolint:wrapcheck
+		return nil, err
+	}
+
+	o.ExecMetrics, err = extractExecMetrics(cmd)
+	if err != nil {
+		return nil, err
+	}
+
 	return o, nil
 }
 
+// extractExecMetrics parses the NAME=COMMAND definitions of the exec metrics.
+func extractExecMetrics(cmd *cobra.Command) (map[string]string, error) {
+	definitions, err := cmd.Flags().GetStringArray("exec-metric")
+	if err != nil {
+		//nolint:wrapcheck
+		return nil, err
+	}
+
+	metrics := make(map[string]string, len(definitions))
+
+	for _, definition := range definitions {
+		i := strings.Index(definition, "=")
+		if i <= 0 || len(strings.TrimSpace(definition[i+1:])) == 0 {
+			return nil, fmt.Errorf("%w: exec metric must be NAME=COMMAND, got: %q", errors.ErrInvalidArgument, definition)
+		}
+
+		metrics[definition[:i]] = definition[i+1:]
+	}
+
+	return metrics, nil
+}
+
+// ExtractProfileOptions extracts the load profile either from the arguments or
+// from a points file.
+func ExtractProfileOptions(o *options.Options, cmd *cobra.Command, args []string) (*options.Options, error) {
+	var err error
+
+	if o == nil {
+		o = options.NewOptions()
+	}
+
+	if o.Requests > 0 {
+		return nil, fmt.Errorf("%w: profile tests are bound by the profile", errors.ErrInvalidArgument)
+	}
+
+	points, err := cmd.Flags().GetString("points")
+	if err != nil {
+		//nolint:wrapcheck
+		return nil, err
+	}
+
+	if len(points) == 0 {
+		o.Profile, err = tester.ParseProfile(strings.Join(args, " "))
+		if err != nil {
+			//nolint:wrapcheck
+			return nil, err
+		}
+
+		return o, nil
+	}
+
+	if len(args) > 0 {
+		return nil, fmt.Errorf("%w: profile stages and points file are mutually exclusive", errors.ErrInvalidArgument)
+	}
+
+	file, err := os.Open(points)
+	if err != nil {
+		//nolint:wrapcheck
+		return nil, err
+	}
+
+	defer func() {
+		if err := file.Close(); err != nil {
+			log.Errorf("Warning: Error closing points file: %v\n", err)
+		}
+	}()
+
+	o.Profile, err = tester.ParseProfilePoints(file)
+	if err != nil {
+		//nolint:wrapcheck
+		return nil, err
+	}
+
+	return o, nil
+}
+
+// ExtractReplayOptions extracts the replay speed and validates that the other
+// options can be used in a replay test.
+func ExtractReplayOptions(o *options.Options, cmd *cobra.Command, args []string) (*options.Options, error) {
+	if o == nil {
+		o = options.NewOptions()
+	}
+
+	o.Replay = true
+
+	speed, err := cmd.Flags().GetString("speed")
+	if err != nil {
+		//nolint:wrapcheck
+		return nil, err
+	}
+
+	o.Speed, err = strconv.ParseFloat(strings.TrimSuffix(speed, "x"), 64)
+	if err != nil || o.Speed <= 0 {
+		return nil, fmt.Errorf("%w: speed must be a positive factor (e.g. 2x), got: %q", errors.ErrInvalidArgument, speed)
+	}
+
+	if len(o.Agents) > 0 {
+		return nil, fmt.Errorf("%w: replay tests cannot be distributed among agents", errors.ErrInvalidArgument)
+	}
+
+	if o.Warmup > 0 {
+		return nil, fmt.Errorf("%w: warm-up is not supported in replay tests", errors.ErrInvalidArgument)
+	}
+
+	if o.Requests > 0 {
+		return nil, fmt.Errorf("%w: replay tests are bound by the duration or the input", errors.ErrInvalidArgument)
+	}
+
+	// The trace is replayed sequentially, so the random orders are unavailable
+	if err := sequentialOrder(cmd, "replay tests"); err != nil {
+		return nil, err
+	}
+
+	return o, nil
+}
+
+// sequentialOrder validates that the input is used sequentially.
+func sequentialOrder(cmd *cobra.Command, what string) error {
+	order := cmd.Flags().Lookup("order").Value.String()
+	if order != "roundrobin" && order != "once" {
+		return fmt.Errorf("%w: order %q is not supported in %s", errors.ErrInvalidArgument, order, what)
+	}
+
+	return nil
+}
+
 // GenerateOptions runs given generators for a command and returns options.
 func GenerateOptions(cmd *cobra.Command, args []string, gs ...OptionsGenerator) (*options.Options, error) {
 	var o *options.Options
@@ -10,10 +10,12 @@ package core
 
 import (
 	"strings"
+	"time"
 
 	"github.com/facebookincubator/fbender/flags"
 	"github.com/facebookincubator/fbender/metric"
 	"github.com/facebookincubator/fbender/tester"
+	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 )
 
@@ -25,6 +27,17 @@ var (
 	ConstraintsValue = flags.NewConstraintSliceValue(metric.Parser)
 	// ConstraintsHelp is a help message on how to use constraints.
 	ConstraintsHelp = strings.Join([]string{tester.ConstraintsHelp, metric.Help}, "\n")
+	// ProfileFlags contains flags for specifying profile tests options.
+	ProfileFlags = pflag.NewFlagSet("Profile test flags", pflag.ExitOnError)
+	// ReplayHelp is a help message on the replay tests input format.
+	ReplayHelp = `
+Every input line starts with a timestamp of the request followed by the input
+in the protocol format. The timestamp is either in seconds (e.g. Unix time with
+a fraction) or in the RFC 3339 format. The requests are sent with the original
+intervals between them divided by the speed, the test stops after the test
+duration of the replayed trace or when the input is exhausted (--order once).`
+	// ReplayFlags contains flags for specifying replay tests options.
+	ReplayFlags = pflag.NewFlagSet("Replay test flags", pflag.ExitOnError)
 )
 
 //nolint:gochecknoinits
@@ -33,4 +46,17 @@ func init() {
 
 	ConstraintsFlags.VarP(ConstraintsValue, "constraints", "c", "constraints to be checked after each test")
 	ConstraintsFlags.VarP(growth, "growth", "g", "growth used to determinate the next test (+AMOUNT|%PERCENT|^PRECISION)")
+	ConstraintsFlags.Duration("abort-on", 0, "abort a test once the constraints are violated for this long (0 disables)")
+	ConstraintsFlags.Duration("abort-window", 5*time.Second, "rolling window of the constraints checks during a test")
+	ConstraintsFlags.String("prometheus", "http://localhost:9090", "URL of the Prometheus server queried by prom metrics")
+	ConstraintsFlags.Duration("prometheus-step", 5*time.Second, "resolution of the prom metrics queries")
+	ConstraintsFlags.StringArray("exec-metric", []string{}, "command of an exec metric (NAME=COMMAND)")
+
+	ProfileFlags.StringP("points", "P", "", "load the profile from a file of \"Time QPS\" points")
+
+	if err := ProfileFlags.SetAnnotation("points", cobra.BashCompFilenameExt, []string{}); err != nil {
+		panic(err)
+	}
+
+	ReplayFlags.String("speed", "1x", "replay speed factor, e.g. 2x sends the requests twice as fast")
 }
@@ -10,13 +10,18 @@ package input
 
 import (
 	"bufio"
+	"bytes"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
+	"sync"
 
 	"github.com/facebookincubator/fbender/cmd/core/errors"
+	"github.com/facebookincubator/fbender/cmd/core/options"
 	"github.com/facebookincubator/fbender/cmd/core/runner"
 	"github.com/facebookincubator/fbender/log"
+	"github.com/facebookincubator/fbender/tester"
 )
 
 // Transformer converts input line into a request.
@@ -25,39 +30,118 @@ type Transformer func(string) (interface{}, error)
 // Modifier changes request right before sending.
 type Modifier func(interface{}) (interface{}, error)
 
-// NewRequestGenerator reads data from the specified input and converts it into
-// requests using given transformer. The lines which aren't formatted correctly
-// are skipped. The requests are then reused in a round-robin manner inside the
-// generator. If modifiers are provided they are applied to the request every
-// time just before being returned.
-func NewRequestGenerator(filename string, transformer Transformer, mods ...Modifier) (runner.RequestGenerator, error) {
-	file, err := open(filename)
+// NewRequestGenerator creates a request generator reading data from the input
+// set in options and converting it into requests using given transformer. The
+// lines which aren't formatted correctly are skipped. The requests are used in
+// the order set in options, the generator returns nil once the input is
+// exhausted. If modifiers are provided they are applied to the request every
+// time just before being returned. In replay tests every line starts with
+// a timestamp and the requests are wrapped in tester.TimedRequest. The input
+// is read only once for all the generators sharing the options input cache.
+func NewRequestGenerator(o *options.Options, transformer Transformer,
+	mods ...Modifier) (runner.RequestGenerator, error) {
+	if o.Replay {
+		transformer = timedTransformer(transformer)
+	}
+
+	create := func() (func(i int) interface{}, error) {
+		if o.Stream {
+			return newStreamingRequestGenerator(o, transformer)
+		}
+
+		return newParsedRequestGenerator(o, transformer)
+	}
+
+	var (
+		generator func(i int) interface{}
+		err       error
+	)
+
+	if o.Inputs != nil {
+		generator, err = o.Inputs.Get(create)
+	} else {
+		generator, err = create()
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return func(i int) interface{} {
+		request := generator(i)
+		if request == nil {
+			return nil
+		}
+
+		return modify(request, mods...)
+	}, nil
+}
+
+// newParsedRequestGenerator creates a request generator parsing the whole
+// input up front and keeping it in memory.
+func newParsedRequestGenerator(o *options.Options, transformer Transformer) (runner.RequestGenerator, error) {
+	file, err := open(o.Input)
 	if err != nil {
 		return nil, err
 	}
 
-	defer close(file)
+	defer closeFile(file)
 
 	data := parse(file, transformer)
 	if len(data) < 1 {
 		return nil, fmt.Errorf("%w: at least one valid input line is required", errors.ErrInvalidFormat)
 	}
 
+	index := o.Indexer(len(data))
+
 	return func(i int) interface{} {
-		var err error
-
-		request := data[i%len(data)]
-		for _, mod := range mods {
-			request, err = mod(request)
-			if err != nil {
-				panic(err)
-			}
+		j, ok := index(i)
+		if !ok {
+			return nil
 		}
 
-		return request
+		return data[j]
 	}, nil
 }
 
+// timedTransformer converts input lines starting with a timestamp into timed
+// requests, the rest of the line is converted using given transformer.
+func timedTransformer(transformer Transformer) Transformer {
+	return func(line string) (interface{}, error) {
+		t, rest, err := tester.ParseTimedLine(line)
+		if err != nil {
+			//nolint:wrapcheck
+			return nil, err
+		}
+
+		request, err := transformer(rest)
+		if err != nil {
+			return nil, err
+		}
+
+		return &tester.TimedRequest{Time: t, Request: request}, nil
+	}
+}
+
+// modify applies the modifiers to the request (or the request wrapped in
+// a timed request).
+func modify(request interface{}, mods ...Modifier) interface{} {
+	if timed, ok := request.(*tester.TimedRequest); ok && len(mods) > 0 {
+		return &tester.TimedRequest{Time: timed.Time, Request: modify(timed.Request, mods...)}
+	}
+
+	var err error
+
+	for _, mod := range mods {
+		request, err = mod(request)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	return request
+}
+
 // parse reads data from the specified input and converts it into requests
 // using given transformer. The lines which are not formatted correctly are
 // skipped and a warning message is printed to stderr.
@@ -79,21 +163,31 @@ func parse(input io.Reader, transformer Transformer) []interface{} {
 	return requests
 }
 
-func open(filename string) (*os.File, error) {
+// Standard input can be read only once, it's kept in memory so multiple
+// generators (e.g. one per target) can be created from it.
+//nolint:gochecknoglobals
+var (
+	stdin     []byte
+	stdinErr  error
+	stdinOnce sync.Once
+)
+
+func open(filename string) (io.ReadCloser, error) {
 	if len(filename) == 0 {
-		log.Errorf("Reading input lines until EOF:\n")
+		stdinOnce.Do(func() {
+			log.Errorf("Reading input lines until EOF:\n")
+
+			stdin, stdinErr = ioutil.ReadAll(os.Stdin)
+		})
 
-		return os.Stdin, nil
+		return ioutil.NopCloser(bytes.NewReader(stdin)), stdinErr
 	}
 
+	//nolint:wrapcheck
 	return os.Open(filename)
 }
 
-func close(file io.Closer) {
-	if file == os.Stdin {
-		return
-	}
-
+func closeFile(file io.Closer) {
 	if err := file.Close(); err != nil {
 		log.Errorf("Warning: Error closing input file: %v\n", err)
 	}
@@ -0,0 +1,163 @@
+/*
+Copyright (c) Facebook, Inc. and its affiliates.
+All rights reserved.
+
+This source code is licensed under the BSD-style license found in the
+LICENSE file in the root directory of this source tree.
+*/
+
+package input
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/facebookincubator/fbender/cmd/core/errors"
+	"github.com/facebookincubator/fbender/cmd/core/options"
+	"github.com/facebookincubator/fbender/cmd/core/runner"
+	"github.com/facebookincubator/fbender/log"
+	"github.com/facebookincubator/fbender/tester"
+)
+
+// stream reads and transforms the input lines lazily in background, keeping
+// at most readAhead requests in memory.
+type stream struct {
+	filename    string
+	transformer Transformer
+	indexer     func(n int) tester.Indexer
+	readAhead   int
+
+	mutex    sync.Mutex
+	requests chan interface{}
+	stop     chan struct{}
+	last     int
+}
+
+// newStreamingRequestGenerator creates a request generator which reads the
+// input file lazily, so the memory use doesn't depend on the input size. When
+// the end of the file is reached it's reopened unless the order set in options
+// says the input is exhausted. The stream starts over from the beginning of the
+// file every time the requests numbering starts over (e.g. in a new test), so
+// a single stream can be shared by all the targets.
+func newStreamingRequestGenerator(o *options.Options, transformer Transformer) (runner.RequestGenerator, error) {
+	if len(o.Input) == 0 {
+		return nil, fmt.Errorf("%w: streaming requires an input file", errors.ErrInvalidArgument)
+	}
+
+	file, err := os.Open(o.Input)
+	if err != nil {
+		//nolint:wrapcheck
+		return nil, err
+	}
+
+	closeFile(file)
+
+	s := &stream{
+		filename:    o.Input,
+		transformer: transformer,
+		indexer:     o.Indexer,
+		readAhead:   o.BufferSize,
+	}
+
+	return func(i int) interface{} {
+		request, ok := s.next(i)
+		if !ok {
+			return nil
+		}
+
+		return request
+	}, nil
+}
+
+// next returns the request for the i-th request, it restarts the stream if the
+// requests numbering has started over.
+func (s *stream) next(i int) (interface{}, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.requests == nil || i <= s.last {
+		s.restart()
+	}
+
+	s.last = i
+	request, ok := <-s.requests
+
+	return request, ok
+}
+
+// restart stops reading the current stream and starts a new one.
+func (s *stream) restart() {
+	if s.stop != nil {
+		close(s.stop)
+	}
+
+	s.requests = make(chan interface{}, s.readAhead)
+	s.stop = make(chan struct{})
+
+	go s.read(s.requests, s.stop)
+}
+
+// read sends the requests to the channel until stopped or the input is
+// exhausted. The malformed lines are reported only in the first pass.
+func (s *stream) read(requests chan<- interface{}, stop <-chan struct{}) {
+	defer close(requests)
+
+	for pass := 0; ; pass++ {
+		lines, ok := s.pass(requests, stop, pass == 0)
+		if !ok || lines == 0 {
+			return
+		}
+
+		// The number of lines is known after the first pass, the order
+		// decides if the lines are used again.
+		if _, ok := s.indexer(lines)(lines); !ok {
+			return
+		}
+	}
+}
+
+// pass reads the input file once and returns the number of valid lines. It
+// returns false if the stream was stopped or the file couldn't be read.
+func (s *stream) pass(requests chan<- interface{}, stop <-chan struct{}, warn bool) (int, bool) {
+	file, err := os.Open(s.filename)
+	if err != nil {
+		log.Errorf("Error: Error opening input file: %v\n", err)
+
+		return 0, false
+	}
+
+	defer closeFile(file)
+
+	lines := 0
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		request, err := s.transformer(line)
+		if err != nil {
+			if warn {
+				log.Errorf("Warning: Error parsing input line %q: %v\n", line, err)
+			}
+
+			continue
+		}
+
+		select {
+		case <-stop:
+			return lines, false
+		case requests <- request:
+			lines++
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		log.Errorf("Error: Error reading input file: %v\n", err)
+
+		return lines, false
+	}
+
+	return lines, true
+}
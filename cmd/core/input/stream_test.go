@@ -0,0 +1,110 @@
+/*
+Copyright (c) Facebook, Inc. and its affiliates.
+All rights reserved.
+
+This source code is licensed under the BSD-style license found in the
+LICENSE file in the root directory of this source tree.
+*/
+
+package input_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/facebookincubator/fbender/cmd/core/input"
+	"github.com/facebookincubator/fbender/cmd/core/options"
+	"github.com/facebookincubator/fbender/tester"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errBadLine = errors.New("bad line")
+
+func transformer(line string) (interface{}, error) {
+	if line == "bad" {
+		return nil, errBadLine
+	}
+
+	return line, nil
+}
+
+func streamOptions(t *testing.T, order tester.Order) *options.Options {
+	t.Helper()
+
+	file, err := ioutil.TempFile("", "input")
+	require.NoError(t, err)
+
+	t.Cleanup(func() { os.Remove(file.Name()) })
+
+	_, err = file.WriteString("a\nbad\nb\nc\n")
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	o := options.NewOptions()
+	o.Input = file.Name()
+	o.Order = order
+	o.Stream = true
+	o.BufferSize = 1
+
+	return o
+}
+
+func generate(generator func(int) interface{}, from, to int) []interface{} {
+	requests := []interface{}{}
+	for i := from; i < to; i++ {
+		requests = append(requests, generator(i))
+	}
+
+	return requests
+}
+
+func TestStreamingRequestGenerator(t *testing.T) {
+	o := streamOptions(t, tester.RoundRobinOrder)
+	generator, err := input.NewRequestGenerator(o, transformer)
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{"a", "b", "c", "a", "b", "c", "a"}, generate(generator, 0, 7))
+	// The stream starts over when the requests numbering does.
+	assert.Equal(t, []interface{}{"a", "b"}, generate(generator, 0, 2))
+}
+
+func TestStreamingRequestGenerator_Once(t *testing.T) {
+	o := streamOptions(t, tester.OnceOrder)
+	modifier := func(request interface{}) (interface{}, error) {
+		return request.(string) + "!", nil
+	}
+
+	generator, err := input.NewRequestGenerator(o, transformer, modifier)
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{"a!", "b!", "c!", nil, nil}, generate(generator, 0, 5))
+	assert.Equal(t, []interface{}{"a!", "b!", "c!", nil}, generate(generator, 0, 4))
+}
+
+func TestStreamingRequestGenerator_Shared(t *testing.T) {
+	o := streamOptions(t, tester.OnceOrder)
+	o.Inputs = new(options.InputCache)
+	modifier := func(request interface{}) (interface{}, error) {
+		return request.(string) + "!", nil
+	}
+
+	first, err := input.NewRequestGenerator(o, transformer)
+	require.NoError(t, err)
+	second, err := input.NewRequestGenerator(o, transformer, modifier)
+	require.NoError(t, err)
+	// Every line is sent once no matter which of the targets gets it.
+	assert.Equal(t, []interface{}{"a", "b!", "c", nil},
+		[]interface{}{first(0), second(1), first(2), second(3)})
+}
+
+func TestStreamingRequestGenerator_Errors(t *testing.T) {
+	o := options.NewOptions()
+	o.Stream = true
+	_, err := input.NewRequestGenerator(o, transformer)
+	assert.EqualError(t, err, "invalid argument: streaming requires an input file")
+
+	o.Input = "/nonexistent/input"
+	_, err = input.NewRequestGenerator(o, transformer)
+	assert.True(t, os.IsNotExist(err))
+}
@@ -9,31 +9,89 @@ LICENSE file in the root directory of this source tree.
 package options
 
 import (
+	"sync"
 	"time"
 
+	"github.com/facebookincubator/fbender/recorders"
 	"github.com/facebookincubator/fbender/tester"
 	"github.com/pinterest/bender"
 )
 
+// ProcessSampleInterval is the interval of the process resource usage samples.
+const ProcessSampleInterval = time.Second
+
 // Options represents common options for the Commands.
 type Options struct {
-	Target   string
+	Target    string
+	Targets   tester.Targets
+	Selection tester.Selection
+
 	Duration time.Duration
+	Requests int
+	Warmup   time.Duration
+	Repeat   int
+	Cooldown time.Duration
 	Tests    []int
 	Start    int
 
-	Input string
+	Input  string
+	Order  tester.Order
+	Seed   int64
+	Stream bool
+	// Inputs is shared by the options of every target, so the input is read
+	// only once for all of them
+	Inputs *InputCache
 
 	BufferSize   int
 	Timeout      time.Duration
+	MaxInflight  int
+	DropOverflow bool
 	Distribution func(float64) bender.IntervalGenerator
 	Unit         time.Duration
 	NoStatistics bool
+	// DryRun checks the options and creates the params without running the
+	// tests
+	DryRun bool
+
+	ReportInterval time.Duration
+	FailSaturated  bool
 
 	Constraints []*tester.Constraint
 	Growth      tester.Growth
+	AbortOn     time.Duration
+	AbortWindow time.Duration
+
+	PrometheusURL  string
+	PrometheusStep time.Duration
+	ExecMetrics    map[string]string
+
+	Profile tester.Profile
+
+	Replay bool
+	Speed  float64
 
 	Recorders []bender.Recorder
+	Processes map[string]*recorders.ProcessUsage
+
+	Agents       []string
+	AgentsSecret []byte
+}
+
+// InputCache shares the input among the request generators created for
+// multiple targets.
+type InputCache struct {
+	once      sync.Once
+	generator func(i int) interface{}
+	err       error
+}
+
+// Get returns the cached request generator, it's created on the first call.
+func (c *InputCache) Get(create func() (func(i int) interface{}, error)) (func(i int) interface{}, error) {
+	c.once.Do(func() {
+		c.generator, c.err = create()
+	})
+
+	return c.generator, c.err
 }
 
 // NewOptions returns new options.
@@ -42,6 +100,7 @@ func NewOptions() *Options {
 		Tests:       []int{},
 		Constraints: []*tester.Constraint{},
 		Recorders:   []bender.Recorder{},
+		Processes:   map[string]*recorders.ProcessUsage{},
 	}
 }
 
@@ -50,7 +109,89 @@ func (o *Options) GetUnit() time.Duration {
 	return o.Unit
 }
 
+// GetTimeout returns a requests timeout.
+func (o *Options) GetTimeout() time.Duration {
+	return o.Timeout
+}
+
+// GetRepeat returns the number of trials of every test.
+func (o *Options) GetRepeat() int {
+	return o.Repeat
+}
+
+// GetCooldown returns a pause between the trials of a test.
+func (o *Options) GetCooldown() time.Duration {
+	return o.Cooldown
+}
+
+// GetMaxInflight returns a limit of in-flight requests.
+func (o *Options) GetMaxInflight() int {
+	return o.MaxInflight
+}
+
+// GetDropOverflow returns whether requests over the in-flight limit are dropped.
+func (o *Options) GetDropOverflow() bool {
+	return o.DropOverflow
+}
+
+// GetAbortOn returns for how long the constraints may be violated before
+// a test is aborted.
+func (o *Options) GetAbortOn() time.Duration {
+	return o.AbortOn
+}
+
+// GetAbortWindow returns a window of the constraints checks during a test.
+func (o *Options) GetAbortWindow() time.Duration {
+	return o.AbortWindow
+}
+
+// GetPrometheusURL returns the URL of the Prometheus server queried by the
+// metrics.
+func (o *Options) GetPrometheusURL() string {
+	return o.PrometheusURL
+}
+
+// GetPrometheusStep returns the resolution of the Prometheus queries.
+func (o *Options) GetPrometheusStep() time.Duration {
+	return o.PrometheusStep
+}
+
+// GetExecMetric returns the command of the named external command metric.
+func (o *Options) GetExecMetric(name string) (string, bool) {
+	command, ok := o.ExecMetrics[name]
+
+	return command, ok
+}
+
+// Indexer returns an indexer going through the n input lines in the order set
+// in options (round-robin by default).
+func (o *Options) Indexer(n int) tester.Indexer {
+	if o.Order == nil {
+		return tester.RoundRobinOrder(n, o.Seed)
+	}
+
+	return o.Order(n, o.Seed)
+}
+
 // AddRecorder adds a recorder to options.
 func (o *Options) AddRecorder(recorder bender.Recorder) {
 	o.Recorders = append(o.Recorders, recorder)
 }
+
+// WatchProcess starts sampling the resource usage of a process (pid or name)
+// during the tests. Every process is sampled once, no matter how many times it
+// is watched.
+func (o *Options) WatchProcess(process string) *recorders.ProcessUsage {
+	if o.Processes == nil {
+		o.Processes = make(map[string]*recorders.ProcessUsage)
+	}
+
+	usage, ok := o.Processes[process]
+	if !ok {
+		usage = &recorders.ProcessUsage{Process: process}
+		o.Processes[process] = usage
+		o.AddRecorder(recorders.NewProcessRecorder(usage, ProcessSampleInterval))
+	}
+
+	return usage
+}
@@ -0,0 +1,245 @@
+/*
+Copyright (c) Facebook, Inc. and its affiliates.
+All rights reserved.
+
+This source code is licensed under the BSD-style license found in the
+LICENSE file in the root directory of this source tree.
+*/
+
+package plan
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	// ErrInvalidPlan is returned when a test plan is malformed.
+	ErrInvalidPlan = errors.New("invalid test plan")
+
+	errInvalidFlag = errors.New("invalid flag")
+)
+
+// Test types available for each of the tests.
+//nolint:gochecknoglobals
+var types = map[string][]string{
+	"throughput":  {"fixed", "constraints", "profile", "replay"},
+	"concurrency": {"fixed", "constraints"},
+}
+
+// Plan is a list of named test sections.
+type Plan struct {
+	Sections []*Section `yaml:"tests"`
+}
+
+// Section describes a single test command.
+type Section struct {
+	Name     string `yaml:"name"`
+	Protocol string `yaml:"protocol"`
+	// Test is either throughput (default) or concurrency
+	Test string `yaml:"test"`
+	// Type is fixed (default), constraints, profile or replay
+	Type        string   `yaml:"type"`
+	Targets     []string `yaml:"targets"`
+	TargetsFile string   `yaml:"targets-file"`
+	Input       string   `yaml:"input"`
+	Duration    string   `yaml:"duration"`
+	// Values are the test values (QPS or workers) or the profile stages
+	Values      []string `yaml:"values"`
+	Constraints []string `yaml:"constraints"`
+	Growth      string   `yaml:"growth"`
+	// Flags are any other flags of the test command, including the protocol
+	// flags, e.g. "timeout: 2s" or "protocol: tcp"
+	Flags Flags `yaml:"flags"`
+}
+
+// Flags are command line flags, a list of values repeats the flag.
+type Flags map[string][]string
+
+// UnmarshalYAML decodes a map of flags keeping the values as they are written,
+// so they are parsed by the flags themselves.
+func (f *Flags) UnmarshalYAML(node *yaml.Node) error {
+	var values map[string]yaml.Node
+	if err := node.Decode(&values); err != nil {
+		//nolint:wrapcheck
+		return err
+	}
+
+	flags := make(Flags, len(values))
+
+	for name, value := range values {
+		nodes := []*yaml.Node{&value}
+		if value.Kind == yaml.SequenceNode {
+			nodes = value.Content
+		}
+
+		for _, node := range nodes {
+			if node.Kind != yaml.ScalarNode || node.Tag == "!!null" {
+				return fmt.Errorf("%w: %q value must be a scalar or a list of scalars", errInvalidFlag, name)
+			}
+
+			flags[name] = append(flags[name], node.Value)
+		}
+	}
+
+	*f = flags
+
+	return nil
+}
+
+// Parse reads a test plan and validates its sections.
+func Parse(r io.Reader) (*Plan, error) {
+	plan := new(Plan)
+
+	decoder := yaml.NewDecoder(r)
+	decoder.KnownFields(true)
+
+	if err := decoder.Decode(plan); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPlan, err)
+	}
+
+	if len(plan.Sections) == 0 {
+		return nil, fmt.Errorf("%w: no tests", ErrInvalidPlan)
+	}
+
+	names := make(map[string]bool)
+
+	for i, section := range plan.Sections {
+		if section == nil {
+			return nil, fmt.Errorf("%w: test %d is empty", ErrInvalidPlan, i)
+		}
+
+		if err := section.validate(); err != nil {
+			return nil, err
+		}
+
+		if names[section.Name] {
+			return nil, fmt.Errorf("%w: duplicate test name %q", ErrInvalidPlan, section.Name)
+		}
+
+		names[section.Name] = true
+	}
+
+	return plan, nil
+}
+
+// Select returns the sections with the given names in the plan order, all the
+// sections if no names are given.
+func (p *Plan) Select(names ...string) ([]*Section, error) {
+	if len(names) == 0 {
+		return p.Sections, nil
+	}
+
+	selected := make(map[string]bool)
+	for _, name := range names {
+		selected[name] = true
+	}
+
+	sections := make([]*Section, 0, len(names))
+
+	for _, section := range p.Sections {
+		if selected[section.Name] {
+			sections = append(sections, section)
+			delete(selected, section.Name)
+		}
+	}
+
+	for _, name := range names {
+		if selected[name] {
+			return nil, fmt.Errorf("%w: unknown test %q", ErrInvalidPlan, name)
+		}
+	}
+
+	return sections, nil
+}
+
+// validate fills in the defaults and checks the required fields.
+func (s *Section) validate() error {
+	if len(s.Name) == 0 {
+		return fmt.Errorf("%w: test name is required", ErrInvalidPlan)
+	}
+
+	if len(s.Protocol) == 0 {
+		return fmt.Errorf("%w: test %q protocol is required", ErrInvalidPlan, s.Name)
+	}
+
+	if len(s.Test) == 0 {
+		s.Test = "throughput"
+	}
+
+	if len(s.Type) == 0 {
+		s.Type = "fixed"
+	}
+
+	available, ok := types[s.Test]
+	if !ok {
+		return fmt.Errorf("%w: test %q must be throughput or concurrency, got: %q", ErrInvalidPlan, s.Name, s.Test)
+	}
+
+	for _, typ := range available {
+		if typ == s.Type {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: test %q type must be one of %v, got: %q", ErrInvalidPlan, s.Name, available, s.Type)
+}
+
+// Args returns the command line arguments running the section. The global
+// flags precede the section flags, so the section can override them.
+func (s *Section) Args(global ...string) []string {
+	args := append([]string{s.Protocol, s.Test, s.Type}, global...)
+
+	for _, target := range s.Targets {
+		args = append(args, "--target", target)
+	}
+
+	args = appendFlag(args, "targets-file", s.TargetsFile)
+	args = appendFlag(args, "input", s.Input)
+	args = appendFlag(args, "duration", s.Duration)
+
+	for _, constraint := range s.Constraints {
+		args = append(args, "--constraints", constraint)
+	}
+
+	args = appendFlag(args, "growth", s.Growth)
+	args = append(args, flagArgs(s.Flags)...)
+
+	// Values may look like flags, e.g. negative numbers
+	args = append(args, "--")
+
+	return append(args, s.Values...)
+}
+
+// flagArgs converts the flags to command line arguments.
+func flagArgs(flags Flags) []string {
+	names := make([]string, 0, len(flags))
+	for name := range flags {
+		names = append(names, name)
+	}
+
+	// Make the command line deterministic
+	sort.Strings(names)
+
+	args := make([]string, 0, len(flags))
+
+	for _, name := range names {
+		for _, value := range flags[name] {
+			args = append(args, fmt.Sprintf("--%s=%s", name, value))
+		}
+	}
+
+	return args
+}
+
+func appendFlag(args []string, name, value string) []string {
+	if len(value) == 0 {
+		return args
+	}
+
+	return append(args, fmt.Sprintf("--%s=%s", name, value))
+}
@@ -0,0 +1,96 @@
+/*
+Copyright (c) Facebook, Inc. and its affiliates.
+All rights reserved.
+
+This source code is licensed under the BSD-style license found in the
+LICENSE file in the root directory of this source tree.
+*/
+
+package plan_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/facebookincubator/fbender/cmd/core/plan"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testPlan = `
+tests:
+  - name: baseline
+    protocol: dns
+    targets: [a, b 2]
+    input: queries.txt
+    duration: 5m
+    values: [100, 200]
+    flags:
+      protocol: tcp
+      randomize: true
+      timeout: 1.50s
+      agents: [h1:7878, h2:7878]
+  - name: capacity
+    protocol: http
+    test: concurrency
+    type: constraints
+    targets: [c]
+    values: [10]
+    constraints: ["MAX(errors) < 5", "AVG(latency) < 20"]
+    growth: ^5
+`
+
+func TestParse(t *testing.T) {
+	p, err := plan.Parse(strings.NewReader(testPlan))
+	require.NoError(t, err)
+	require.Len(t, p.Sections, 2)
+
+	assert.Equal(t, []string{
+		"dns", "throughput", "fixed", "--target", "a", "--target", "b 2", "--input=queries.txt",
+		"--duration=5m", "--agents=h1:7878", "--agents=h2:7878", "--protocol=tcp", "--randomize=true",
+		"--timeout=1.50s", "--", "100", "200",
+	}, p.Sections[0].Args())
+
+	assert.Equal(t, []string{
+		"http", "concurrency", "constraints", "--target", "c", "--constraints", "MAX(errors) < 5",
+		"--constraints", "AVG(latency) < 20", "--growth=^5", "--", "10",
+	}, p.Sections[1].Args())
+}
+
+func TestParse__Invalid(t *testing.T) {
+	tests := map[string]string{
+		"empty":     "tests: []",
+		"unknown":   "tests: [{name: a, protocol: dns, qps: 10}]",
+		"name":      "tests: [{protocol: dns}]",
+		"protocol":  "tests: [{name: a}]",
+		"test":      "tests: [{name: a, protocol: dns, test: latency}]",
+		"type":      "tests: [{name: a, protocol: dns, test: concurrency, type: profile}]",
+		"duplicate": "tests: [{name: a, protocol: dns}, {name: a, protocol: udp}]",
+		"flag map":  "tests: [{name: a, protocol: dns, flags: {timeout: {value: 1s}}}]",
+		"flag list": "tests: [{name: a, protocol: dns, flags: {agents: [[h1, h2]]}}]",
+		"flag null": "tests: [{name: a, protocol: dns, flags: {timeout: }}]",
+	}
+
+	for name, input := range tests {
+		_, err := plan.Parse(strings.NewReader(input))
+		assert.ErrorIs(t, err, plan.ErrInvalidPlan, name)
+	}
+}
+
+func TestPlan__Select(t *testing.T) {
+	p, err := plan.Parse(strings.NewReader(testPlan))
+	require.NoError(t, err)
+
+	sections, err := p.Select()
+	require.NoError(t, err)
+	assert.Len(t, sections, 2)
+
+	sections, err = p.Select("capacity", "baseline")
+	require.NoError(t, err)
+	require.Len(t, sections, 2)
+	assert.Equal(t, "baseline", sections[0].Name)
+	assert.Equal(t, "capacity", sections[1].Name)
+
+	_, err = p.Select("baseline", "missing")
+	assert.ErrorIs(t, err, plan.ErrInvalidPlan)
+}
@@ -0,0 +1,105 @@
+/*
+Copyright (c) Facebook, Inc. and its affiliates.
+All rights reserved.
+
+This source code is licensed under the BSD-style license found in the
+LICENSE file in the root directory of this source tree.
+*/
+
+package plan
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrInvalidScenario is returned when a scenario is malformed.
+var ErrInvalidScenario = errors.New("invalid scenario")
+
+// Scenario is a mixed workload, the load of a test is shared among its parts.
+type Scenario struct {
+	Parts []*Part `yaml:"parts"`
+}
+
+// Part describes the load sent with a single protocol to a single target.
+type Part struct {
+	// Name identifies the part in the statistics and constraints, it defaults
+	// to the protocol
+	Name     string `yaml:"name"`
+	Protocol string `yaml:"protocol"`
+	Target   string `yaml:"target"`
+	Input    string `yaml:"input"`
+	// Share is the weight of the part in the load, it defaults to 1
+	Share float64 `yaml:"share"`
+	// Flags are the protocol flags, e.g. "protocol: tcp"
+	Flags Flags `yaml:"flags"`
+}
+
+// ParseScenario reads a scenario and validates its parts.
+func ParseScenario(r io.Reader) (*Scenario, error) {
+	scenario := new(Scenario)
+
+	decoder := yaml.NewDecoder(r)
+	decoder.KnownFields(true)
+
+	if err := decoder.Decode(scenario); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidScenario, err)
+	}
+
+	if len(scenario.Parts) == 0 {
+		return nil, fmt.Errorf("%w: no parts", ErrInvalidScenario)
+	}
+
+	names := make(map[string]bool)
+
+	for i, part := range scenario.Parts {
+		if part == nil {
+			return nil, fmt.Errorf("%w: part %d is empty", ErrInvalidScenario, i)
+		}
+
+		if err := part.validate(); err != nil {
+			return nil, err
+		}
+
+		if names[part.Name] {
+			return nil, fmt.Errorf("%w: duplicate part name %q, name the parts explicitly", ErrInvalidScenario, part.Name)
+		}
+
+		names[part.Name] = true
+	}
+
+	return scenario, nil
+}
+
+// validate fills in the defaults and checks the required fields.
+func (p *Part) validate() error {
+	if len(p.Protocol) == 0 {
+		return fmt.Errorf("%w: part protocol is required", ErrInvalidScenario)
+	}
+
+	if len(p.Name) == 0 {
+		p.Name = p.Protocol
+	}
+
+	if len(p.Target) == 0 {
+		return fmt.Errorf("%w: part %q target is required", ErrInvalidScenario, p.Name)
+	}
+
+	if p.Share == 0 {
+		p.Share = 1
+	}
+
+	if p.Share < 0 {
+		return fmt.Errorf("%w: part %q share must be positive, got: %f", ErrInvalidScenario, p.Name, p.Share)
+	}
+
+	return nil
+}
+
+// Args returns the command line arguments of the protocol flags.
+func (p *Part) Args() []string {
+	return flagArgs(p.Flags)
+}
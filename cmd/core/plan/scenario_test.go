@@ -0,0 +1,63 @@
+/*
+Copyright (c) Facebook, Inc. and its affiliates.
+All rights reserved.
+
+This source code is licensed under the BSD-style license found in the
+LICENSE file in the root directory of this source tree.
+*/
+
+package plan_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/facebookincubator/fbender/cmd/core/plan"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testScenario = `
+parts:
+  - protocol: dns
+    target: a
+    input: queries.txt
+    share: 3
+    flags:
+      protocol: tcp
+      randomize: true
+  - name: web
+    protocol: http
+    target: b:8080
+    input: urls.txt
+`
+
+func TestParseScenario(t *testing.T) {
+	s, err := plan.ParseScenario(strings.NewReader(testScenario))
+	require.NoError(t, err)
+	require.Len(t, s.Parts, 2)
+
+	assert.Equal(t, "dns", s.Parts[0].Name)
+	assert.Equal(t, 3., s.Parts[0].Share)
+	assert.Equal(t, []string{"--protocol=tcp", "--randomize=true"}, s.Parts[0].Args())
+
+	assert.Equal(t, "web", s.Parts[1].Name)
+	assert.Equal(t, 1., s.Parts[1].Share)
+	assert.Empty(t, s.Parts[1].Args())
+}
+
+func TestParseScenario__Invalid(t *testing.T) {
+	tests := map[string]string{
+		"empty":     "parts: []",
+		"unknown":   "parts: [{protocol: dns, target: a, qps: 10}]",
+		"protocol":  "parts: [{target: a}]",
+		"target":    "parts: [{protocol: dns}]",
+		"share":     "parts: [{protocol: dns, target: a, share: -1}]",
+		"duplicate": "parts: [{protocol: dns, target: a}, {protocol: dns, target: b}]",
+	}
+
+	for name, input := range tests {
+		_, err := plan.ParseScenario(strings.NewReader(input))
+		assert.ErrorIs(t, err, plan.ErrInvalidScenario, name)
+	}
+}
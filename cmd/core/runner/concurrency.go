@@ -22,8 +22,9 @@ import (
 // ConcurrencyRunner is a test runner for load test concurrency commands.
 type ConcurrencyRunner struct {
 	runner
-	workerSem     *bender.WorkerSemaphore
-	spinnerCancel context.CancelFunc
+	workerSem *bender.WorkerSemaphore
+	cancel    context.CancelFunc
+	spinner   chan context.CancelFunc
 }
 
 // NewConcurrencyRunner returns new ConcurrencyRunner.
@@ -36,7 +37,7 @@ func NewConcurrencyRunner(params *Params) *ConcurrencyRunner {
 }
 
 // Before prepares requests, recorders and interval generator.
-func (r *ConcurrencyRunner) Before(workers tester.Workers, opts interface{}) error {
+func (r *ConcurrencyRunner) Before(ctx context.Context, workers tester.Workers, opts interface{}) error {
 	if err := r.runner.Before(workers, opts); err != nil {
 		return err
 	}
@@ -46,26 +47,38 @@ func (r *ConcurrencyRunner) Before(workers tester.Workers, opts interface{}) err
 		return tester.ErrInvalidOptions
 	}
 
+	err := r.warmup(ctx, workers, o, func(ctx context.Context, executor bender.RequestExecutor,
+		recorder chan interface{}) {
+		workerSem := bender.NewWorkerSemaphore()
+
+		go func() { workerSem.Signal(workers) }()
+
+		bender.LoadTestConcurrency(workerSem, r.generate(ctx, -1, o.BufferSize), executor, recorder)
+	})
+	if err != nil {
+		return err
+	}
+
 	r.workerSem = bender.NewWorkerSemaphore()
 
 	go func() { r.workerSem.Signal(workers) }()
 
-	r.requests = make(chan interface{})
+	ctx, r.cancel = context.WithCancel(ctx)
+	r.spinner = make(chan context.CancelFunc, 1)
 
-	ctx, cancel := context.WithCancel(context.Background())
+	// The test bounded by the number of requests ends once all of them are
+	// completed, so the progressbar measures the completed requests.
+	if o.Requests > 0 {
+		r.requests = r.generate(ctx, o.Requests, o.BufferSize)
+		r.progress, r.bar = recorders.NewLoadTestProgress(o.Requests)
+		r.progress.Start()
+		r.recorders = append(r.recorders, recorders.NewProgressBarRecorder(r.bar))
+		r.spinner <- r.progress.Stop
 
-	go func() {
-		for i := 0; ; i++ {
-			select {
-			case <-ctx.Done():
-				close(r.requests)
+		return nil
+	}
 
-				return
-			default:
-				r.requests <- r.Params.RequestGenerator(i)
-			}
-		}
-	}()
+	r.requests = r.generate(ctx, -1, o.BufferSize)
 
 	// We want tne progressbar to measure the time passed.
 	const scale = 10
@@ -74,22 +87,32 @@ func (r *ConcurrencyRunner) Before(workers tester.Workers, opts interface{}) err
 	r.progress, r.bar = recorders.NewLoadTestProgress(count)
 	r.progress.Start()
 
-	go func() {
+	go func(cancel context.CancelFunc) {
+		ticker := time.NewTicker(time.Second / scale)
+		defer ticker.Stop()
+
+	loop:
 		for i := 0; i < count; i++ {
-			time.Sleep(time.Second / scale)
-			r.bar.Incr()
+			select {
+			case <-ctx.Done():
+				break loop
+			case <-ticker.C:
+				r.bar.Incr()
+			}
 		}
+
 		cancel()
 		r.progress.Stop()
-		r.spinnerCancel = utils.NewBackgroundSpinner("Waiting for tests to finish", 0)
-	}()
+		r.spinner <- utils.NewBackgroundSpinner("Waiting for tests to finish", 0)
+	}(r.cancel)
 
 	return nil
 }
 
 // After cleans up after the test.
 func (r *ConcurrencyRunner) After(test int, options interface{}) {
-	r.spinnerCancel()
+	r.cancel()
+	(<-r.spinner)()
 	r.runner.After(test, options)
 }
 
@@ -0,0 +1,66 @@
+/*
+Copyright (c) Facebook, Inc. and its affiliates.
+All rights reserved.
+
+This source code is licensed under the BSD-style license found in the
+LICENSE file in the root directory of this source tree.
+*/
+
+package runner
+
+import (
+	"bytes"
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/facebookincubator/fbender/recorders"
+	"github.com/facebookincubator/fbender/tester"
+)
+
+// errorClasses sets up counting the failed requests by their error class.
+func (r *runner) errorClasses() {
+	r.errors = new(recorders.ErrorClasses)
+	r.recorders = append(r.recorders, recorders.NewErrorClassesRecorder(r.errors))
+}
+
+// errorClassesString returns a table with the number of errors of every class
+// that occurred in the test.
+func (r *runner) errorClassesString() string {
+	buf := new(bytes.Buffer)
+	w := tabwriter.NewWriter(buf, 0, 0, 2, ' ', tabwriter.AlignRight)
+
+	fmt.Fprintln(w, "Class\tErrors\tPercent of requests\t")
+
+	requests := r.errors.Requests()
+
+	for _, class := range tester.ErrorClasses {
+		errors := r.errors.Errors(class)
+		if errors == 0 {
+			continue
+		}
+
+		percent := float64(errors) / float64(requests) * 100
+		fmt.Fprintf(w, "%s\t%d\t%.2f\t\n", class, errors, percent)
+	}
+
+	if err := w.Flush(); err != nil {
+		return err.Error()
+	}
+
+	return buf.String()
+}
+
+// failed checks whether any request of the test failed.
+func (r *runner) failed() bool {
+	if r.errors == nil {
+		return false
+	}
+
+	for _, class := range tester.ErrorClasses {
+		if r.errors.Errors(class) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
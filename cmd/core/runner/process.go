@@ -0,0 +1,111 @@
+/*
+Copyright (c) Facebook, Inc. and its affiliates.
+All rights reserved.
+
+This source code is licensed under the BSD-style license found in the
+LICENSE file in the root directory of this source tree.
+*/
+
+package runner
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/facebookincubator/fbender/cmd/core/options"
+	"github.com/facebookincubator/fbender/recorders"
+)
+
+// processesString returns a table with the resource usage of the watched
+// processes.
+func processesString(o *options.Options) string {
+	processes := make([]string, 0, len(o.Processes))
+	for process := range o.Processes {
+		processes = append(processes, process)
+	}
+
+	sort.Strings(processes)
+
+	buf := new(bytes.Buffer)
+	w := tabwriter.NewWriter(buf, 0, 0, 2, ' ', tabwriter.AlignRight)
+
+	fmt.Fprintln(w, "Process\tSamples\tAverage CPU (%)\tMax CPU (%)\tMax RSS (MiB)\tMax FDs\tMax threads\t")
+
+	for _, process := range processes {
+		samples := o.Processes[process].Samples()
+		if len(samples) == 0 {
+			fmt.Fprintf(w, "%s\t0\t-\t-\t-\t-\t-\t\n", process)
+
+			continue
+		}
+
+		usage := summarizeProcess(samples)
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%.2f\t%s\t%d\t\n", process, len(samples),
+			optionalValue(usage.averageCPU, "%.2f"), optionalValue(usage.maxCPU, "%.2f"),
+			float64(usage.maxRSS)/recorders.BytesInMiB, optionalValue(float64(usage.maxFDs), "%.0f"), usage.maxThreads)
+	}
+
+	if err := w.Flush(); err != nil {
+		return err.Error()
+	}
+
+	return buf.String()
+}
+
+// processUsage groups the summary of the process samples, negative values are
+// unavailable.
+type processUsage struct {
+	averageCPU, maxCPU float64
+	maxRSS             int64
+	maxFDs             int64
+	maxThreads         int64
+}
+
+func summarizeProcess(samples []recorders.ProcessSample) processUsage {
+	usage := processUsage{averageCPU: -1, maxCPU: -1, maxFDs: -1}
+
+	var (
+		cpu   float64
+		count int
+	)
+
+	for _, sample := range samples {
+		if sample.CPU >= 0 {
+			cpu += sample.CPU
+			count++
+
+			if sample.CPU > usage.maxCPU {
+				usage.maxCPU = sample.CPU
+			}
+		}
+
+		if sample.RSS > usage.maxRSS {
+			usage.maxRSS = sample.RSS
+		}
+
+		if sample.FDs > usage.maxFDs {
+			usage.maxFDs = sample.FDs
+		}
+
+		if sample.Threads > usage.maxThreads {
+			usage.maxThreads = sample.Threads
+		}
+	}
+
+	if count > 0 {
+		usage.averageCPU = cpu / float64(count)
+	}
+
+	return usage
+}
+
+// optionalValue formats the value unless it's unavailable (negative).
+func optionalValue(value float64, format string) string {
+	if value < 0 {
+		return "-"
+	}
+
+	return fmt.Sprintf(format, value)
+}
@@ -0,0 +1,83 @@
+/*
+Copyright (c) Facebook, Inc. and its affiliates.
+All rights reserved.
+
+This source code is licensed under the BSD-style license found in the
+LICENSE file in the root directory of this source tree.
+*/
+
+package runner
+
+import (
+	"context"
+
+	"github.com/facebookincubator/fbender/cmd/core/options"
+	"github.com/facebookincubator/fbender/log"
+	"github.com/facebookincubator/fbender/recorders"
+	"github.com/facebookincubator/fbender/tester"
+	"github.com/facebookincubator/fbender/tester/run"
+	"github.com/pinterest/bender"
+	"github.com/sirupsen/logrus"
+)
+
+// ProfileRunner is a test runner for load test throughput commands following
+// a load profile instead of a constant QPS.
+type ProfileRunner struct {
+	ThroughputRunner
+}
+
+// NewProfileRunner returns new ProfileRunner.
+func NewProfileRunner(params *Params) *ProfileRunner {
+	return &ProfileRunner{
+		ThroughputRunner: ThroughputRunner{
+			runner: runner{
+				Params: params,
+			},
+		},
+	}
+}
+
+// Before prepares requests, recorders and interval generator. The test value
+// is used only to identify the test, the QPS is defined by the profile.
+func (r *ProfileRunner) Before(ctx context.Context, test tester.QPS, opts interface{}) error {
+	if err := r.runner.Before(test, opts); err != nil {
+		return err
+	}
+
+	o, ok := opts.(*options.Options)
+	if !ok {
+		return tester.ErrInvalidOptions
+	}
+
+	start := o.Profile[0].From
+	err := r.warmup(ctx, test, o, func(ctx context.Context, executor bender.RequestExecutor,
+		recorder chan interface{}) {
+		count := int(start * o.Warmup.Seconds())
+		requests := r.generate(ctx, count, o.BufferSize)
+		run.StartThroughput(o.Distribution(start), requests, executor, recorder, o)
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Profile: %s\n", o.Profile)
+
+	count := o.Profile.Requests()
+	r.intervals = o.Profile.IntervalGenerator(o.Distribution, func(i int, stage *tester.Stage) {
+		logrus.WithFields(logrus.Fields{
+			"test":  test,
+			"stage": i,
+			"qps":   stage.From,
+		}).Infof("Stage: %s", stage)
+	})
+	r.requests = r.generate(ctx, count, o.BufferSize)
+
+	r.progress, r.bar = recorders.NewLoadTestProgress(count)
+	r.progress.Start()
+	r.recorders = append(r.recorders, recorders.NewProgressBarRecorder(r.bar))
+	r.correct(o)
+	r.limit(o)
+	r.saturate()
+
+	return nil
+}
@@ -0,0 +1,276 @@
+/*
+Copyright (c) Facebook, Inc. and its affiliates.
+All rights reserved.
+
+This source code is licensed under the BSD-style license found in the
+LICENSE file in the root directory of this source tree.
+*/
+
+package runner
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/facebookincubator/fbender/cmd/core/agent"
+	"github.com/facebookincubator/fbender/cmd/core/errors"
+	"github.com/facebookincubator/fbender/cmd/core/options"
+	"github.com/facebookincubator/fbender/log"
+	"github.com/facebookincubator/fbender/recorders"
+	"github.com/facebookincubator/fbender/tester"
+	"github.com/facebookincubator/fbender/utils"
+	"github.com/pinterest/bender"
+)
+
+// RemoteRunner is a test runner which delegates the load generation to agents
+// and merges the events they send back. It can be used both as a throughput
+// and a concurrency runner, the agents run the test of the given kind.
+type RemoteRunner struct {
+	ThroughputRunner
+	kind   string
+	agents []string
+	conns  []*agent.Conn
+}
+
+// NewRemoteRunner returns new RemoteRunner. The jobs sent to the agents are
+// based on the params job.
+func NewRemoteRunner(params *Params, kind string, agents []string) *RemoteRunner {
+	return &RemoteRunner{
+		ThroughputRunner: ThroughputRunner{
+			runner: runner{
+				Params: params,
+			},
+		},
+		kind:   kind,
+		agents: agents,
+	}
+}
+
+// Before sends the job to all the agents and waits until they are ready.
+func (r *RemoteRunner) Before(ctx context.Context, test int, opts interface{}) error {
+	if err := r.runner.Before(test, opts); err != nil {
+		return err
+	}
+
+	o, ok := opts.(*options.Options)
+	if !ok {
+		return tester.ErrInvalidOptions
+	}
+
+	// Every agent generates a part of the QPS or runs a part of the workers
+	if r.kind != agent.Profile && test < len(r.agents) {
+		return fmt.Errorf("%w: test value must be at least the number of agents, got: %d < %d",
+			errors.ErrInvalidArgument, test, len(r.agents))
+	}
+
+	cancel := utils.NewBackgroundSpinner(fmt.Sprintf("Preparing the test on %d agents", len(r.agents)), 0)
+	conns, err := r.dial(test, o)
+
+	cancel()
+
+	if err != nil {
+		return err
+	}
+
+	r.conns = conns
+
+	if r.kind == agent.Profile {
+		log.Printf("Profile: %s\n", o.Profile)
+	}
+
+	r.corrected = nil
+	r.saturation = nil
+
+	var count int
+
+	switch r.kind {
+	case agent.Throughput:
+		count = requests(o, test)
+	case agent.Profile:
+		count = o.Profile.Requests()
+	default:
+		return nil
+	}
+
+	r.progress, r.bar = recorders.NewLoadTestProgress(count)
+	r.progress.Start()
+	r.recorders = append(r.recorders, recorders.NewProgressBarRecorder(r.bar))
+	r.correct(o)
+	r.limit(o)
+
+	return nil
+}
+
+// dial connects to all the agents concurrently. If any of them fails the
+// others are disconnected.
+func (r *RemoteRunner) dial(test int, o *options.Options) ([]*agent.Conn, error) {
+	conns := make([]*agent.Conn, len(r.agents))
+	errs := make([]error, len(r.agents))
+
+	template := *r.Params.Job
+	template.Kind = r.kind
+	template.Targets = o.Targets
+	template.Duration = o.Duration
+	template.Test = test
+	template.Agents = len(r.agents)
+
+	if r.kind == agent.Profile {
+		template.SetProfile(o.Profile)
+	}
+
+	var wg sync.WaitGroup
+
+	for i, address := range r.agents {
+		wg.Add(1)
+
+		go func(i int, address string) {
+			defer wg.Done()
+
+			job := template
+			job.Agent = i
+			conns[i], errs[i] = agent.Dial(address, &job, o.AgentsSecret)
+		}(i, address)
+	}
+
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			for _, conn := range conns {
+				if conn != nil {
+					conn.Close()
+				}
+			}
+
+			return nil, err
+		}
+	}
+
+	return conns, nil
+}
+
+// Load starts the test on all the agents at once and merges the events they
+// send into the recorder. The agents are stopped once the context is canceled.
+func (r *RemoteRunner) Load(ctx context.Context, recorder chan interface{}) {
+	go func() {
+		start := time.Now().UnixNano()
+		recorder <- &bender.StartEvent{Start: start}
+
+		for _, conn := range r.conns {
+			if err := conn.Start(); err != nil {
+				log.Errorf("Warning: Error starting the test on %s: %v\n", conn.Address, err)
+			}
+		}
+
+		done := make(chan struct{})
+		defer close(done)
+
+		go func() {
+			select {
+			case <-done:
+			case <-ctx.Done():
+				for _, conn := range r.conns {
+					if err := conn.Stop(); err != nil {
+						log.Errorf("Warning: Error stopping the test on %s: %v\n", conn.Address, err)
+					}
+				}
+			}
+		}()
+
+		var wg sync.WaitGroup
+
+		for _, conn := range r.conns {
+			wg.Add(1)
+
+			go func(conn *agent.Conn) {
+				defer wg.Done()
+
+				if err := conn.Events(recorder); err != nil {
+					log.Errorf("Warning: %v\n", err)
+				}
+			}(conn)
+		}
+
+		wg.Wait()
+		r.saturation = r.mergeSaturation(start)
+		recorder <- &bender.EndEvent{Start: start, End: time.Now().UnixNano()}
+		close(recorder)
+	}()
+}
+
+// mergeSaturation combines the saturation statistics reported by the agents,
+// so the test fails if they didn't keep up with the schedule together. It
+// returns nil if none of them reported any.
+func (r *RemoteRunner) mergeSaturation(start int64) *recorders.Saturation {
+	var saturation *recorders.Saturation
+
+	for _, conn := range r.conns {
+		if conn.Saturation == nil {
+			continue
+		}
+
+		if saturation == nil {
+			saturation = &recorders.Saturation{Start: start}
+		}
+
+		saturation.Merge(conn.Saturation)
+	}
+
+	return saturation
+}
+
+// After disconnects from the agents.
+func (r *RemoteRunner) After(test int, opts interface{}) {
+	for _, conn := range r.conns {
+		conn.Close()
+	}
+
+	r.conns = nil
+
+	if r.kind == agent.Concurrency {
+		r.runner.After(test, opts)
+	} else {
+		r.ThroughputRunner.After(test, opts)
+	}
+}
+
+// Tester returns a tester which does nothing, the agents set up their own.
+func (r *RemoteRunner) Tester() tester.Tester {
+	return remoteTester{}
+}
+
+// Intervals is not used, the agents generate the intervals on their own.
+func (r *RemoteRunner) Intervals() bender.IntervalGenerator {
+	return nil
+}
+
+// WorkerSemaphore is not used, the agents manage the workers on their own.
+func (r *RemoteRunner) WorkerSemaphore() *bender.WorkerSemaphore {
+	return nil
+}
+
+// remoteTester is a tester for the remote runner.
+type remoteTester struct{}
+
+// Before is called before the first test.
+func (remoteTester) Before(_ interface{}) error {
+	return nil
+}
+
+// After is called after all tests are finished.
+func (remoteTester) After(_ interface{}) {}
+
+// BeforeEach is called before every test.
+func (remoteTester) BeforeEach(_ interface{}) error {
+	return nil
+}
+
+// AfterEach is called after every test.
+func (remoteTester) AfterEach(_ interface{}) {}
+
+// RequestExecutor is not used, the agents execute the requests.
+func (remoteTester) RequestExecutor(_ context.Context, _ interface{}) (bender.RequestExecutor, error) {
+	return nil, nil
+}
@@ -0,0 +1,129 @@
+/*
+Copyright (c) Facebook, Inc. and its affiliates.
+All rights reserved.
+
+This source code is licensed under the BSD-style license found in the
+LICENSE file in the root directory of this source tree.
+*/
+
+package runner
+
+import (
+	"context"
+	"time"
+
+	"github.com/facebookincubator/fbender/cmd/core/options"
+	"github.com/facebookincubator/fbender/log"
+	"github.com/facebookincubator/fbender/recorders"
+	"github.com/facebookincubator/fbender/tester"
+)
+
+// ReplayRunner is a test runner for load test throughput commands replaying
+// a trace with the original intervals between the requests.
+type ReplayRunner struct {
+	ThroughputRunner
+}
+
+// NewReplayRunner returns new ReplayRunner.
+func NewReplayRunner(params *Params) *ReplayRunner {
+	return &ReplayRunner{
+		ThroughputRunner: ThroughputRunner{
+			runner: runner{
+				Params: params,
+			},
+		},
+	}
+}
+
+// Before prepares requests, recorders and interval generator. The test value
+// is used only to identify the test, the intervals are defined by the trace.
+func (r *ReplayRunner) Before(ctx context.Context, test tester.QPS, opts interface{}) error {
+	if err := r.runner.Before(test, opts); err != nil {
+		return err
+	}
+
+	o, ok := opts.(*options.Options)
+	if !ok {
+		return tester.ErrInvalidOptions
+	}
+
+	log.Printf("Replaying at %gx speed for up to %s\n", o.Speed, o.Duration)
+
+	// We want the progress bar to measure the replayed time.
+	const scale = 10
+	count := int(o.Duration/time.Second) * scale
+
+	r.progress, r.bar = recorders.NewLoadTestProgress(count)
+	r.progress.Start()
+
+	trace := tester.NewTrace(o.Speed)
+	intervals, elapsed := trace.IntervalGenerator(), time.Duration(0)
+	r.intervals = func(now int64) int64 {
+		interval := intervals(now)
+		elapsed += time.Duration(interval)
+
+		if current := int(elapsed * scale / time.Second); current <= count {
+			//nolint:errcheck
+			r.bar.Set(current)
+		}
+
+		return interval
+	}
+	r.requests = r.replay(ctx, trace, o)
+
+	r.correct(o)
+	r.limit(o)
+	r.saturate()
+
+	return nil
+}
+
+// replay pushes the times of the generated requests to the trace and unwraps
+// the requests. It stops once the replayed time exceeds the test duration or
+// the input is exhausted.
+func (r *ReplayRunner) replay(ctx context.Context, trace *tester.Trace, o *options.Options) chan interface{} {
+	ctx, cancel := context.WithCancel(ctx)
+	requests := make(chan interface{})
+	timed := r.generate(ctx, -1, o.BufferSize)
+
+	go func() {
+		defer cancel()
+		defer close(requests)
+
+		for request := range timed {
+			t, request, ok := untime(request)
+			if !ok {
+				log.Errorf("Warning: Skipping request without a timestamp: %v\n", request)
+
+				continue
+			}
+
+			if trace.Push(t) > o.Duration {
+				return
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case requests <- request:
+			}
+		}
+	}()
+
+	return requests
+}
+
+// untime returns the time of a timed request (possibly bound to one of the
+// targets) and the request without the time.
+func untime(request interface{}) (time.Time, interface{}, bool) {
+	switch r := request.(type) {
+	case *tester.TimedRequest:
+		return r.Time, r.Request, true
+	case *tester.TargetedRequest:
+		if timed, ok := r.Request.(*tester.TimedRequest); ok {
+			return timed.Time, &tester.TargetedRequest{Target: r.Target, Request: timed.Request}, true
+		}
+	}
+
+	return time.Time{}, request, false
+}
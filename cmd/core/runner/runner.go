@@ -9,8 +9,11 @@ LICENSE file in the root directory of this source tree.
 package runner
 
 import (
+	"context"
+	"fmt"
 	"runtime"
 
+	"github.com/facebookincubator/fbender/cmd/core/agent"
 	"github.com/facebookincubator/fbender/cmd/core/options"
 	"github.com/facebookincubator/fbender/log"
 	"github.com/facebookincubator/fbender/recorders"
@@ -22,13 +25,21 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
-// RequestGenerator is used to generate requests.
+// RequestGenerator is used to generate requests. It returns nil once there are
+// no more requests to send.
 type RequestGenerator func(i int) interface{}
 
 // Params represents test parameters for the runner.
 type Params struct {
 	Tester           tester.Tester
 	RequestGenerator RequestGenerator
+	// Targets lists the targets when the load is spread among multiple of them
+	Targets []string
+	// Parts are the parts of a scenario, each of them run with its own load
+	// and named by the target of the same index
+	Parts []*Part
+	// Job is the template of the jobs a coordinator sends to the agents
+	Job *agent.Job
 }
 
 // runner groups fields used in both runners.
@@ -38,9 +49,19 @@ type runner struct {
 
 	recorders []bender.Recorder
 	histogram *hist.Histogram
+	breakdown map[string]*recorders.TargetStatistics
+	errors    *recorders.ErrorClasses
 	progress  *uiprogress.Progress
 	bar       *uiprogress.Bar
 
+	// Statistics of the current trial and results of the previous trials of
+	// a repeated test
+	statistics *recorders.Statistics
+	trials     tester.Trials
+
+	// Snapshots of the statistics gathered in a soak test
+	snapshots []*recorders.Snapshot
+
 	Params *Params
 }
 
@@ -50,6 +71,10 @@ func (r *runner) reset() {
 	r.recorder = nil
 	r.recorders = nil
 	r.histogram = nil
+	r.breakdown = nil
+	r.errors = nil
+	r.statistics = nil
+	r.snapshots = nil
 	r.progress = nil
 	r.bar = nil
 }
@@ -81,18 +106,123 @@ func (r *runner) Before(test int, opts interface{}) error {
 		r.recorders = append(r.recorders, bender.NewHistogramRecorder(r.histogram))
 	}
 
+	r.errorClasses()
+	r.targets(o)
+	r.repeat(o)
+	r.soak(o)
+
 	cancel()
 
-	log.Printf("Running test: %d\n", test)
+	if o.Repeat > 1 {
+		log.Printf("Running test: %d (trial %d/%d)\n", test, r.trials.Count()+1, o.Repeat)
+	} else {
+		log.Printf("Running test: %d\n", test)
+	}
 
 	return nil
 }
 
 // After cleans up after the test.
-func (r *runner) After(test int, options interface{}) {
+func (r *runner) After(test int, opts interface{}) {
 	if r.histogram != nil {
 		log.Printf("%s", r.histogram.String())
 	}
+
+	o, ok := opts.(*options.Options)
+	if !ok {
+		return
+	}
+
+	if r.breakdown != nil {
+		log.Printf("Targets:\n%s", r.breakdownString(o))
+	}
+
+	if r.failed() {
+		log.Printf("Errors:\n%s", r.errorClassesString())
+	}
+
+	if len(o.Processes) > 0 {
+		log.Printf("Processes:\n%s", processesString(o))
+	}
+
+	if len(r.snapshots) > 0 {
+		log.Printf("Time series (every %s):\n%s", o.ReportInterval, r.soakString(o))
+	}
+
+	r.trial(o)
+}
+
+// generate starts generating requests in background. It stops and closes the
+// returned channel once count requests have been generated (count < 0 means
+// no limit), the request generator runs out of requests or the context is
+// canceled. The requests are buffered internally
+// so the returned channel never holds requests which would be sent after the
+// context is canceled.
+func (r *runner) generate(ctx context.Context, count int, bufferSize int) chan interface{} {
+	buffer := make(chan interface{}, bufferSize)
+	requests := make(chan interface{})
+
+	go func() {
+		defer close(buffer)
+
+		for i := 0; count < 0 || i < count; i++ {
+			request := r.Params.RequestGenerator(i)
+			if request == nil {
+				return
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case buffer <- request:
+			}
+		}
+	}()
+
+	go func() {
+		defer close(requests)
+
+		for request := range buffer {
+			select {
+			case <-ctx.Done():
+				return
+			case requests <- request:
+			}
+		}
+	}()
+
+	return requests
+}
+
+// warmup runs the given load for the warm-up duration before the test, the
+// load stops generating requests once the context passed to it is done. Only
+// the logs are recorded during the warm-up and it's over before the measured
+// part of the test starts, so it affects neither statistics nor constraints.
+func (r *runner) warmup(ctx context.Context, test int, o *options.Options,
+	load func(ctx context.Context, executor bender.RequestExecutor, recorder chan interface{})) error {
+	if o.Warmup <= 0 {
+		return nil
+	}
+
+	executor, err := r.Params.Tester.RequestExecutor(ctx, o)
+	if err != nil {
+		//nolint:wrapcheck
+		return err
+	}
+
+	cancel := utils.NewBackgroundSpinner(fmt.Sprintf("Warming up for %s", o.Warmup), 0)
+	defer cancel()
+
+	warmup, stop := context.WithTimeout(ctx, o.Warmup)
+	defer stop()
+
+	recorder := make(chan interface{}, o.BufferSize)
+	load(warmup, executor, recorder)
+	bender.Record(recorder, recorders.NewLogrusRecorder(
+		logrus.StandardLogger(), logrus.Fields{"test": test, "warmup": true},
+	))
+
+	return nil
 }
 
 // Tester returns the protocol tester.
@@ -0,0 +1,427 @@
+/*
+Copyright (c) Facebook, Inc. and its affiliates.
+All rights reserved.
+
+This source code is licensed under the BSD-style license found in the
+LICENSE file in the root directory of this source tree.
+*/
+
+package runner
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/facebookincubator/fbender/cmd/core/agent"
+	"github.com/facebookincubator/fbender/cmd/core/errors"
+	"github.com/facebookincubator/fbender/cmd/core/options"
+	"github.com/facebookincubator/fbender/log"
+	"github.com/facebookincubator/fbender/recorders"
+	"github.com/facebookincubator/fbender/tester"
+	"github.com/facebookincubator/fbender/tester/run"
+	"github.com/gosuri/uiprogress"
+	"github.com/pinterest/bender"
+	"github.com/sirupsen/logrus"
+)
+
+// Replay is the kind of the tests replaying a trace. Unlike the other kinds the
+// agents don't run them.
+const Replay = "replay"
+
+// Part is a part of a scenario with its own requests and share of the load.
+type Part struct {
+	Share            float64
+	RequestGenerator RequestGenerator
+}
+
+// NewScenarioParams combines params created for every part of a scenario into
+// params running all of them at once.
+func NewScenarioParams(parts tester.Targets, params ...*Params) *Params {
+	p := &Params{Targets: parts.Addresses()}
+	testers := make([]tester.Tester, 0, len(params))
+
+	for i, partParams := range params {
+		testers = append(testers, partParams.Tester)
+		p.Parts = append(p.Parts, &Part{Share: parts[i].Weight, RequestGenerator: targeted(i, partParams.RequestGenerator)})
+	}
+
+	p.Tester = &tester.MultiTester{Targets: parts, Testers: testers}
+
+	return p
+}
+
+// targeted binds all the generated requests to the target.
+func targeted(target int, generator RequestGenerator) RequestGenerator {
+	return func(i int) interface{} {
+		request := generator(i)
+		if request == nil {
+			return nil
+		}
+
+		return &tester.TargetedRequest{Target: target, Request: request}
+	}
+}
+
+// load starts the load of a single part, the recorder is closed once it's done.
+type load func(executor bender.RequestExecutor, recorder chan interface{})
+
+// ScenarioRunner is a test runner which runs all the parts of a scenario at
+// once, each of them with its own requests, schedule and share of the load,
+// and merges their events. It can be used both as a throughput and
+// a concurrency runner, the parts run the test of the given kind.
+type ScenarioRunner struct {
+	ThroughputRunner
+	kind     string
+	loads    []load
+	executor bender.RequestExecutor
+	cancel   context.CancelFunc
+	stop     context.CancelFunc
+}
+
+// NewScenarioRunner returns new ScenarioRunner.
+func NewScenarioRunner(params *Params, kind string) *ScenarioRunner {
+	return &ScenarioRunner{
+		ThroughputRunner: ThroughputRunner{
+			runner: runner{
+				Params: params,
+			},
+		},
+		kind: kind,
+	}
+}
+
+// Before prepares the loads of all the parts and warms them up.
+func (r *ScenarioRunner) Before(ctx context.Context, test int, opts interface{}) error {
+	if err := r.runner.Before(test, opts); err != nil {
+		return err
+	}
+
+	o, ok := opts.(*options.Options)
+	if !ok {
+		return tester.ErrInvalidOptions
+	}
+
+	// Every part runs a part of the workers
+	if r.kind == agent.Concurrency && test < len(r.Params.Parts) {
+		return fmt.Errorf("%w: test value must be at least the number of parts, got: %d < %d",
+			errors.ErrInvalidArgument, test, len(r.Params.Parts))
+	}
+
+	if r.kind != Replay {
+		err := r.warmup(ctx, test, o, func(ctx context.Context, executor bender.RequestExecutor,
+			recorder chan interface{}) {
+			merge(r.warmupLoads(ctx, test, o), executor, recorder)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	drain, stop := run.DrainContext(ctx, o)
+
+	executor, err := r.Params.Tester.RequestExecutor(drain, o)
+	if err != nil {
+		stop()
+
+		//nolint:wrapcheck
+		return err
+	}
+
+	r.executor, r.stop = executor, stop
+
+	if r.kind == agent.Concurrency && o.Requests <= 0 {
+		ctx, r.cancel = context.WithTimeout(ctx, o.Duration)
+	} else {
+		ctx, r.cancel = context.WithCancel(ctx)
+	}
+
+	switch r.kind {
+	case agent.Throughput:
+		r.loads = r.throughputLoads(ctx, test, o)
+	case agent.Profile:
+		r.loads = r.profileLoads(ctx, test, o)
+	case agent.Concurrency:
+		r.loads = r.concurrencyLoads(ctx, test, o)
+	case Replay:
+		r.loads = r.replayLoads(ctx, o)
+	}
+
+	if r.kind == agent.Concurrency {
+		r.corrected, r.dropped, r.saturation = nil, nil, nil
+
+		return nil
+	}
+
+	r.correct(o)
+	r.limit(o)
+	r.saturate()
+
+	return nil
+}
+
+// fractions returns the fractions of the load of every part.
+func (r *ScenarioRunner) fractions() []float64 {
+	sum := 0.
+	for _, part := range r.Params.Parts {
+		sum += part.Share
+	}
+
+	fractions := make([]float64, 0, len(r.Params.Parts))
+	for _, part := range r.Params.Parts {
+		fractions = append(fractions, part.Share/sum)
+	}
+
+	return fractions
+}
+
+// split divides n among the parts according to the fractions, the parts get
+// n in total.
+func split(n int, fractions []float64) []int {
+	counts := make([]int, 0, len(fractions))
+	cumulative, assigned := 0., 0
+
+	for _, fraction := range fractions {
+		cumulative += fraction
+		next := int(math.Round(cumulative * float64(n)))
+		counts = append(counts, next-assigned)
+		assigned = next
+	}
+
+	return counts
+}
+
+// part returns a runner generating the requests of the i-th part.
+func (r *ScenarioRunner) part(i int) *ReplayRunner {
+	return NewReplayRunner(&Params{RequestGenerator: r.Params.Parts[i].RequestGenerator})
+}
+
+// warmupLoads returns the loads of the parts for the warm-up, each of them at
+// its share of the starting load.
+func (r *ScenarioRunner) warmupLoads(ctx context.Context, test int, o *options.Options) []load {
+	loads := make([]load, 0, len(r.Params.Parts))
+	start := float64(test)
+
+	if r.kind == agent.Profile {
+		start = o.Profile[0].From
+	}
+
+	fractions := r.fractions()
+	workers := split(test, fractions)
+
+	for i, fraction := range fractions {
+		if r.kind == agent.Concurrency {
+			loads = append(loads, concurrencyLoad(workers[i], r.part(i).generate(ctx, -1, o.BufferSize)))
+
+			continue
+		}
+
+		qps := start * fraction
+		requests := r.part(i).generate(ctx, int(qps*o.Warmup.Seconds()), o.BufferSize)
+		loads = append(loads, throughputLoad(o.Distribution(qps), requests, o))
+	}
+
+	return loads
+}
+
+// throughputLoads returns the loads of the parts sending their shares of the
+// QPS.
+func (r *ScenarioRunner) throughputLoads(ctx context.Context, qps int, o *options.Options) []load {
+	loads := make([]load, 0, len(r.Params.Parts))
+	fractions := r.fractions()
+	count := requests(o, qps)
+	counts := split(count, fractions)
+
+	for i, fraction := range fractions {
+		requests := r.part(i).generate(ctx, counts[i], o.BufferSize)
+		loads = append(loads, throughputLoad(o.Distribution(float64(qps)*fraction), requests, o))
+	}
+
+	r.count(count)
+
+	return loads
+}
+
+// profileLoads returns the loads of the parts following the profile scaled by
+// their shares.
+func (r *ScenarioRunner) profileLoads(ctx context.Context, test int, o *options.Options) []load {
+	loads := make([]load, 0, len(r.Params.Parts))
+	count := 0
+
+	log.Printf("Profile: %s\n", o.Profile)
+
+	for i, fraction := range r.fractions() {
+		part := r.Params.Targets[i]
+		profile := o.Profile.Scale(fraction)
+		intervals := profile.IntervalGenerator(o.Distribution, func(i int, stage *tester.Stage) {
+			logrus.WithFields(logrus.Fields{
+				"test":  test,
+				"part":  part,
+				"stage": i,
+				"qps":   stage.From,
+			}).Infof("Stage: %s", stage)
+		})
+
+		requests := r.part(i).generate(ctx, profile.Requests(), o.BufferSize)
+		loads = append(loads, throughputLoad(intervals, requests, o))
+		count += profile.Requests()
+	}
+
+	r.count(count)
+
+	return loads
+}
+
+// concurrencyLoads returns the loads of the parts running their shares of the
+// workers. The requests are shared as well if the test is bounded by them.
+func (r *ScenarioRunner) concurrencyLoads(ctx context.Context, workers int, o *options.Options) []load {
+	loads := make([]load, 0, len(r.Params.Parts))
+	fractions := r.fractions()
+	shares := split(workers, fractions)
+	counts := split(o.Requests, fractions)
+
+	for i := range r.Params.Parts {
+		count := -1
+		if o.Requests > 0 {
+			count = counts[i]
+		}
+
+		loads = append(loads, concurrencyLoad(shares[i], r.part(i).generate(ctx, count, o.BufferSize)))
+	}
+
+	if o.Requests > 0 {
+		r.count(o.Requests)
+	} else {
+		r.elapse(ctx, o.Duration)
+	}
+
+	return loads
+}
+
+// replayLoads returns the loads of the parts replaying their own traces.
+func (r *ScenarioRunner) replayLoads(ctx context.Context, o *options.Options) []load {
+	loads := make([]load, 0, len(r.Params.Parts))
+
+	log.Printf("Replaying at %gx speed for up to %s\n", o.Speed, o.Duration)
+
+	for i := range r.Params.Parts {
+		trace := tester.NewTrace(o.Speed)
+		requests := r.part(i).replay(ctx, trace, o)
+		loads = append(loads, throughputLoad(trace.IntervalGenerator(), requests, o))
+	}
+
+	r.elapse(ctx, time.Duration(float64(o.Duration)/o.Speed))
+
+	return loads
+}
+
+// count adds a progress bar measuring the completed requests.
+func (r *ScenarioRunner) count(count int) {
+	r.progress, r.bar = recorders.NewLoadTestProgress(count)
+	r.progress.Start()
+	r.recorders = append(r.recorders, recorders.NewProgressBarRecorder(r.bar))
+}
+
+// elapse adds a progress bar measuring the time passed until the context is
+// done.
+func (r *ScenarioRunner) elapse(ctx context.Context, d time.Duration) {
+	const scale = 10
+	count := int(d/time.Second) * scale
+
+	r.progress, r.bar = recorders.NewLoadTestProgress(count)
+	r.progress.Start()
+
+	go func(bar *uiprogress.Bar) {
+		ticker := time.NewTicker(time.Second / scale)
+		defer ticker.Stop()
+
+		for i := 0; i < count; i++ {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				bar.Incr()
+			}
+		}
+	}(r.bar)
+}
+
+// throughputLoad returns a load sending the requests with the intervals.
+func throughputLoad(intervals bender.IntervalGenerator, requests chan interface{}, o *options.Options) load {
+	return func(executor bender.RequestExecutor, recorder chan interface{}) {
+		run.StartThroughput(intervals, requests, executor, recorder, o)
+	}
+}
+
+// concurrencyLoad returns a load sending the requests with the workers.
+func concurrencyLoad(workers int, requests chan interface{}) load {
+	return func(executor bender.RequestExecutor, recorder chan interface{}) {
+		workerSem := bender.NewWorkerSemaphore()
+
+		go func() { workerSem.Signal(workers) }()
+
+		bender.LoadTestConcurrency(workerSem, requests, executor, recorder)
+	}
+}
+
+// merge starts all the loads at once and forwards their events to the
+// recorder as events of a single test. The recorder is closed once all the
+// loads are done.
+func merge(loads []load, executor bender.RequestExecutor, recorder chan interface{}) {
+	go func() {
+		start := time.Now().UnixNano()
+		recorder <- &bender.StartEvent{Start: start}
+
+		var wg sync.WaitGroup
+
+		for _, load := range loads {
+			events := make(chan interface{}, cap(recorder))
+			load(executor, events)
+
+			wg.Add(1)
+
+			go func(events chan interface{}) {
+				defer wg.Done()
+
+				for msg := range events {
+					switch msg.(type) {
+					case *bender.StartEvent, *bender.EndEvent:
+						continue
+					}
+
+					recorder <- msg
+				}
+			}(events)
+		}
+
+		wg.Wait()
+		recorder <- &bender.EndEvent{Start: start, End: time.Now().UnixNano()}
+		close(recorder)
+	}()
+}
+
+// Load starts the loads of all the parts at once and merges their events into
+// the recorder.
+func (r *ScenarioRunner) Load(_ context.Context, recorder chan interface{}) {
+	merge(r.loads, r.executor, recorder)
+}
+
+// After cleans up after the test.
+func (r *ScenarioRunner) After(test int, opts interface{}) {
+	r.cancel()
+	r.stop()
+
+	if r.kind == agent.Concurrency {
+		r.progress.Stop()
+		r.runner.After(test, opts)
+	} else {
+		r.ThroughputRunner.After(test, opts)
+	}
+}
+
+// WorkerSemaphore is not used, every part manages its workers on its own.
+func (r *ScenarioRunner) WorkerSemaphore() *bender.WorkerSemaphore {
+	return nil
+}
@@ -0,0 +1,138 @@
+/*
+Copyright (c) Facebook, Inc. and its affiliates.
+All rights reserved.
+
+This source code is licensed under the BSD-style license found in the
+LICENSE file in the root directory of this source tree.
+*/
+
+package runner_test
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/facebookincubator/fbender/cmd/core/agent"
+	"github.com/facebookincubator/fbender/cmd/core/errors"
+	"github.com/facebookincubator/fbender/cmd/core/options"
+	"github.com/facebookincubator/fbender/cmd/core/runner"
+	"github.com/facebookincubator/fbender/log"
+	"github.com/facebookincubator/fbender/recorders"
+	"github.com/facebookincubator/fbender/tester"
+	"github.com/facebookincubator/fbender/tester/run"
+	"github.com/pinterest/bender"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// partTester counts the requests it executes, each of them takes the delay.
+type partTester struct {
+	mutex    sync.Mutex
+	delay    time.Duration
+	requests int
+	done     time.Time
+}
+
+func (t *partTester) Before(_ interface{}) error {
+	return nil
+}
+
+func (t *partTester) After(_ interface{}) {}
+
+func (t *partTester) BeforeEach(_ interface{}) error {
+	return nil
+}
+
+func (t *partTester) AfterEach(_ interface{}) {}
+
+func (t *partTester) RequestExecutor(_ context.Context, _ interface{}) (bender.RequestExecutor, error) {
+	return func(_ int64, request interface{}) (interface{}, error) {
+		time.Sleep(t.delay)
+
+		t.mutex.Lock()
+		t.requests++
+		t.done = time.Now()
+		t.mutex.Unlock()
+
+		return request, nil
+	}, nil
+}
+
+func scenarioParams(testers ...*partTester) *runner.Params {
+	parts := tester.Targets{{Address: "fast", Weight: 3}, {Address: "slow", Weight: 1}}
+	params := make([]*runner.Params, 0, len(testers))
+
+	for _, t := range testers {
+		params = append(params, &runner.Params{
+			Tester:           t,
+			RequestGenerator: func(i int) interface{} { return i },
+		})
+	}
+
+	return runner.NewScenarioParams(parts[:len(testers)], params...)
+}
+
+func scenarioOptions(statistics *recorders.Statistics) *options.Options {
+	o := options.NewOptions()
+	o.Requests = 40
+	o.BufferSize = 100
+	o.Timeout = time.Second
+	o.Unit = time.Millisecond
+	o.Distribution = bender.UniformIntervalGenerator
+	o.AddRecorder(recorders.NewStatisticsRecorder(statistics))
+
+	return o
+}
+
+func TestScenarioRunner__Throughput(t *testing.T) {
+	stdout := log.Stdout
+	output := new(bytes.Buffer)
+	log.Stdout = output
+
+	defer func() { log.Stdout = stdout }()
+
+	fast, slow := new(partTester), &partTester{delay: 10 * time.Millisecond}
+	statistics := new(recorders.Statistics)
+
+	r := runner.NewScenarioRunner(scenarioParams(fast, slow), agent.Throughput)
+	err := run.LoadTestThroughputFixed(context.Background(), r, scenarioOptions(statistics), 400)
+	require.NoError(t, err)
+
+	// Every part sends its share of the requests and the statistics combine them
+	assert.Equal(t, 30, fast.requests)
+	assert.Equal(t, 10, slow.requests)
+	assert.Equal(t, int64(40), statistics.Requests)
+	assert.Equal(t, int64(0), statistics.Errors)
+
+	assert.Contains(t, output.String(), "Total requests: 40\n")
+	assert.Regexp(t, `fast\s+30\s+0\s+0.00`, output.String())
+	assert.Regexp(t, `slow\s+10\s+0\s+0.00`, output.String())
+}
+
+func TestScenarioRunner__Concurrency(t *testing.T) {
+	stdout := log.Stdout
+	log.Stdout = new(bytes.Buffer)
+
+	defer func() { log.Stdout = stdout }()
+
+	fast, slow := new(partTester), &partTester{delay: 20 * time.Millisecond}
+	statistics := new(recorders.Statistics)
+
+	r := runner.NewScenarioRunner(scenarioParams(fast, slow), agent.Concurrency)
+	err := run.LoadTestConcurrencyFixed(context.Background(), r, scenarioOptions(statistics), 4)
+	require.NoError(t, err)
+
+	assert.Equal(t, 30, fast.requests)
+	assert.Equal(t, 10, slow.requests)
+	assert.Equal(t, int64(40), statistics.Requests)
+
+	// The slow part runs with its own worker, so it doesn't stall the other one
+	assert.True(t, fast.done.Before(slow.done), "fast: %s, slow: %s", fast.done, slow.done)
+
+	// Every part needs a worker
+	err = run.LoadTestConcurrencyFixed(context.Background(), r, scenarioOptions(statistics), 1)
+	assert.ErrorIs(t, err, errors.ErrInvalidArgument)
+}
@@ -0,0 +1,202 @@
+/*
+Copyright (c) Facebook, Inc. and its affiliates.
+All rights reserved.
+
+This source code is licensed under the BSD-style license found in the
+LICENSE file in the root directory of this source tree.
+*/
+
+package runner
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/facebookincubator/fbender/cmd/core/options"
+	"github.com/facebookincubator/fbender/log"
+	"github.com/facebookincubator/fbender/recorders"
+	"github.com/mattn/go-isatty"
+	"github.com/pinterest/bender/hist"
+)
+
+// Drift thresholds. The latency drifts when its trend changes by more than
+// latencyDrift of the initial value and at least by one unit, the errors drift
+// when the trend of the errors percentage changes by more than errorsDrift
+// points. At least minDriftSnapshots snapshots are needed to detect a drift.
+const (
+	latencyDrift      = 0.2
+	errorsDrift       = 1.
+	minDriftSnapshots = 3
+)
+
+// soak sets up periodic interim reports if a report interval is set.
+func (r *runner) soak(o *options.Options) {
+	if o.ReportInterval <= 0 {
+		return
+	}
+
+	histogram := func() *hist.Histogram {
+		if o.NoStatistics {
+			return nil
+		}
+
+		return hist.NewHistogram(2*int(o.Timeout/o.Unit), int(o.Unit))
+	}
+
+	r.snapshots = []*recorders.Snapshot{}
+	r.recorders = append(r.recorders, recorders.NewSnapshotRecorder(o.ReportInterval, histogram,
+		func(snapshot *recorders.Snapshot) {
+			r.snapshots = append(r.snapshots, snapshot)
+			log.Fprintf(r.interimOutput(), "Interim report (%s): %s", r.elapsed(snapshot), snapshotString(snapshot))
+		}))
+}
+
+// interimOutput returns the output of the interim reports. The progress bar
+// would overwrite the reports printed to the same terminal, so they're printed
+// above it then.
+func (r *runner) interimOutput() io.Writer {
+	if r.progress != nil && log.Stdout == os.Stdout && isatty.IsTerminal(os.Stdout.Fd()) {
+		return r.progress.Bypass()
+	}
+
+	return log.Stdout
+}
+
+// elapsed returns the time since the start of the test until the end of the
+// snapshot.
+func (r *runner) elapsed(snapshot *recorders.Snapshot) time.Duration {
+	return time.Duration(snapshot.End - r.snapshots[0].Start).Round(time.Second)
+}
+
+// snapshotString returns a summary of the snapshot followed by its histogram.
+func snapshotString(snapshot *recorders.Snapshot) string {
+	s := fmt.Sprintf("requests: %d, errors: %.2f%%", snapshot.Requests, snapshot.ErrorsPercent())
+	if snapshot.Dropped > 0 {
+		s += fmt.Sprintf(", dropped: %d", snapshot.Dropped)
+	}
+
+	s += "\n"
+
+	if snapshot.Histogram != nil && snapshot.Requests > snapshot.Dropped {
+		s += snapshot.Histogram.String()
+	}
+
+	return s
+}
+
+// soakString returns a table with the time series of the snapshots followed
+// by the detected drifts.
+func (r *runner) soakString(o *options.Options) string {
+	buf := new(bytes.Buffer)
+	w := tabwriter.NewWriter(buf, 0, 0, 2, ' ', tabwriter.AlignRight)
+
+	fmt.Fprintf(w, "Elapsed\tRequests\tErrors\tPercent errors\t")
+
+	if !o.NoStatistics {
+		fmt.Fprintf(w, "Average (%s)\tMedian\t99th\tMax\t", o.Unit)
+	}
+
+	fmt.Fprintln(w)
+
+	for _, snapshot := range r.snapshots {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%.2f\t", r.elapsed(snapshot), snapshot.Requests, snapshot.Errors,
+			snapshot.ErrorsPercent())
+
+		if snapshot.Histogram != nil && snapshot.Requests > snapshot.Errors {
+			ps := snapshot.Histogram.Percentiles(0.5, 0.99, 1.0)
+			fmt.Fprintf(w, "%f\t%d\t%d\t%d\t", snapshot.Histogram.Average(), ps[0], ps[1], ps[2])
+		}
+
+		fmt.Fprintln(w)
+	}
+
+	if err := w.Flush(); err != nil {
+		return err.Error()
+	}
+
+	drifts := r.drifts(o)
+	if len(drifts) == 0 {
+		drifts = []string{"No drift detected"}
+	}
+
+	for _, drift := range drifts {
+		fmt.Fprintf(buf, "%s\n", drift)
+	}
+
+	return buf.String()
+}
+
+// drifts returns descriptions of the latency and errors trends which changed
+// significantly during the test.
+func (r *runner) drifts(o *options.Options) []string {
+	snapshots := completeSnapshots(r.snapshots, o.ReportInterval)
+	if len(snapshots) < minDriftSnapshots {
+		return []string{fmt.Sprintf("Not enough snapshots to detect a drift, want: %d, got: %d",
+			minDriftSnapshots, len(snapshots))}
+	}
+
+	drifts := []string{}
+
+	errors := make([]float64, 0, len(snapshots))
+	for _, snapshot := range snapshots {
+		errors = append(errors, snapshot.ErrorsPercent())
+	}
+
+	first, last := recorders.Trend(errors)
+	first, last = math.Max(first, 0), math.Max(last, 0)
+
+	if math.Abs(last-first) > errorsDrift {
+		drifts = append(drifts, fmt.Sprintf("Errors drift: %s from %.2f%% to %.2f%%", direction(first, last), first, last))
+	}
+
+	for _, percentile := range []struct {
+		name     string
+		fraction float64
+	}{{"Median", 0.5}, {"99th", 0.99}} {
+		latencies := []float64{}
+
+		for _, snapshot := range snapshots {
+			if snapshot.Histogram != nil && snapshot.Requests > snapshot.Errors {
+				latencies = append(latencies, float64(snapshot.Histogram.Percentiles(percentile.fraction)[0]))
+			}
+		}
+
+		if len(latencies) < minDriftSnapshots {
+			continue
+		}
+
+		first, last := recorders.Trend(latencies)
+		first, last = math.Max(first, 0), math.Max(last, 0)
+
+		if change := math.Abs(last - first); change > latencyDrift*first && change >= 1 {
+			drifts = append(drifts, fmt.Sprintf("Latency drift: %s latency %s from %.2f to %.2f (%s)",
+				percentile.name, direction(first, last), first, last, o.Unit))
+		}
+	}
+
+	return drifts
+}
+
+// completeSnapshots skips the last snapshot if it covers less than half of the
+// interval, as it would skew the trends.
+func completeSnapshots(snapshots []*recorders.Snapshot, interval time.Duration) []*recorders.Snapshot {
+	if n := len(snapshots); n > 0 && time.Duration(snapshots[n-1].End-snapshots[n-1].Start) < interval/2 {
+		return snapshots[:n-1]
+	}
+
+	return snapshots
+}
+
+// direction describes the change from the first to the last value.
+func direction(first, last float64) string {
+	if last > first {
+		return "increased"
+	}
+
+	return "decreased"
+}
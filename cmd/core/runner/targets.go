@@ -0,0 +1,108 @@
+/*
+Copyright (c) Facebook, Inc. and its affiliates.
+All rights reserved.
+
+This source code is licensed under the BSD-style license found in the
+LICENSE file in the root directory of this source tree.
+*/
+
+package runner
+
+import (
+	"bytes"
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/facebookincubator/fbender/cmd/core/options"
+	"github.com/facebookincubator/fbender/recorders"
+	"github.com/facebookincubator/fbender/tester"
+	"github.com/pinterest/bender/hist"
+)
+
+// NewMultiTargetParams combines params created for every target into params
+// spreading the load among all of them. Selector decides which target the
+// i-th request is sent to.
+func NewMultiTargetParams(targets tester.Targets, selector tester.Selector, params ...*Params) *Params {
+	testers := make([]tester.Tester, 0, len(params))
+	for _, p := range params {
+		testers = append(testers, p.Tester)
+	}
+
+	return &Params{
+		Tester: &tester.MultiTester{Targets: targets, Testers: testers},
+		RequestGenerator: func(i int) interface{} {
+			target := selector(i)
+
+			request := params[target].RequestGenerator(i)
+			if request == nil {
+				return nil
+			}
+
+			return &tester.TargetedRequest{Target: target, Request: request}
+		},
+		Targets: targets.Addresses(),
+	}
+}
+
+// targets sets up a per target breakdown if the load is spread among multiple
+// targets.
+func (r *runner) targets(o *options.Options) {
+	if len(r.Params.Targets) < 2 {
+		return
+	}
+
+	r.breakdown = make(map[string]*recorders.TargetStatistics, len(r.Params.Targets))
+
+	for _, target := range r.Params.Targets {
+		statistics := new(recorders.TargetStatistics)
+		if !o.NoStatistics {
+			statistics.Histogram = hist.NewHistogram(2*int(o.Timeout/o.Unit), int(o.Unit))
+		}
+
+		r.breakdown[target] = statistics
+	}
+
+	r.recorders = append(r.recorders, recorders.NewTargetsRecorder(r.breakdown))
+}
+
+// breakdownString returns a table with the per target statistics.
+func (r *runner) breakdownString(o *options.Options) string {
+	buf := new(bytes.Buffer)
+	w := tabwriter.NewWriter(buf, 0, 0, 2, ' ', tabwriter.AlignRight)
+
+	fmt.Fprintf(w, "Target\tRequests\tErrors\tPercent errors\t")
+
+	if !o.NoStatistics {
+		fmt.Fprintf(w, "Average (%s)\tMedian\t99th\tMax\t", o.Unit)
+	}
+
+	fmt.Fprintln(w)
+
+	for _, target := range r.Params.Targets {
+		statistics := r.breakdown[target]
+		percent := 0.
+		if statistics.Requests > 0 {
+			percent = float64(statistics.Errors) / float64(statistics.Requests) * 100
+		}
+
+		fmt.Fprintf(w, "%s\t%d\t%d\t%.2f\t", target, statistics.Requests, statistics.Errors, percent)
+
+		if statistics.Histogram != nil {
+			average := 0.
+			if statistics.Requests > 0 {
+				average = statistics.Histogram.Average()
+			}
+
+			ps := statistics.Histogram.Percentiles(0.5, 0.99, 1.0)
+			fmt.Fprintf(w, "%f\t%d\t%d\t%d\t", average, ps[0], ps[1], ps[2])
+		}
+
+		fmt.Fprintln(w)
+	}
+
+	if err := w.Flush(); err != nil {
+		return err.Error()
+	}
+
+	return buf.String()
+}
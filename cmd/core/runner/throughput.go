@@ -9,18 +9,27 @@ LICENSE file in the root directory of this source tree.
 package runner
 
 import (
+	"context"
+	"fmt"
+	"math"
 	"time"
 
 	"github.com/facebookincubator/fbender/cmd/core/options"
+	"github.com/facebookincubator/fbender/log"
 	"github.com/facebookincubator/fbender/recorders"
 	"github.com/facebookincubator/fbender/tester"
+	"github.com/facebookincubator/fbender/tester/run"
 	"github.com/pinterest/bender"
+	"github.com/pinterest/bender/hist"
 )
 
 // ThroughputRunner is a test runner for load test throughput commands.
 type ThroughputRunner struct {
 	runner
-	intervals bender.IntervalGenerator
+	intervals  bender.IntervalGenerator
+	corrected  *hist.Histogram
+	dropped    *recorders.Statistics
+	saturation *recorders.Saturation
 }
 
 // NewThroughputRunner returns new ThroughputRunner.
@@ -33,7 +42,7 @@ func NewThroughputRunner(params *Params) *ThroughputRunner {
 }
 
 // Before prepares requests, recorders and interval generator.
-func (r *ThroughputRunner) Before(qps tester.QPS, opts interface{}) error {
+func (r *ThroughputRunner) Before(ctx context.Context, qps tester.QPS, opts interface{}) error {
 	if err := r.runner.Before(qps, opts); err != nil {
 		return err
 	}
@@ -43,29 +52,136 @@ func (r *ThroughputRunner) Before(qps tester.QPS, opts interface{}) error {
 		return tester.ErrInvalidOptions
 	}
 
-	count := int(float64(qps) * float64(o.Duration/time.Second))
-	r.intervals = o.Distribution(float64(qps))
-
-	r.requests = make(chan interface{}, o.BufferSize)
+	err := r.warmup(ctx, qps, o, func(ctx context.Context, executor bender.RequestExecutor,
+		recorder chan interface{}) {
+		count := int(float64(qps) * o.Warmup.Seconds())
+		requests := r.generate(ctx, count, o.BufferSize)
+		run.StartThroughput(o.Distribution(float64(qps)), requests, executor, recorder, o)
+	})
+	if err != nil {
+		return err
+	}
 
-	go func() {
-		for i := 0; i < count; i++ {
-			r.requests <- r.Params.RequestGenerator(i)
-		}
-		close(r.requests)
-	}()
+	count := requests(o, qps)
+	r.intervals = o.Distribution(float64(qps))
+	r.requests = r.generate(ctx, count, o.BufferSize)
 
 	r.progress, r.bar = recorders.NewLoadTestProgress(count)
 	r.progress.Start()
 	r.recorders = append(r.recorders, recorders.NewProgressBarRecorder(r.bar))
+	r.correct(o)
+	r.limit(o)
+	r.saturate()
+
+	return nil
+}
+
+// requests returns the number of requests sent in a throughput test, either
+// set explicitly or resulting from the QPS and the test duration.
+func requests(o *options.Options, qps int) int {
+	if o.Requests > 0 {
+		return o.Requests
+	}
+
+	return int(float64(qps) * float64(o.Duration/time.Second))
+}
+
+// correct adds a histogram of latencies corrected for the coordinated omission.
+func (r *ThroughputRunner) correct(o *options.Options) {
+	r.corrected = nil
+	if r.histogram != nil {
+		r.corrected = hist.NewHistogram(2*int(o.Timeout/o.Unit), int(o.Unit))
+		r.recorders = append(r.recorders, recorders.NewCorrectedHistogramRecorder(r.corrected))
+	}
+}
+
+// limit adds statistics of requests dropped over the in-flight requests limit.
+func (r *ThroughputRunner) limit(o *options.Options) {
+	r.dropped = nil
+	if o.MaxInflight > 0 && o.DropOverflow {
+		r.dropped = new(recorders.Statistics)
+		r.recorders = append(r.recorders, recorders.NewStatisticsRecorder(r.dropped))
+	}
+}
+
+// saturationTolerance is the fraction of the scheduled QPS by which the achieved
+// QPS may fall short before the load generator is considered saturated.
+const saturationTolerance = 0.05
+
+// saturate adds statistics of how well the load generator kept up with the
+// schedule.
+func (r *ThroughputRunner) saturate() {
+	r.saturation = new(recorders.Saturation)
+	r.recorders = append(r.recorders, recorders.NewSaturationRecorder(r.saturation))
+}
+
+// saturated returns whether the load generator couldn't keep up with the
+// schedule. Requests waiting for the in-flight limit are late because of the
+// target, so the generator isn't considered saturated then.
+func (r *ThroughputRunner) saturated(o *options.Options) bool {
+	blocking := o.MaxInflight > 0 && !o.DropOverflow
+
+	return r.saturation != nil && !blocking && r.saturation.Saturated(saturationTolerance)
+}
+
+// Saturation returns the statistics of how well the load generator kept up
+// with the schedule of the last test.
+func (r *ThroughputRunner) Saturation() *recorders.Saturation {
+	return r.saturation
+}
+
+// saturationString returns the achieved and scheduled QPS with the lag.
+func (r *ThroughputRunner) saturationString() string {
+	s := r.saturation
+
+	return fmt.Sprintf("Achieved QPS: %.2f of %.2f scheduled (%.2f%%), scheduling lag: average %s, max %s\n",
+		s.AchievedQPS(), s.ScheduledQPS(), 100*s.AchievedQPS()/math.Max(s.ScheduledQPS(), 1e-9),
+		s.AverageLag(), time.Duration(s.MaxLag))
+}
+
+// Verify fails the test if the load generator couldn't keep up with the
+// schedule and the options say so.
+func (r *ThroughputRunner) Verify(test int, opts interface{}) error {
+	o, ok := opts.(*options.Options)
+	if !ok {
+		return tester.ErrInvalidOptions
+	}
+
+	if o.FailSaturated && r.saturated(o) {
+		return fmt.Errorf("%w, test %d achieved %.2f of %.2f scheduled QPS", tester.ErrSaturated, test,
+			r.saturation.AchievedQPS(), r.saturation.ScheduledQPS())
+	}
 
 	return nil
 }
 
 // After cleans up after the test.
-func (r *ThroughputRunner) After(test int, options interface{}) {
+func (r *ThroughputRunner) After(test int, opts interface{}) {
 	r.progress.Stop()
-	r.runner.After(test, options)
+
+	if r.corrected != nil {
+		log.Printf("Uncorrected latency:\n")
+	}
+
+	r.runner.After(test, opts)
+
+	if r.corrected != nil {
+		log.Printf("Corrected latency (measured from the scheduled send time):\n%s", r.corrected.String())
+	}
+
+	if r.dropped != nil {
+		log.Printf("Dropped: %d/%d requests over the in-flight limit\n", r.dropped.Dropped, r.dropped.Requests)
+	}
+
+	if r.saturation == nil || r.saturation.Sent == 0 {
+		return
+	}
+
+	log.Printf("%s", r.saturationString())
+
+	if o, ok := opts.(*options.Options); ok && r.saturated(o) {
+		log.Errorf("Warning: The load generator couldn't keep up with the schedule, the results reflect a lower load\n")
+	}
 }
 
 // Intervals returns the interval generator.
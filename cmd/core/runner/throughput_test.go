@@ -0,0 +1,149 @@
+/*
+Copyright (c) Facebook, Inc. and its affiliates.
+All rights reserved.
+
+This source code is licensed under the BSD-style license found in the
+LICENSE file in the root directory of this source tree.
+*/
+
+package runner_test
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/facebookincubator/fbender/cmd/core/options"
+	"github.com/facebookincubator/fbender/cmd/core/runner"
+	"github.com/facebookincubator/fbender/log"
+	"github.com/facebookincubator/fbender/recorders"
+	"github.com/facebookincubator/fbender/tester"
+	"github.com/facebookincubator/fbender/tester/run"
+	"github.com/pinterest/bender"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// warmupTester fails all the requests of its first executor (the warm-up) and
+// records when the last of them ended.
+type warmupTester struct {
+	mutex     sync.Mutex
+	executors int
+	warmedUp  time.Time
+}
+
+func (t *warmupTester) Before(_ interface{}) error {
+	return nil
+}
+
+func (t *warmupTester) After(_ interface{}) {}
+
+func (t *warmupTester) BeforeEach(_ interface{}) error {
+	return nil
+}
+
+func (t *warmupTester) AfterEach(_ interface{}) {}
+
+func (t *warmupTester) RequestExecutor(_ context.Context, _ interface{}) (bender.RequestExecutor, error) {
+	t.mutex.Lock()
+	t.executors++
+	warmup := t.executors == 1
+	t.mutex.Unlock()
+
+	return func(_ int64, request interface{}) (interface{}, error) {
+		if !warmup {
+			return request, nil
+		}
+
+		t.mutex.Lock()
+		t.warmedUp = time.Now()
+		t.mutex.Unlock()
+
+		return nil, assert.AnError
+	}, nil
+}
+
+// windowMetric records the periods it's fetched for.
+type windowMetric struct {
+	starts []time.Time
+}
+
+func (m *windowMetric) Setup(_ interface{}) error {
+	return nil
+}
+
+func (m *windowMetric) Fetch(start time.Time, duration time.Duration) ([]tester.DataPoint, error) {
+	m.starts = append(m.starts, start)
+
+	return []tester.DataPoint{{Time: start.Add(duration), Value: 0}}, nil
+}
+
+func (m *windowMetric) Name() string {
+	return "window"
+}
+
+// singleTest stops the tests after the first one.
+type singleTest struct{}
+
+func (singleTest) OnSuccess(_ int) int {
+	return 0
+}
+
+func (singleTest) OnFail(_ int) int {
+	return 0
+}
+
+func (singleTest) String() string {
+	return "single"
+}
+
+func TestThroughputRunner__Warmup(t *testing.T) {
+	stdout := log.Stdout
+	output := new(bytes.Buffer)
+	log.Stdout = output
+
+	defer func() { log.Stdout = stdout }()
+
+	fake := new(warmupTester)
+	params := &runner.Params{
+		Tester:           fake,
+		RequestGenerator: func(i int) interface{} { return i },
+	}
+
+	statistics := new(recorders.Statistics)
+	metric := new(windowMetric)
+
+	o := options.NewOptions()
+	o.Warmup = 200 * time.Millisecond
+	o.Requests = 20
+	o.BufferSize = 100
+	o.Timeout = time.Second
+	o.Unit = time.Millisecond
+	o.Distribution = bender.UniformIntervalGenerator
+	o.AddRecorder(recorders.NewStatisticsRecorder(statistics))
+
+	maximum, err := tester.ParseAggregator("MAX")
+	require.NoError(t, err)
+	less, err := tester.ParseComparator("<")
+	require.NoError(t, err)
+
+	constraint := &tester.Constraint{Metric: metric, Aggregator: maximum, Comparator: less, Threshold: 1}
+
+	err = run.LoadTestThroughputConstraints(context.Background(), runner.NewThroughputRunner(params),
+		o, 200, singleTest{}, constraint)
+	require.NoError(t, err)
+
+	// The warm-up requests (all failed) are neither in the statistics nor in
+	// the histogram
+	assert.Equal(t, int64(20), statistics.Requests)
+	assert.Equal(t, int64(0), statistics.Errors)
+	assert.Contains(t, output.String(), "Total requests: 20\n")
+	assert.Contains(t, output.String(), "Errors: 0\n")
+
+	// The constraint window starts after the warm-up
+	require.Len(t, metric.starts, 1)
+	assert.False(t, fake.warmedUp.IsZero())
+	assert.True(t, metric.starts[0].After(fake.warmedUp), "window: %s, warm-up: %s", metric.starts[0], fake.warmedUp)
+}
@@ -0,0 +1,96 @@
+/*
+Copyright (c) Facebook, Inc. and its affiliates.
+All rights reserved.
+
+This source code is licensed under the BSD-style license found in the
+LICENSE file in the root directory of this source tree.
+*/
+
+package runner
+
+import (
+	"bytes"
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/facebookincubator/fbender/cmd/core/options"
+	"github.com/facebookincubator/fbender/log"
+	"github.com/facebookincubator/fbender/recorders"
+)
+
+// trialPercentiles are the latency percentiles summarized over the trials.
+//nolint:gochecknoglobals
+var trialPercentiles = []struct {
+	name     string
+	fraction float64
+}{
+	{"Median", 0.5},
+	{"90th", 0.9},
+	{"99th", 0.99},
+	{"99.9th", 0.999},
+}
+
+// repeat sets up gathering the statistics of a trial if tests are repeated.
+func (r *runner) repeat(o *options.Options) {
+	if o.Repeat < 2 {
+		return
+	}
+
+	r.statistics = new(recorders.Statistics)
+	r.recorders = append(r.recorders, recorders.NewStatisticsRecorder(r.statistics))
+}
+
+// trial adds the results of a finished trial.
+func (r *runner) trial(o *options.Options) {
+	if r.statistics == nil {
+		return
+	}
+
+	names, values := []string{}, []float64{}
+
+	if r.statistics.Requests > 0 {
+		names = append(names, "Percent errors")
+		values = append(values, float64(r.statistics.Errors)/float64(r.statistics.Requests)*100)
+	}
+
+	if r.histogram != nil && r.statistics.Requests > r.statistics.Errors {
+		fractions := make([]float64, 0, len(trialPercentiles))
+		for _, percentile := range trialPercentiles {
+			fractions = append(fractions, percentile.fraction)
+		}
+
+		names = append(names, fmt.Sprintf("Average (%s)", o.Unit))
+		values = append(values, r.histogram.Average())
+
+		for i, value := range r.histogram.Percentiles(fractions...) {
+			names = append(names, fmt.Sprintf("%s (%s)", trialPercentiles[i].name, o.Unit))
+			values = append(values, float64(value))
+		}
+	}
+
+	r.trials.Add(names, values)
+}
+
+// Summarize prints the mean, the standard deviation and the 95% confidence
+// interval of the statistics gathered in all the trials of a test.
+func (r *runner) Summarize(test int, opts interface{}) {
+	defer r.trials.Reset()
+
+	buf := new(bytes.Buffer)
+	w := tabwriter.NewWriter(buf, 0, 0, 2, ' ', tabwriter.AlignRight)
+
+	fmt.Fprintf(w, "Statistic\tMean\tStdDev\t95%% CI\t\n")
+
+	for _, summary := range r.trials.Summaries() {
+		fmt.Fprintf(w, "%s\t%.4f\t%.4f\t[%.4f, %.4f]\t\n",
+			summary.Name, summary.Mean, summary.StdDev, summary.Low, summary.High)
+	}
+
+	if err := w.Flush(); err != nil {
+		log.Errorf("Error summarizing trials: %v\n", err)
+
+		return
+	}
+
+	log.Printf("Summary of test %d (%d trials):\n%s", test, r.trials.Count(), buf.String())
+}
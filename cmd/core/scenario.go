@@ -0,0 +1,116 @@
+/*
+Copyright (c) Facebook, Inc. and its affiliates.
+All rights reserved.
+
+This source code is licensed under the BSD-style license found in the
+LICENSE file in the root directory of this source tree.
+*/
+
+package core
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/facebookincubator/fbender/cmd/core/errors"
+	"github.com/facebookincubator/fbender/cmd/core/options"
+	"github.com/facebookincubator/fbender/cmd/core/plan"
+	"github.com/facebookincubator/fbender/cmd/core/runner"
+	"github.com/facebookincubator/fbender/log"
+	"github.com/facebookincubator/fbender/tester"
+	"github.com/spf13/cobra"
+)
+
+// protocols lists the protocols available in the scenarios.
+//nolint:gochecknoglobals
+var protocols = make(map[string]*protocol)
+
+// NewScenarioCommand creates a scenario command with all the test subcommands.
+// The load of every test is shared among the scenario parts, each of them
+// tested with its own protocol.
+func NewScenarioCommand(c *CommandTemplate) *cobra.Command {
+	return registerTestCommand(c, scenarioParams)
+}
+
+// scenarioParams creates params for every part of the scenario and combines
+// them, so every part runs at once with its own share of the load.
+func scenarioParams(cmd *cobra.Command, o *options.Options) (*runner.Params, error) {
+	filename, err := cmd.Flags().GetString("scenario")
+	if err != nil {
+		//nolint:wrapcheck
+		return nil, err
+	}
+
+	scenario, err := readScenario(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	parts := make(tester.Targets, 0, len(scenario.Parts))
+	params := make([]*runner.Params, 0, len(scenario.Parts))
+
+	for _, part := range scenario.Parts {
+		partOptions := *o
+		partOptions.Target = part.Target
+
+		if len(part.Input) > 0 {
+			partOptions.Input = part.Input
+		} else if len(scenario.Parts) > 1 {
+			// The standard input can be read only once
+			return nil, fmt.Errorf("%w: part %q input is required", errors.ErrInvalidArgument, part.Name)
+		}
+
+		partParams, err := protocolParams(part, &partOptions)
+		if err != nil {
+			return nil, err
+		}
+
+		parts = append(parts, &tester.Target{Address: part.Name, Weight: part.Share})
+		params = append(params, partParams)
+	}
+
+	return runner.NewScenarioParams(parts, params...), nil
+}
+
+// protocolParams creates params of the part as if its protocol command was run
+// with the part flags.
+func protocolParams(part *plan.Part, o *options.Options) (*runner.Params, error) {
+	p, ok := protocols[part.Protocol]
+	if !ok {
+		return nil, fmt.Errorf("%w: part %q has unknown protocol: %q", errors.ErrInvalidArgument, part.Name, part.Protocol)
+	}
+
+	// Multiple parts may use the same protocol with different flags, so every
+	// part gets its own flags
+	cmd := &cobra.Command{Use: part.Protocol}
+	if p.template.Flags != nil {
+		p.template.Flags(cmd.Flags())
+	}
+
+	if err := cmd.Flags().Parse(part.Args()); err != nil {
+		return nil, fmt.Errorf("%w: part %q: %v", errors.ErrInvalidArgument, part.Name, err)
+	}
+
+	return p.params(cmd, o)
+}
+
+func readScenario(filename string) (*plan.Scenario, error) {
+	if len(filename) == 0 {
+		return nil, fmt.Errorf("%w: scenario file is required", errors.ErrInvalidArgument)
+	}
+
+	file, err := os.Open(filename)
+	if err != nil {
+		//nolint:wrapcheck
+		return nil, err
+	}
+
+	defer func() {
+		if err := file.Close(); err != nil {
+			log.Errorf("Warning: Error closing scenario file: %v\n", err)
+		}
+	}()
+
+	//nolint:wrapcheck
+	return plan.ParseScenario(file)
+}
@@ -0,0 +1,44 @@
+/*
+Copyright (c) Facebook, Inc. and its affiliates.
+All rights reserved.
+
+This source code is licensed under the BSD-style license found in the
+LICENSE file in the root directory of this source tree.
+*/
+
+package core
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/facebookincubator/fbender/log"
+)
+
+// interruptContext returns a context which is canceled when the process
+// receives SIGINT or SIGTERM. Receiving a second signal exits immediately.
+func interruptContext() (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+	signals := make(chan os.Signal, 1)
+
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			signal.Stop(signals)
+
+			return
+		case sig := <-signals:
+			log.Errorf("\nReceived %v, waiting for in-flight requests (repeat to exit immediately)\n", sig)
+			cancel()
+		}
+
+		<-signals
+		os.Exit(1)
+	}()
+
+	return ctx, cancel
+}
@@ -10,6 +10,7 @@ package dhcpv4
 
 import (
 	"github.com/facebookincubator/fbender/cmd/core"
+	"github.com/spf13/pflag"
 )
 
 //nolint:gochecknoglobals
@@ -28,15 +29,15 @@ Input format: "DeviceMAC"
   fbender dhcpv4 {test} fixed -t $TARGET -d 5m 50`,
 	Constraints: `  fbender dhcpv4 {test} constraints -t $TARGET -c "AVG(latency)<10" 20
   fbender dhcpv4 {test} constraints -t $TARGET -g ^10 -c "MAX(errors)<10" 40`,
+	Flags:   protocolFlags,
+	Aliases: []string{"dhcp4"},
 }
 
 // Command is the DHCPv4 subcommand.
 //nolint:gochecknoglobals
 var Command = core.NewTestCommand(template, params)
 
-//nolint:gochecknoinits
-func init() {
+func protocolFlags(flags *pflag.FlagSet) {
 	optionCodes := NewOptionCodeSliceValue()
-	Command.PersistentFlags().VarP(optionCodes, "oro", "r", "dhcpv4 parameter request list")
-	Command.Aliases = []string{"dhcp4"}
+	flags.VarP(optionCodes, "oro", "r", "dhcpv4 parameter request list")
 }
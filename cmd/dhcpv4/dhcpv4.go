@@ -28,7 +28,7 @@ func params(cmd *cobra.Command, o *options.Options) (*runner.Params, error) {
 		return nil, err
 	}
 
-	r, err := input.NewRequestGenerator(o.Input, inputTransformer(optionCodes))
+	r, err := input.NewRequestGenerator(o, inputTransformer(optionCodes))
 	if err != nil {
 		//nolint:wrapcheck
 		return nil, err
@@ -10,6 +10,7 @@ package dhcpv6
 
 import (
 	"github.com/facebookincubator/fbender/cmd/core"
+	"github.com/spf13/pflag"
 )
 
 //nolint:gochecknoglobals
@@ -28,15 +29,15 @@ Input format: "DeviceMAC"
   fbender dhcpv6 {test} fixed -t $TARGET -d 5m 50`,
 	Constraints: `  fbender dhcpv6 {test} constraints -t $TARGET -c "AVG(latency)<10" 20
   fbender dhcpv6 {test} constraints -t $TARGET -g ^10 -c "MAX(errors)<10" 40`,
+	Flags:   protocolFlags,
+	Aliases: []string{"dhcp6"},
 }
 
 // Command is the TFTP subcommand.
 //nolint:gochecknoglobals
 var Command = core.NewTestCommand(template, params)
 
-//nolint:gochecknoinits
-func init() {
+func protocolFlags(flags *pflag.FlagSet) {
 	optionCodes := NewOptionCodeSliceValue()
-	Command.PersistentFlags().VarP(optionCodes, "oro", "r", "dhcpv6 requested options (ORO)")
-	Command.Aliases = []string{"dhcp6"}
+	flags.VarP(optionCodes, "oro", "r", "dhcpv6 requested options (ORO)")
 }
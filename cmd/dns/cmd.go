@@ -10,6 +10,7 @@ package dns
 
 import (
 	"github.com/facebookincubator/fbender/cmd/core"
+	"github.com/spf13/pflag"
 )
 
 //nolint:gochecknoglobals
@@ -33,6 +34,7 @@ Input format: "Domain QType [Rcode]"
   fbender dns {test} fixed -t $TARGET -r -d 5m 50`,
 	Constraints: `  fbender dns {test} constraints -t $TARGET -r -c "AVG(latency)<10" 20
   fbender dns {test} constraints -t $TARGET -g ^10 -c "MAX(errors)<10" 40`,
+	Flags: protocolFlags,
 }
 
 // Command is the DNS subcommand.
@@ -41,15 +43,17 @@ var Command = core.NewTestCommand(template, params)
 
 //nolint:gochecknoinits
 func init() {
-	Command.PersistentFlags().BoolP("randomize", "r", false, "randomize queries with timestamp and a random hex")
 	core.DeferPostInit(postinit)
 }
 
-func postinit() {
-	protocol := NewProtocolValue()
+func protocolFlags(flags *pflag.FlagSet) {
+	flags.BoolP("randomize", "r", false, "randomize queries with timestamp and a random hex")
 
-	Command.PersistentFlags().VarP(protocol, "protocol", "p", "protocol used for DNS queries (udp|tcp)")
+	protocol := NewProtocolValue()
+	flags.VarP(protocol, "protocol", "p", "protocol used for DNS queries (udp|tcp)")
+}
 
+func postinit() {
 	if err := BashCompletionProtocol(Command, Command.PersistentFlags(), "protocol"); err != nil {
 		panic(err)
 	}
@@ -38,7 +38,7 @@ func params(cmd *cobra.Command, o *options.Options) (*runner.Params, error) {
 		return nil, err
 	}
 
-	r, err := input.NewRequestGenerator(o.Input, inputTransformer, getModifiers(randomize)...)
+	r, err := input.NewRequestGenerator(o, inputTransformer, getModifiers(randomize)...)
 	if err != nil {
 		//nolint:wrapcheck
 		return nil, err
@@ -10,6 +10,7 @@ package http
 
 import (
 	"github.com/facebookincubator/fbender/cmd/core"
+	"github.com/spf13/pflag"
 )
 
 //nolint:gochecknoglobals
@@ -28,13 +29,13 @@ Input format: "GET RelativeURL" or "POST RelativeURL FormData"
   fbender http {test} fixed -t $TARGET -s -d 5m 50`,
 	Constraints: `  fbender http {test} constraints -t $TARGET -s -c "AVG(latency)<10" 20
   fbender http {test} constraints -t $TARGET -g ^10 -c "MAX(errors)<10" 40`,
+	Flags: protocolFlags,
 }
 
 // Command is the HTTP subcommand.
 //nolint:gochecknoglobals
 var Command = core.NewTestCommand(template, params)
 
-//nolint:gochecknoinits
-func init() {
-	Command.PersistentFlags().BoolP("ssl", "s", false, "enable ssl (use HTTPS)")
+func protocolFlags(flags *pflag.FlagSet) {
+	flags.BoolP("ssl", "s", false, "enable ssl (use HTTPS)")
 }
@@ -32,7 +32,7 @@ func params(cmd *cobra.Command, o *options.Options) (*runner.Params, error) {
 		return nil, err
 	}
 
-	r, err := input.NewRequestGenerator(o.Input, inputTransformer(ssl, o.Target), requestCreator)
+	r, err := input.NewRequestGenerator(o, inputTransformer, requestCreator(ssl, o.Target))
 	if err != nil {
 		//nolint:wrapcheck
 		return nil, err
@@ -45,60 +45,55 @@ func params(cmd *cobra.Command, o *options.Options) (*runner.Params, error) {
 	return &runner.Params{Tester: t, RequestGenerator: r}, nil
 }
 
-func inputTransformer(ssl bool, target string) input.Transformer {
-	protocol := "http"
-	if ssl {
-		protocol = "https"
+// inputTransformer parses the requests relative to the target, so the same
+// requests can be sent to multiple targets.
+func inputTransformer(input string) (interface{}, error) {
+	i := strings.Index(input, " ")
+	if i < 0 {
+		return nil, fmt.Errorf("%w, want: %s, got: %q", errors.ErrInvalidFormat, formats, input)
 	}
 
-	return func(input string) (interface{}, error) {
-		i := strings.Index(input, " ")
-		if i < 0 {
-			return nil, fmt.Errorf("%w, want: %s, got: %q", errors.ErrInvalidFormat, formats, input)
-		}
-
-		method, data := input[:i], input[i+1:]
-		switch method {
-		case "GET":
-			return parseGetRequest(protocol, target, data)
-		case "POST":
-			return parsePostRequest(protocol, target, data)
-		}
-
-		return nil, fmt.Errorf("%w, want: (GET|POST), got: %q", errors.ErrInvalidFormat, method)
+	method, data := input[:i], input[i+1:]
+	switch method {
+	case "GET":
+		return parseGetRequest(data)
+	case "POST":
+		return parsePostRequest(data)
 	}
+
+	return nil, fmt.Errorf("%w, want: (GET|POST), got: %q", errors.ErrInvalidFormat, method)
 }
 
 type request interface {
-	Create() (*http.Request, error)
+	Create(base string) (*http.Request, error)
 }
 
 type getRequest struct {
-	url string
+	path string
 }
 
-func (r *getRequest) Create() (*http.Request, error) {
+func (r *getRequest) Create(base string) (*http.Request, error) {
 	//nolint:noctx
-	return http.NewRequest("GET", r.url, nil)
+	return http.NewRequest("GET", base+r.path, nil)
 }
 
-func parseGetRequest(protocol, target, data string) (interface{}, error) {
-	rawurl, err := joinURL(protocol, target, data)
+func parseGetRequest(data string) (interface{}, error) {
+	path, err := parsePath(data)
 	if err != nil {
 		return nil, err
 	}
 
-	return &getRequest{url: rawurl}, nil
+	return &getRequest{path: path}, nil
 }
 
 type postRequest struct {
-	url  string
+	path string
 	body string
 }
 
-func (r *postRequest) Create() (*http.Request, error) {
+func (r *postRequest) Create(base string) (*http.Request, error) {
 	//nolint:noctx
-	req, err := http.NewRequest("POST", r.url, strings.NewReader(r.body))
+	req, err := http.NewRequest("POST", base+r.path, strings.NewReader(r.body))
 	if err != nil {
 		//nolint:wrapcheck
 		return nil, err
@@ -110,7 +105,7 @@ func (r *postRequest) Create() (*http.Request, error) {
 	return req, nil
 }
 
-func parsePostRequest(protocol, target, data string) (interface{}, error) {
+func parsePostRequest(data string) (interface{}, error) {
 	i := strings.Index(data, " ")
 	if i < 0 {
 		return nil, fmt.Errorf("%w, want: %s, got: \"POST %s\"", errors.ErrInvalidFormat, formats, data)
@@ -122,30 +117,39 @@ func parsePostRequest(protocol, target, data string) (interface{}, error) {
 		return nil, err
 	}
 
-	rawurl, err := joinURL(protocol, target, data[:i])
+	path, err := parsePath(data[:i])
 	if err != nil {
 		return nil, err
 	}
 
-	return &postRequest{url: rawurl, body: form.Encode()}, nil
+	return &postRequest{path: path, body: form.Encode()}, nil
 }
 
 // NewRequest uses reader interface for message body, which is being used up.
 // Therefore we cannot reuse a once created request and need to invoke
-// NewRequest everytime before sending it.
-func requestCreator(r interface{}) (interface{}, error) {
-	if r, ok := r.(request); ok {
-		return r.Create()
+// NewRequest everytime before sending it. The requests are sent to the target.
+func requestCreator(ssl bool, target string) input.Modifier {
+	protocol := "http"
+	if ssl {
+		protocol = "https"
 	}
 
-	return nil, fmt.Errorf("%w, want: request, got: %T", errors.ErrInvalidType, r)
+	base := fmt.Sprintf("%s://%s", protocol, target)
+
+	return func(r interface{}) (interface{}, error) {
+		if r, ok := r.(request); ok {
+			return r.Create(base)
+		}
+
+		return nil, fmt.Errorf("%w, want: request, got: %T", errors.ErrInvalidType, r)
+	}
 }
 
-func joinURL(protocol, target, path string) (string, error) {
-	path = strings.TrimPrefix(path, "/")
-	rawurl := fmt.Sprintf("%s://%s/%s", protocol, target, path)
-	_, err := url.Parse(rawurl)
+// parsePath validates the relative URL and returns it as an absolute path.
+func parsePath(path string) (string, error) {
+	path = "/" + strings.TrimPrefix(path, "/")
+	_, err := url.Parse(path)
 
 	//nolint:wrapcheck
-	return rawurl, err
+	return path, err
 }
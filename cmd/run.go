@@ -0,0 +1,208 @@
+/*
+Copyright (c) Facebook, Inc. and its affiliates.
+All rights reserved.
+
+This source code is licensed under the BSD-style license found in the
+LICENSE file in the root directory of this source tree.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/facebookincubator/fbender/cmd/core"
+	"github.com/facebookincubator/fbender/cmd/core/errors"
+	"github.com/facebookincubator/fbender/cmd/core/plan"
+	"github.com/facebookincubator/fbender/log"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+//nolint:gochecknoglobals
+var runCmd = &cobra.Command{
+	Use:   "run plan.yaml [test...]",
+	Short: "Runs the tests described in a test plan",
+	Long: `Runs the tests described in a test plan.
+
+A test plan is a YAML file listing named tests. Each of them describes a single
+test command: the protocol, the test (throughput or concurrency) and its type
+(fixed, constraints, profile or replay), the targets, the input, the duration,
+the test values, the constraints and the growth. Any other flags of the test
+command, including the protocol flags, are given in the flags map. The tests
+are run in order, every one of them with a fresh command with the default
+values of the flags. The global flags given to the run command apply to all the
+tests, unless a test overrides them. All the tests are checked with a dry run
+before the first one starts. The plan stops at the first failed or interrupted
+test. Only the named tests are run if any names are given.
+
+tests:
+  - name: baseline
+    protocol: dns
+    targets: [$TARGET]
+    input: queries.txt
+    duration: 5m
+    values: [1000, 2000]
+    flags:
+      protocol: tcp
+      timeout: 2s
+  - name: capacity
+    protocol: dns
+    type: constraints
+    targets: [$TARGET]
+    input: queries.txt
+    values: [1000]
+    constraints: ["AVG(latency) < 20", "MAX(errors) < 5"]
+    growth: "^100"`,
+	Example: `  fbender run plan.yaml
+  fbender run -v error plan.yaml capacity`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		sections, err := readPlan(args[0], args[1:]...)
+		if err != nil {
+			return err
+		}
+
+		global, err := globalArgs(cmd)
+		if err != nil {
+			return err
+		}
+
+		// We want test errors to be logged and not trigger help message
+		for _, section := range sections {
+			if err := checkSection(section, global...); err != nil {
+				log.Errorf("Error: test plan section %q is invalid: %v\n", section.Name, err)
+				os.Exit(1)
+			}
+		}
+
+		for _, section := range sections {
+			log.Printf("Running test plan section: %s\n", section.Name)
+
+			if err := runSection(section, global...); err != nil {
+				log.Errorf("Error: test plan section %q failed: %v\n", section.Name, err)
+				os.Exit(1)
+			}
+		}
+
+		return nil
+	},
+}
+
+// readPlan reads the test plan and selects the sections to run.
+func readPlan(filename string, names ...string) ([]*plan.Section, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		//nolint:wrapcheck
+		return nil, err
+	}
+
+	defer func() {
+		if err := file.Close(); err != nil {
+			log.Errorf("Warning: Error closing test plan file: %v\n", err)
+		}
+	}()
+
+	p, err := plan.Parse(file)
+	if err != nil {
+		//nolint:wrapcheck
+		return nil, err
+	}
+
+	//nolint:wrapcheck
+	return p.Select(names...)
+}
+
+// checkSection runs the section as a dry run, so its flags, arguments and
+// options are checked without running the tests. The output is discarded.
+func checkSection(section *plan.Section, global ...string) error {
+	stdout := log.Stdout
+	log.Stdout = ioutil.Discard
+
+	defer func() { log.Stdout = stdout }()
+
+	return runSection(section, append(append([]string{}, global...), "--dry-run")...)
+}
+
+// runSection runs the section with a fresh command, so every section starts
+// with the default values of all the flags but the global ones. The log file
+// of the section is closed once it's done.
+func runSection(section *plan.Section, global ...string) error {
+	command, err := sectionCommand(section.Protocol)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err := command.PersistentFlags().Set("output", ""); err != nil {
+			log.Errorf("Warning: Error closing the output of test %q: %v\n", section.Name, err)
+		}
+	}()
+
+	command.SetArgs(section.Args(global...))
+
+	//nolint:wrapcheck
+	return command.Execute()
+}
+
+// sectionCommand creates a root command with a fresh test command of the
+// protocol. The logger gets its default level and format back, as all the log
+// flags are at their default values.
+func sectionCommand(protocol string) (*cobra.Command, error) {
+	subcommand := core.CreateTestCommand(protocol)
+	if subcommand == nil {
+		return nil, fmt.Errorf("%w: unknown protocol: %q", errors.ErrInvalidArgument, protocol)
+	}
+
+	defaults := logrus.New()
+	logrus.SetLevel(defaults.Level)
+	logrus.SetFormatter(defaults.Formatter)
+
+	command := &cobra.Command{Use: Command.Use, SilenceErrors: true, SilenceUsage: true}
+	initIOFlags(command)
+	initExecutionFlags(command)
+
+	for _, protocolCommand := range Subcommands {
+		if protocolCommand.Name() == subcommand.Name() {
+			initTargetFlags(subcommand)
+		}
+	}
+
+	command.AddCommand(subcommand)
+
+	return command, nil
+}
+
+// globalArgs returns the global flags set explicitly so they can be forwarded
+// to every section.
+func globalArgs(cmd *cobra.Command) ([]string, error) {
+	var (
+		args []string
+		err  error
+	)
+
+	cmd.InheritedFlags().VisitAll(func(flag *pflag.Flag) {
+		if !flag.Changed {
+			return
+		}
+
+		switch value := flag.Value.(type) {
+		case pflag.SliceValue:
+			for _, v := range value.GetSlice() {
+				args = append(args, fmt.Sprintf("--%s=%s", flag.Name, v))
+			}
+		default:
+			// Every section would truncate the log file of the previous one
+			if flag.Name == "output" {
+				err = fmt.Errorf("%w: set the output of every test in the test plan", errors.ErrInvalidArgument)
+			}
+
+			args = append(args, fmt.Sprintf("--%s=%s", flag.Name, value))
+		}
+	})
+
+	return args, err
+}
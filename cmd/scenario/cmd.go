@@ -0,0 +1,59 @@
+/*
+Copyright (c) Facebook, Inc. and its affiliates.
+All rights reserved.
+
+This source code is licensed under the BSD-style license found in the
+LICENSE file in the root directory of this source tree.
+*/
+
+package scenario
+
+import (
+	"github.com/facebookincubator/fbender/cmd/core"
+	"github.com/spf13/pflag"
+)
+
+//nolint:gochecknoglobals
+var template = &core.CommandTemplate{
+	Name:  "scenario",
+	Short: "Test mixed workloads",
+	Long: `
+The load of every test is shared among the parts of the scenario, all of them
+run at once with their own protocol, target, schedule and share of the load.
+The statistics are reported for every part and combined. Constraints can check
+the metrics of a single part with the metric@part syntax, e.g. AVG(latency@dns).
+
+Scenario format (YAML):
+  parts:
+    - name: dns             # defaults to the protocol
+      protocol: dns
+      target: $DNS_TARGET
+      input: queries.txt    # required if there are multiple parts
+      share: 3              # defaults to 1
+      flags:                # protocol flags
+        protocol: tcp
+    - protocol: http
+      target: $HTTP_TARGET
+      input: urls.txt`,
+	Fixed: `  fbender scenario {test} fixed -s scenario.yaml 100 200
+  fbender scenario {test} fixed -s scenario.yaml -d 5m 50`,
+	Constraints: `  fbender scenario {test} constraints -s scenario.yaml -c "AVG(latency@dns)<10" 20
+  fbender scenario {test} constraints -s scenario.yaml -g ^10 -c "MAX(errors)<10" 40`,
+	Target: "-s scenario.yaml",
+	Flags:  scenarioFlags,
+}
+
+// Command is the scenario subcommand.
+//nolint:gochecknoglobals
+var Command = core.NewScenarioCommand(template)
+
+//nolint:gochecknoinits
+func init() {
+	if err := Command.MarkPersistentFlagFilename("scenario"); err != nil {
+		panic(err)
+	}
+}
+
+func scenarioFlags(flags *pflag.FlagSet) {
+	flags.StringP("scenario", "s", "", "load the scenario from a file")
+}
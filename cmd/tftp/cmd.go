@@ -10,6 +10,7 @@ package tftp
 
 import (
 	"github.com/facebookincubator/fbender/cmd/core"
+	"github.com/spf13/pflag"
 )
 
 //nolint:gochecknoglobals
@@ -30,13 +31,13 @@ Input format: "Filename octet" or "Filename netascii"
   fbender tftp {test} fixed -t $TARGET -d 5m 50`,
 	Constraints: `  fbender tftp {test} constraints -t $TARGET -b 1500 -c "AVG(latency)<10" 20
   fbender tftp {test} constraints -t $TARGET -g ^10 -c "MAX(errors)<10" 40`,
+	Flags: protocolFlags,
 }
 
 // Command is the TFTP subcommand.
 //nolint:gochecknoglobals
 var Command = core.NewTestCommand(template, params)
 
-//nolint:gochecknoinits
-func init() {
-	Command.PersistentFlags().IntP("blocksize", "s", 512, "blocksize option as in RFC2348")
+func protocolFlags(flags *pflag.FlagSet) {
+	flags.IntP("blocksize", "s", 512, "blocksize option as in RFC2348")
 }
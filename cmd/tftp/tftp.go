@@ -32,7 +32,7 @@ func params(cmd *cobra.Command, o *options.Options) (*runner.Params, error) {
 		return nil, err
 	}
 
-	r, err := input.NewRequestGenerator(o.Input, inputTransformer)
+	r, err := input.NewRequestGenerator(o, inputTransformer)
 	if err != nil {
 		//nolint:wrapcheck
 		return nil, err
@@ -22,7 +22,7 @@ import (
 )
 
 func params(cmd *cobra.Command, o *options.Options) (*runner.Params, error) {
-	r, err := input.NewRequestGenerator(o.Input, inputTransformer)
+	r, err := input.NewRequestGenerator(o, inputTransformer)
 	if err != nil {
 		//nolint:wrapcheck
 		return nil, err
@@ -9,7 +9,6 @@ LICENSE file in the root directory of this source tree.
 package flags
 
 import (
-	"encoding/csv"
 	"fmt"
 	"strings"
 
@@ -36,21 +35,63 @@ func NewConstraintSliceValue(parsers ...tester.MetricParser) *ConstraintSliceVal
 	}
 }
 
-func readAsCSV(val string) ([]string, error) {
+// splitConstraints splits the value on the commas separating the constraints.
+// Commas inside parentheses, brackets, braces and quoted strings are part of
+// the constraint, e.g. in a query metric. The constraints may be quoted as
+// a whole, like in a CSV list, the quotes are then removed.
+func splitConstraints(val string) ([]string, error) {
 	if val == "" {
 		return []string{}, nil
 	}
 
-	stringReader := strings.NewReader(val)
-	csvReader := csv.NewReader(stringReader)
+	var (
+		values []string
+		depth  int
+		quoted bool
+		start  int
+	)
+
+	for i := 0; i < len(val); i++ {
+		switch c := val[i]; {
+		case c == '"':
+			quoted = !quoted
+		case quoted:
+		case c == '(' || c == '[' || c == '{':
+			depth++
+		case c == ')' || c == ']' || c == '}':
+			depth--
+		case c == ',' && depth == 0:
+			values = append(values, unquote(val[start:i]))
+			start = i + 1
+		}
+	}
+
+	if quoted || depth != 0 {
+		return nil, fmt.Errorf("%w, unbalanced quotes or brackets: %q", tester.ErrInvalidFormat, val)
+	}
+
+	return append(values, unquote(val[start:])), nil
+}
+
+// unquote removes the quotes surrounding the whole constraint. The quotes
+// inside the constraint (e.g. in a query) are kept.
+func unquote(val string) string {
+	trimmed := strings.TrimSpace(val)
+	if len(trimmed) < 2 || trimmed[0] != '"' || trimmed[len(trimmed)-1] != '"' {
+		return val
+	}
+
+	if inner := trimmed[1 : len(trimmed)-1]; !strings.Contains(inner, `"`) {
+		return inner
+	}
 
-	return csvReader.Read()
+	return val
 }
 
 // Set validates given string given constraints and parses them to constraint
 // structures using metric parsers.
 func (c *ConstraintSliceValue) Set(value string) error {
-	values, err := readAsCSV(value)
+	values, err := splitConstraints(value)
 	if err != nil {
 		return err
 	}
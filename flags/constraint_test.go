@@ -163,6 +163,21 @@ func (s *ConstraintsSliceValueTestSuite) TestSet_ParsersCalledForEveryValue() {
 	s.Assert().ElementsMatch(cs, s.cs[:2])
 }
 
+func (s *ConstraintsSliceValueTestSuite) TestSet_StripsQuotes() {
+	s.parsers.On("ParserA", "metric_0").Return(s.ms[0], nil).Once()
+	s.parsers.On("ParserA", "metric_1").Return(s.ms[1], nil).Once()
+
+	// Run the test, the constraints are quoted like in a CSV list.
+	err := s.value.Set(`"MAX(metric_0)<0", "MAX(metric_1) < 10.0"`)
+	s.Require().NoError(err)
+	s.parsers.AssertExpectations(s.T())
+
+	// Check if constraints were parsed.
+	cs, err := flags.GetConstraintsValue(s.value)
+	s.Require().NoError(err)
+	s.Assert().ElementsMatch(cs, s.cs[:2])
+}
+
 func (s *ConstraintsSliceValueTestSuite) TestSet_ErrorsOnError() {
 	s.parsers.On("ParserA", "metric_0").Return(nil, tester.ErrNotParsed).Once()
 	s.parsers.On("ParserB", "metric_0").Return(nil, assert.AnError).Once()
@@ -200,6 +215,29 @@ func (s *ConstraintsSliceValueTestSuite) TestSet_AppendsOnConsecutiveCalls() {
 	s.Assert().ElementsMatch(cs, s.cs[:3])
 }
 
+func (s *ConstraintsSliceValueTestSuite) TestSet_KeepsNestedCommas() {
+	s.parsers.On("ParserA", `q:sum(rate(x{a="1",b="2"}[1m]))`).Return(s.ms[0], nil).Once()
+	s.parsers.On("ParserA", `q:max("a,b")`).Return(s.ms[1], nil).Once()
+
+	// Run the test.
+	err := s.value.Set(`MAX(q:sum(rate(x{a="1",b="2"}[1m]))) < 0.0, MAX(q:max("a,b")) < 10.0`)
+	s.Require().NoError(err)
+	s.parsers.AssertExpectations(s.T())
+
+	// Check if constraints were parsed.
+	cs, err := flags.GetConstraintsValue(s.value)
+	s.Require().NoError(err)
+	s.Assert().ElementsMatch(cs, s.cs[:2])
+}
+
+func (s *ConstraintsSliceValueTestSuite) TestSet_ErrorsOnUnbalanced() {
+	err := s.value.Set(`MAX(q:x{a="1}) < 0.0`)
+	s.Assert().ErrorIs(err, tester.ErrInvalidFormat)
+
+	err = s.value.Set(`MAX(q:x{a="1") < 0.0, MAX(metric_0) < 0.0`)
+	s.Assert().ErrorIs(err, tester.ErrInvalidFormat)
+}
+
 func (s *ConstraintsSliceValueTestSuite) TestString() {
 	// No constraints.
 	s.Assert().Equal("[]", s.value.String())
@@ -26,15 +26,27 @@ type DistributionGenerator = func(float64) bender.IntervalGenerator
 const (
 	uniformGenerator     = "uniform"
 	exponentialGenerator = "exponential"
+	burstGenerator       = "burst"
+	paretoGenerator      = "pareto"
+	sinusoidalGenerator  = "sinusoidal"
+	intervalsGenerator   = "intervals"
 )
 
+// distributionFactory creates a distribution generator from its parameters.
+type distributionFactory = func(params distributionParams) (DistributionGenerator, error)
+
 //nolint:gochecknoglobals
-var generators = map[string]DistributionGenerator{
-	uniformGenerator:     bender.UniformIntervalGenerator,
-	exponentialGenerator: bender.ExponentialIntervalGenerator,
+var generators = map[string]distributionFactory{
+	uniformGenerator:     noParams(bender.UniformIntervalGenerator),
+	exponentialGenerator: noParams(bender.ExponentialIntervalGenerator),
+	burstGenerator:       burstDistribution,
+	paretoGenerator:      paretoDistribution,
+	sinusoidalGenerator:  sinusoidalDistribution,
+	intervalsGenerator:   intervalsDistribution,
 }
 
-// Distribution represents a interval generator flag value.
+// Distribution represents a interval generator flag value. The distribution
+// parameters follow its name, e.g. "burst:size=50,spread=100ms".
 type Distribution struct {
 	Name      string
 	generator DistributionGenerator
@@ -44,7 +56,7 @@ type Distribution struct {
 func NewDefaultDistribution() *Distribution {
 	return &Distribution{
 		Name:      uniformGenerator,
-		generator: generators[uniformGenerator],
+		generator: bender.UniformIntervalGenerator,
 	}
 }
 
@@ -70,10 +82,15 @@ func (d *Distribution) String() string {
 
 // Set validates a given value and sets distribution (allows prefix matching).
 func (d *Distribution) Set(value string) error {
+	name, rawParams := value, ""
+	if i := strings.Index(value, ":"); i >= 0 {
+		name, rawParams = value[:i], value[i+1:]
+	}
+
 	matches := []string{}
 
 	for key := range generators {
-		if strings.HasPrefix(key, value) {
+		if strings.HasPrefix(key, name) {
 			matches = append(matches, key)
 		}
 	}
@@ -85,12 +102,25 @@ func (d *Distribution) Set(value string) error {
 	} else if len(matches) > 1 {
 		sort.Strings(matches)
 
-		return fmt.Errorf("%w, ambiguous prefix %q matches: %s", ErrInvalidGenerator, value, ChoicesString(matches))
+		return fmt.Errorf("%w, ambiguous prefix %q matches: %s", ErrInvalidGenerator, name, ChoicesString(matches))
+	}
+
+	params, err := parseDistributionParams(rawParams)
+	if err != nil {
+		return err
+	}
+
+	generator, err := generators[matches[0]](params)
+	if err != nil {
+		return err
+	}
+
+	d.Name = matches[0]
+	if len(rawParams) > 0 {
+		d.Name = fmt.Sprintf("%s:%s", matches[0], rawParams)
 	}
 
-	generator := matches[0]
-	d.Name = generator
-	d.generator = generators[generator]
+	d.generator = generator
 
 	return nil
 }
@@ -127,7 +157,7 @@ func GetDistributionValue(v pflag.Value) (DistributionGenerator, error) {
 // Bash completion function constants.
 const (
 	fnameDistribution = "__fbender_handle_distribution_flag"
-	fbodyDistribution = `COMPREPLY=($(compgen -W "uniform exponential" -- "${cur}"))`
+	fbodyDistribution = `COMPREPLY=($(compgen -W "uniform exponential burst pareto sinusoidal intervals" -- "${cur}"))`
 )
 
 // BashCompletionDistribution adds bash completion to a distribution flag.
@@ -0,0 +1,227 @@
+/*
+Copyright (c) Facebook, Inc. and its affiliates.
+All rights reserved.
+
+This source code is licensed under the BSD-style license found in the
+LICENSE file in the root directory of this source tree.
+*/
+
+package flags
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/facebookincubator/fbender/log"
+	"github.com/facebookincubator/fbender/tester"
+)
+
+// distributionParams holds the "key=value" parameters of a distribution.
+type distributionParams map[string]string
+
+// parseDistributionParams parses a comma separated list of parameters.
+func parseDistributionParams(s string) (distributionParams, error) {
+	params := distributionParams{}
+	if len(s) == 0 {
+		return params, nil
+	}
+
+	for _, param := range strings.Split(s, ",") {
+		kv := strings.SplitN(param, "=", 2)
+		if len(kv) != 2 || len(kv[0]) == 0 {
+			return nil, fmt.Errorf("%w, want: key=value, got: %q", ErrInvalidGenerator, param)
+		}
+
+		params[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+	}
+
+	return params, nil
+}
+
+// allow returns an error if there are parameters other than the given keys.
+func (p distributionParams) allow(keys ...string) error {
+	allowed := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		allowed[key] = true
+	}
+
+	unknown := []string{}
+
+	for key := range p {
+		if !allowed[key] {
+			unknown = append(unknown, key)
+		}
+	}
+
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+
+		return fmt.Errorf("%w, unknown parameters %s, want: %s", ErrInvalidGenerator, ChoicesString(unknown),
+			ChoicesString(keys))
+	}
+
+	return nil
+}
+
+func (p distributionParams) int(key string, value int) (int, error) {
+	if s, ok := p[key]; ok {
+		v, err := strconv.Atoi(s)
+		if err != nil {
+			return 0, fmt.Errorf("%w, invalid %s: %v", ErrInvalidGenerator, key, err)
+		}
+
+		return v, nil
+	}
+
+	return value, nil
+}
+
+func (p distributionParams) float(key string, value float64) (float64, error) {
+	if s, ok := p[key]; ok {
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return 0, fmt.Errorf("%w, invalid %s: %v", ErrInvalidGenerator, key, err)
+		}
+
+		return v, nil
+	}
+
+	return value, nil
+}
+
+func (p distributionParams) duration(key string, value time.Duration) (time.Duration, error) {
+	if s, ok := p[key]; ok {
+		v, err := time.ParseDuration(s)
+		if err != nil {
+			return 0, fmt.Errorf("%w, invalid %s: %v", ErrInvalidGenerator, key, err)
+		}
+
+		return v, nil
+	}
+
+	return value, nil
+}
+
+// noParams creates a factory of a distribution without parameters.
+func noParams(generator DistributionGenerator) distributionFactory {
+	return func(params distributionParams) (DistributionGenerator, error) {
+		if err := params.allow(); err != nil {
+			return nil, err
+		}
+
+		return generator, nil
+	}
+}
+
+// burstDistribution creates a distribution sending the requests in bursts.
+func burstDistribution(params distributionParams) (DistributionGenerator, error) {
+	if err := params.allow("size", "spread", "period"); err != nil {
+		return nil, err
+	}
+
+	size, err := params.int("size", 10)
+	if err != nil {
+		return nil, err
+	}
+
+	spread, err := params.duration("spread", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	period, err := params.duration("period", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if size < 1 || spread < 0 || period < 0 {
+		return nil, fmt.Errorf("%w, burst size must be positive, spread and period non-negative, got: %d, %s, %s",
+			ErrInvalidGenerator, size, spread, period)
+	}
+
+	if period > 0 && spread > period {
+		return nil, fmt.Errorf("%w, burst spread must not be longer than the period, got: %s > %s",
+			ErrInvalidGenerator, spread, period)
+	}
+
+	return tester.BurstDistribution(size, spread, period), nil
+}
+
+// paretoDistribution creates a heavy-tailed distribution.
+func paretoDistribution(params distributionParams) (DistributionGenerator, error) {
+	if err := params.allow("alpha"); err != nil {
+		return nil, err
+	}
+
+	alpha, err := params.float("alpha", 1.5)
+	if err != nil {
+		return nil, err
+	}
+
+	if alpha <= 1 {
+		return nil, fmt.Errorf("%w, pareto alpha must be greater than 1, got: %g", ErrInvalidGenerator, alpha)
+	}
+
+	return tester.ParetoDistribution(alpha), nil
+}
+
+// sinusoidalDistribution creates a distribution with a sine wave modulated QPS.
+func sinusoidalDistribution(params distributionParams) (DistributionGenerator, error) {
+	if err := params.allow("period", "amplitude"); err != nil {
+		return nil, err
+	}
+
+	period, err := params.duration("period", time.Minute)
+	if err != nil {
+		return nil, err
+	}
+
+	amplitude, err := params.float("amplitude", 0.5)
+	if err != nil {
+		return nil, err
+	}
+
+	if period <= 0 || amplitude < 0 || amplitude >= 1 {
+		return nil, fmt.Errorf("%w, sinusoidal period must be positive and amplitude in [0, 1), got: %s, %g",
+			ErrInvalidGenerator, period, amplitude)
+	}
+
+	return tester.SinusoidalDistribution(period, amplitude), nil
+}
+
+// intervalsDistribution creates an empirical distribution of the intervals
+// loaded from a file.
+func intervalsDistribution(params distributionParams) (DistributionGenerator, error) {
+	if err := params.allow("file"); err != nil {
+		return nil, err
+	}
+
+	filename, ok := params["file"]
+	if !ok {
+		return nil, fmt.Errorf("%w, intervals file parameter is required", ErrInvalidGenerator)
+	}
+
+	file, err := os.Open(filename)
+	if err != nil {
+		//nolint:wrapcheck
+		return nil, err
+	}
+
+	defer func() {
+		if err := file.Close(); err != nil {
+			log.Errorf("Warning: Error closing intervals file: %v\n", err)
+		}
+	}()
+
+	intervals, err := tester.ParseIntervals(file)
+	if err != nil {
+		//nolint:wrapcheck
+		return nil, err
+	}
+
+	return tester.EmpiricalDistribution(intervals), nil
+}
@@ -9,7 +9,10 @@ LICENSE file in the root directory of this source tree.
 package flags_test
 
 import (
+	"io/ioutil"
+	"os"
 	"testing"
+	"time"
 
 	"github.com/facebookincubator/fbender/flags"
 	"github.com/pinterest/bender"
@@ -28,7 +31,7 @@ func TestNewDefaultDistribution(t *testing.T) {
 }
 
 func TestDistributionChoices(t *testing.T) {
-	expected := []string{"uniform", "exponential"}
+	expected := []string{"uniform", "exponential", "burst", "pareto", "sinusoidal", "intervals"}
 	assert.ElementsMatch(t, expected, flags.DistributionChoices())
 }
 
@@ -72,12 +75,66 @@ func TestDistribution__Set(t *testing.T) {
 	// Setting unknown distribution should fail.
 	err = distribution.Set("unknown")
 	assert.ErrorIs(t, err, flags.ErrInvalidGenerator)
-	assert.EqualError(t, err, "invalid generator, want: (exponential|uniform), got: \"unknown\"")
+	assert.EqualError(t, err, "invalid generator, want: (burst|exponential|intervals|pareto|sinusoidal|uniform), got: \"unknown\"")
 	// Setting known distribution through an ambiguous prefix should fail.
 	err = distribution.Set("")
 	assert.Error(t, err)
 	assert.ErrorIs(t, err, flags.ErrInvalidGenerator)
-	assert.EqualError(t, err, "invalid generator, ambiguous prefix \"\" matches: (exponential|uniform)")
+	assert.EqualError(t, err, "invalid generator, ambiguous prefix \"\" matches: "+
+		"(burst|exponential|intervals|pareto|sinusoidal|uniform)")
+}
+
+func TestDistribution__SetParams(t *testing.T) {
+	distribution := new(flags.Distribution)
+	// Parameters are kept in the name and defaults are used for missing ones.
+	err := distribution.Set("b:size=50,spread=100ms")
+	require.NoError(t, err)
+	assert.Equal(t, "burst:size=50,spread=100ms", distribution.String())
+	require.NotNil(t, distribution.Get())
+	err = distribution.Set("burst:size=50,period=1s")
+	require.NoError(t, err)
+	assert.Equal(t, "burst:size=50,period=1s", distribution.String())
+	// A cycle of 1s sends its 100 requests in 2 bursts of 50.
+	generator := distribution.Get()(100)
+	assert.Equal(t, []int64{int64(500 * time.Millisecond), 0}, []int64{generator(0), generator(0)})
+	err = distribution.Set("pareto")
+	require.NoError(t, err)
+	assert.Equal(t, "pareto", distribution.String())
+	err = distribution.Set("sinusoidal:period=10s,amplitude=0.2")
+	require.NoError(t, err)
+	assert.Equal(t, "sinusoidal:period=10s,amplitude=0.2", distribution.String())
+
+	file, err := ioutil.TempFile("", "intervals")
+	require.NoError(t, err)
+
+	defer os.Remove(file.Name())
+
+	_, err = file.WriteString("10ms\n0.01\n")
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+	err = distribution.Set("intervals:file=" + file.Name())
+	require.NoError(t, err)
+	// The intervals are rescaled to the QPS.
+	assert.Equal(t, int64(20*time.Millisecond), distribution.Get()(50)(0))
+	// Invalid parameters should fail.
+	for value, message := range map[string]string{
+		"uniform:size=1":            "invalid generator, unknown parameters (size), want: ()",
+		"burst:size":                "invalid generator, want: key=value, got: \"size\"",
+		"burst:size=many":           "invalid generator, invalid size: strconv.Atoi: parsing \"many\": invalid syntax",
+		"burst:size=0":              "invalid generator, burst size must be positive, spread and period non-negative, got: 0, 0s, 0s",
+		"burst:spread=2s,period=1s": "invalid generator, burst spread must not be longer than the period, got: 2s > 1s",
+		"burst:size=5,period=never": "invalid generator, invalid period: time: invalid duration \"never\"",
+		"pareto:alpha=1":            "invalid generator, pareto alpha must be greater than 1, got: 1",
+		"sinusoidal:amplitude=1":    "invalid generator, sinusoidal period must be positive and amplitude in [0, 1), got: 1m0s, 1",
+		"sinusoidal:period=forever": "invalid generator, invalid period: time: invalid duration \"forever\"",
+		"intervals":                 "invalid generator, intervals file parameter is required",
+	} {
+		err = distribution.Set(value)
+		assert.ErrorIs(t, err, flags.ErrInvalidGenerator)
+		assert.EqualError(t, err, message)
+	}
+	// The last valid distribution is kept.
+	assert.Equal(t, "intervals:file="+file.Name(), distribution.String())
 }
 
 func TestDistribution__Type(t *testing.T) {
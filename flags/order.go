@@ -0,0 +1,146 @@
+/*
+Copyright (c) Facebook, Inc. and its affiliates.
+All rights reserved.
+
+This source code is licensed under the BSD-style license found in the
+LICENSE file in the root directory of this source tree.
+*/
+
+package flags
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/facebookincubator/fbender/tester"
+	"github.com/facebookincubator/fbender/utils"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+const (
+	roundRobinOrder  = "roundrobin"
+	randomOrder      = "random"
+	shuffleOnceOrder = "shuffle-once"
+	onceOrder        = "once"
+)
+
+//nolint:gochecknoglobals
+var orders = map[string]tester.Order{
+	roundRobinOrder:  tester.RoundRobinOrder,
+	randomOrder:      tester.RandomOrder,
+	shuffleOnceOrder: tester.ShuffleOnceOrder,
+	onceOrder:        tester.OnceOrder,
+}
+
+// Order represents an input order flag value.
+type Order struct {
+	Name  string
+	order tester.Order
+}
+
+// NewDefaultOrder returns new order flag with default values.
+func NewDefaultOrder() *Order {
+	return &Order{
+		Name:  roundRobinOrder,
+		order: orders[roundRobinOrder],
+	}
+}
+
+// ErrInvalidOrder is raised when an unknown order is set.
+var ErrInvalidOrder = errors.New("invalid order")
+
+// OrderChoices returns a string representation of available orders.
+func OrderChoices() []string {
+	choices := []string{}
+
+	for key := range orders {
+		choices = append(choices, key)
+	}
+
+	sort.Strings(choices)
+
+	return choices
+}
+
+func (o *Order) String() string {
+	return o.Name
+}
+
+// Set validates a given value and sets an order (allows prefix matching).
+func (o *Order) Set(value string) error {
+	matches := []string{}
+
+	for key := range orders {
+		if strings.HasPrefix(key, value) {
+			matches = append(matches, key)
+		}
+	}
+
+	if len(matches) == 0 {
+		choices := ChoicesString(OrderChoices())
+
+		return fmt.Errorf("%w, want: %s, got: %q", ErrInvalidOrder, choices, value)
+	} else if len(matches) > 1 {
+		sort.Strings(matches)
+
+		return fmt.Errorf("%w, ambiguous prefix %q matches: %s", ErrInvalidOrder, value, ChoicesString(matches))
+	}
+
+	order := matches[0]
+	o.Name = order
+	o.order = orders[order]
+
+	return nil
+}
+
+// Type returns an order type.
+func (o *Order) Type() string {
+	return "order"
+}
+
+// Get returns an input order.
+func (o *Order) Get() tester.Order {
+	return o.order
+}
+
+// GetOrder returns an input order from a pflag set.
+func GetOrder(f *pflag.FlagSet, name string) (tester.Order, error) {
+	flag := f.Lookup(name)
+	if flag == nil {
+		return nil, fmt.Errorf("%w: %q", ErrUndefined, name)
+	}
+
+	return GetOrderValue(flag.Value)
+}
+
+// GetOrderValue returns an input order from a pflag value.
+func GetOrderValue(v pflag.Value) (tester.Order, error) {
+	if order, ok := v.(*Order); ok {
+		return order.Get(), nil
+	}
+
+	return nil, fmt.Errorf("%w, want: order, got: %s", ErrInvalidType, v.Type())
+}
+
+// Bash completion function constants.
+const (
+	fnameOrder = "__fbender_handle_order_flag"
+	fbodyOrder = `COMPREPLY=($(compgen -W "roundrobin random shuffle-once once" -- "${cur}"))`
+)
+
+// BashCompletionOrder adds bash completion to an order flag.
+func BashCompletionOrder(cmd *cobra.Command, f *pflag.FlagSet, name string) error {
+	flag := f.Lookup(name)
+	if flag == nil {
+		return fmt.Errorf("%w: %q", ErrUndefined, name)
+	}
+
+	if _, ok := flag.Value.(*Order); !ok {
+		return fmt.Errorf("%w, want: order, got: %s", ErrInvalidType, flag.Value.Type())
+	}
+
+	return utils.BashCompletion(cmd, f, name, fnameOrder, fbodyOrder)
+}
@@ -0,0 +1,99 @@
+/*
+Copyright (c) Facebook, Inc. and its affiliates.
+All rights reserved.
+
+This source code is licensed under the BSD-style license found in the
+LICENSE file in the root directory of this source tree.
+*/
+
+package flags_test
+
+import (
+	"testing"
+
+	"github.com/facebookincubator/fbender/flags"
+	"github.com/facebookincubator/fbender/tester"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewDefaultOrder(t *testing.T) {
+	order := flags.NewDefaultOrder()
+	require.NotNil(t, order)
+	assert.Equal(t, "roundrobin", order.String())
+	assertPointerEqual(t, tester.RoundRobinOrder, order.Get(), "Expected round robin order")
+}
+
+func TestOrderChoices(t *testing.T) {
+	expected := []string{"roundrobin", "random", "shuffle-once", "once"}
+	assert.ElementsMatch(t, expected, flags.OrderChoices())
+}
+
+func TestOrder__Set(t *testing.T) {
+	order := new(flags.Order)
+	// Setting known order.
+	err := order.Set("random")
+	require.NoError(t, err)
+	assert.Equal(t, "random", order.Name)
+	assertPointerEqual(t, tester.RandomOrder, order.Get(), "Expected random order")
+	// Setting known order through an unambiguous prefix.
+	err = order.Set("s")
+	require.NoError(t, err)
+	assert.Equal(t, "shuffle-once", order.Name)
+	assertPointerEqual(t, tester.ShuffleOnceOrder, order.Get(), "Expected shuffle once order")
+	err = order.Set("once")
+	require.NoError(t, err)
+	assert.Equal(t, "once", order.Name)
+	assertPointerEqual(t, tester.OnceOrder, order.Get(), "Expected once order")
+	// Setting unknown order should fail.
+	err = order.Set("unknown")
+	assert.ErrorIs(t, err, flags.ErrInvalidOrder)
+	assert.EqualError(t, err, "invalid order, want: (once|random|roundrobin|shuffle-once), got: \"unknown\"")
+	// Setting known order through an ambiguous prefix should fail.
+	err = order.Set("r")
+	assert.ErrorIs(t, err, flags.ErrInvalidOrder)
+	assert.EqualError(t, err, "invalid order, ambiguous prefix \"r\" matches: (random|roundrobin)")
+}
+
+func TestOrder__Type(t *testing.T) {
+	order := new(flags.Order)
+	assert.Equal(t, "order", order.Type())
+}
+
+func TestGetOrder(t *testing.T) {
+	order := flags.NewDefaultOrder()
+	f := pflag.NewFlagSet("Test FlagSet", pflag.ExitOnError)
+	f.Var(order, "order", "set order")
+	o, err := flags.GetOrder(f, "order")
+	require.NoError(t, err)
+	assertPointerEqual(t, tester.RoundRobinOrder, o, "Expected round robin order")
+	// Check if error when flag does not exist.
+	_, err = flags.GetOrder(f, "nonexistent")
+	assert.ErrorIs(t, err, flags.ErrUndefined)
+	// Check if error when value is of different type.
+	f.Int("myint", 0, "set myint")
+	_, err = flags.GetOrder(f, "myint")
+	assert.ErrorIs(t, err, flags.ErrInvalidType)
+	assert.EqualError(t, err, "accessed flag type does not match, want: order, got: int")
+}
+
+func TestBashCompletionOrder(t *testing.T) {
+	c := &cobra.Command{}
+	o := flags.NewDefaultOrder()
+	// Check no error when applied to order flag
+	f := c.Flags().VarPF(o, "order", "", "set order")
+	err := flags.BashCompletionOrder(c, c.Flags(), "order")
+	require.NoError(t, err)
+	require.Contains(t, f.Annotations, "cobra_annotation_bash_completion_custom")
+	assert.Equal(t, []string{"__fbender_handle_order_flag"},
+		f.Annotations["cobra_annotation_bash_completion_custom"])
+	// Check error when flag is not defined
+	err = flags.BashCompletionOrder(c, c.Flags(), "nonexistent")
+	assert.ErrorIs(t, err, flags.ErrUndefined)
+	// Check error when flag is not an order
+	c.Flags().Int("myint", 0, "set myint")
+	err = flags.BashCompletionOrder(c, c.Flags(), "myint")
+	assert.ErrorIs(t, err, flags.ErrInvalidType)
+}
@@ -0,0 +1,144 @@
+/*
+Copyright (c) Facebook, Inc. and its affiliates.
+All rights reserved.
+
+This source code is licensed under the BSD-style license found in the
+LICENSE file in the root directory of this source tree.
+*/
+
+package flags
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/facebookincubator/fbender/tester"
+	"github.com/facebookincubator/fbender/utils"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+const (
+	roundRobinSelection = "roundrobin"
+	randomSelection     = "random"
+	weightedSelection   = "weighted"
+)
+
+//nolint:gochecknoglobals
+var selections = map[string]tester.Selection{
+	roundRobinSelection: tester.RoundRobinSelection,
+	randomSelection:     tester.RandomSelection,
+	weightedSelection:   tester.WeightedSelection,
+}
+
+// Selection represents a target selection flag value.
+type Selection struct {
+	Name      string
+	selection tester.Selection
+}
+
+// NewDefaultSelection returns new selection flag with default values.
+func NewDefaultSelection() *Selection {
+	return &Selection{
+		Name:      roundRobinSelection,
+		selection: selections[roundRobinSelection],
+	}
+}
+
+// ErrInvalidSelection is raised when an unknown selection is set.
+var ErrInvalidSelection = errors.New("invalid selection")
+
+// SelectionChoices returns a string representation of available selections.
+func SelectionChoices() []string {
+	choices := []string{}
+
+	for key := range selections {
+		choices = append(choices, key)
+	}
+
+	sort.Strings(choices)
+
+	return choices
+}
+
+func (s *Selection) String() string {
+	return s.Name
+}
+
+// Set validates a given value and sets selection (allows prefix matching).
+func (s *Selection) Set(value string) error {
+	matches := []string{}
+
+	for key := range selections {
+		if strings.HasPrefix(key, value) {
+			matches = append(matches, key)
+		}
+	}
+
+	if len(matches) == 0 {
+		choices := ChoicesString(SelectionChoices())
+
+		return fmt.Errorf("%w, want: %s, got: %q", ErrInvalidSelection, choices, value)
+	} else if len(matches) > 1 {
+		sort.Strings(matches)
+
+		return fmt.Errorf("%w, ambiguous prefix %q matches: %s", ErrInvalidSelection, value, ChoicesString(matches))
+	}
+
+	selection := matches[0]
+	s.Name = selection
+	s.selection = selections[selection]
+
+	return nil
+}
+
+// Type returns a selection type.
+func (s *Selection) Type() string {
+	return "selection"
+}
+
+// Get returns a target selection.
+func (s *Selection) Get() tester.Selection {
+	return s.selection
+}
+
+// GetSelection returns a target selection from a pflag set.
+func GetSelection(f *pflag.FlagSet, name string) (tester.Selection, error) {
+	flag := f.Lookup(name)
+	if flag == nil {
+		return nil, fmt.Errorf("%w: %q", ErrUndefined, name)
+	}
+
+	return GetSelectionValue(flag.Value)
+}
+
+// GetSelectionValue returns a target selection from a pflag value.
+func GetSelectionValue(v pflag.Value) (tester.Selection, error) {
+	if selection, ok := v.(*Selection); ok {
+		return selection.Get(), nil
+	}
+
+	return nil, fmt.Errorf("%w, want: selection, got: %s", ErrInvalidType, v.Type())
+}
+
+// Bash completion function constants.
+const (
+	fnameSelection = "__fbender_handle_selection_flag"
+	fbodySelection = `COMPREPLY=($(compgen -W "roundrobin random weighted" -- "${cur}"))`
+)
+
+// BashCompletionSelection adds bash completion to a selection flag.
+func BashCompletionSelection(cmd *cobra.Command, f *pflag.FlagSet, name string) error {
+	flag := f.Lookup(name)
+	if flag == nil {
+		return fmt.Errorf("%w: %q", ErrUndefined, name)
+	}
+
+	if _, ok := flag.Value.(*Selection); !ok {
+		return fmt.Errorf("%w, want: selection, got: %s", ErrInvalidType, flag.Value.Type())
+	}
+
+	return utils.BashCompletion(cmd, f, name, fnameSelection, fbodySelection)
+}
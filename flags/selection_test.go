@@ -0,0 +1,95 @@
+/*
+Copyright (c) Facebook, Inc. and its affiliates.
+All rights reserved.
+
+This source code is licensed under the BSD-style license found in the
+LICENSE file in the root directory of this source tree.
+*/
+
+package flags_test
+
+import (
+	"testing"
+
+	"github.com/facebookincubator/fbender/flags"
+	"github.com/facebookincubator/fbender/tester"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewDefaultSelection(t *testing.T) {
+	selection := flags.NewDefaultSelection()
+	require.NotNil(t, selection)
+	assert.Equal(t, "roundrobin", selection.String())
+	assertPointerEqual(t, tester.RoundRobinSelection, selection.Get(), "Expected round robin selection")
+}
+
+func TestSelectionChoices(t *testing.T) {
+	expected := []string{"roundrobin", "random", "weighted"}
+	assert.ElementsMatch(t, expected, flags.SelectionChoices())
+}
+
+func TestSelection__Set(t *testing.T) {
+	selection := new(flags.Selection)
+	// Setting known selection.
+	err := selection.Set("random")
+	require.NoError(t, err)
+	assert.Equal(t, "random", selection.Name)
+	assertPointerEqual(t, tester.RandomSelection, selection.Get(), "Expected random selection")
+	// Setting known selection through an unambiguous prefix.
+	err = selection.Set("w")
+	require.NoError(t, err)
+	assert.Equal(t, "weighted", selection.Name)
+	assertPointerEqual(t, tester.WeightedSelection, selection.Get(), "Expected weighted selection")
+	// Setting unknown selection should fail.
+	err = selection.Set("unknown")
+	assert.ErrorIs(t, err, flags.ErrInvalidSelection)
+	assert.EqualError(t, err, "invalid selection, want: (random|roundrobin|weighted), got: \"unknown\"")
+	// Setting known selection through an ambiguous prefix should fail.
+	err = selection.Set("r")
+	assert.ErrorIs(t, err, flags.ErrInvalidSelection)
+	assert.EqualError(t, err, "invalid selection, ambiguous prefix \"r\" matches: (random|roundrobin)")
+}
+
+func TestSelection__Type(t *testing.T) {
+	selection := new(flags.Selection)
+	assert.Equal(t, "selection", selection.Type())
+}
+
+func TestGetSelection(t *testing.T) {
+	selection := flags.NewDefaultSelection()
+	f := pflag.NewFlagSet("Test FlagSet", pflag.ExitOnError)
+	f.Var(selection, "selection", "set selection")
+	s, err := flags.GetSelection(f, "selection")
+	require.NoError(t, err)
+	assertPointerEqual(t, tester.RoundRobinSelection, s, "Expected round robin selection")
+	// Check if error when flag does not exist.
+	_, err = flags.GetSelection(f, "nonexistent")
+	assert.ErrorIs(t, err, flags.ErrUndefined)
+	// Check if error when value is of different type.
+	f.Int("myint", 0, "set myint")
+	_, err = flags.GetSelection(f, "myint")
+	assert.ErrorIs(t, err, flags.ErrInvalidType)
+	assert.EqualError(t, err, "accessed flag type does not match, want: selection, got: int")
+}
+
+func TestBashCompletionSelection(t *testing.T) {
+	c := &cobra.Command{}
+	s := flags.NewDefaultSelection()
+	// Check no error when applied to selection flag
+	f := c.Flags().VarPF(s, "selection", "", "set selection")
+	err := flags.BashCompletionSelection(c, c.Flags(), "selection")
+	require.NoError(t, err)
+	require.Contains(t, f.Annotations, "cobra_annotation_bash_completion_custom")
+	assert.Equal(t, []string{"__fbender_handle_selection_flag"},
+		f.Annotations["cobra_annotation_bash_completion_custom"])
+	// Check error when flag is not defined
+	err = flags.BashCompletionSelection(c, c.Flags(), "nonexistent")
+	assert.ErrorIs(t, err, flags.ErrUndefined)
+	// Check error when flag is not a selection
+	c.Flags().Int("myint", 0, "set myint")
+	err = flags.BashCompletionSelection(c, c.Flags(), "myint")
+	assert.ErrorIs(t, err, flags.ErrInvalidType)
+}
@@ -6,6 +6,7 @@ require (
 	github.com/gosuri/uilive v0.0.4 // indirect
 	github.com/gosuri/uiprogress v0.0.1
 	github.com/insomniacslk/dhcp v0.0.0-20201112113307-4de412bc85d8
+	github.com/mattn/go-isatty v0.0.3
 	github.com/mdlayher/netx v0.0.0-20200512211805-669a06fde734 // indirect
 	github.com/miekg/dns v1.1.35
 	github.com/pin/tftp v0.0.0-20200229063000-e4f073737eb2
@@ -16,5 +17,5 @@ require (
 	github.com/stretchr/objx v0.3.0 // indirect
 	github.com/stretchr/testify v1.6.2-0.20201103103935-92707c0b2d50
 	github.com/tj/go-spin v1.1.0
-	gopkg.in/yaml.v3 v3.0.0 // indirect
+	gopkg.in/yaml.v3 v3.0.0
 )
@@ -9,6 +9,8 @@ LICENSE file in the root directory of this source tree.
 package metric
 
 import (
+	"fmt"
+	"sync"
 	"time"
 
 	"github.com/facebookincubator/fbender/recorders"
@@ -16,9 +18,14 @@ import (
 	"github.com/pinterest/bender"
 )
 
-// ErrorsMetric fetches data from statistics.
+// ErrorsMetric fetches data from statistics. If the class is set only the
+// errors of the class are counted, see tester.ClassifyError.
 type ErrorsMetric struct {
-	Statistics recorders.Statistics
+	Class string
+
+	// Statistics of the requests finished in every second of the test
+	mutex   sync.Mutex
+	seconds map[int64]*recorders.Statistics
 }
 
 // ErrorsMetricOptions represents errors metric options.
@@ -33,16 +40,75 @@ func (m *ErrorsMetric) Setup(options interface{}) error {
 		return tester.ErrInvalidOptions
 	}
 
-	opts.AddRecorder(recorders.NewStatisticsRecorder(&m.Statistics))
+	opts.AddRecorder(func(msg interface{}) {
+		switch msg := msg.(type) {
+		case *bender.StartEvent:
+			m.mutex.Lock()
+			m.seconds = make(map[int64]*recorders.Statistics)
+			m.mutex.Unlock()
+		case *bender.EndRequestEvent:
+			m.count(msg.End, m.matches(tester.ClassifyError(msg.Err)))
+		case *tester.DroppedRequestEvent:
+			m.count(msg.Time, m.matches(tester.ErrorClassDropped))
+		}
+	})
 
 	return nil
 }
 
-// Fetch calculates the errors percentage for the statistics.
+// matches checks whether a request with the given error class is counted as
+// an error, the class of successful requests is empty.
+func (m *ErrorsMetric) matches(class string) bool {
+	if len(m.Class) == 0 {
+		return len(class) > 0
+	}
+
+	return class == m.Class
+}
+
+// count adds a request finished at the given time to the statistics of its
+// second.
+func (m *ErrorsMetric) count(end int64, failed bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	second := end / int64(time.Second)
+
+	statistics, ok := m.seconds[second]
+	if !ok {
+		statistics = new(recorders.Statistics)
+		m.seconds[second] = statistics
+	}
+
+	statistics.Requests++
+
+	if failed {
+		statistics.Errors++
+	}
+}
+
+// Fetch calculates the errors percentage of the requests finished during the
+// given period, with one second precision.
 func (m *ErrorsMetric) Fetch(start time.Time, duration time.Duration) ([]tester.DataPoint, error) {
-	errorsPct := float64(m.Statistics.Errors) / float64(m.Statistics.Requests) * 100.0
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	var requests, errors int64
+
+	for second, statistics := range m.seconds {
+		if second >= start.Unix() && second <= start.Add(duration).Unix() {
+			requests += statistics.Requests
+			errors += statistics.Errors
+		}
+	}
+
+	if requests == 0 {
+		return nil, nil
+	}
+
+	errorsPct := float64(errors) / float64(requests) * 100.0
 
-	// return a single point with time equal to end of the test
+	// return a single point with time equal to end of the period
 	return []tester.DataPoint{
 		{Time: start.Add(duration), Value: errorsPct},
 	}, nil
@@ -50,5 +116,21 @@ func (m *ErrorsMetric) Fetch(start time.Time, duration time.Duration) ([]tester.
 
 // Name returns the name of the errors statistic.
 func (m *ErrorsMetric) Name() string {
+	if len(m.Class) > 0 {
+		return "errors." + m.Class
+	}
+
 	return "errors"
 }
+
+// ParseErrorsMetric creates an errors metric of the given error class.
+func ParseErrorsMetric(class string) (*ErrorsMetric, error) {
+	for _, c := range tester.ErrorClasses {
+		if c == class {
+			return &ErrorsMetric{Class: class}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: unknown error class %q, want one of %v", tester.ErrInvalidFormat, class,
+		tester.ErrorClasses)
+}
@@ -0,0 +1,211 @@
+/*
+Copyright (c) Facebook, Inc. and its affiliates.
+All rights reserved.
+
+This source code is licensed under the BSD-style license found in the
+LICENSE file in the root directory of this source tree.
+*/
+
+package metric
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/facebookincubator/fbender/tester"
+)
+
+// ErrExec is returned when a metric command fails.
+var ErrExec = errors.New("metric command failed")
+
+// execTimeout limits the time of a single metric command run.
+const execTimeout = 30 * time.Second
+
+// ExecMetric fetches the data points printed by an external command. The
+// command is configured in options under the source name.
+type ExecMetric struct {
+	Source string
+
+	command []string
+}
+
+// ExecMetricOptions represents external command metric options.
+type ExecMetricOptions interface {
+	GetExecMetric(name string) (string, bool)
+}
+
+// ParseExecMetric creates an external command metric of the source.
+func ParseExecMetric(source string) (*ExecMetric, error) {
+	if len(source) == 0 {
+		return nil, fmt.Errorf("%w, exec requires a name (e.g. exec:cpu), got: %q", tester.ErrInvalidFormat, source)
+	}
+
+	return &ExecMetric{Source: source}, nil
+}
+
+// Setup prepares external command metric.
+func (m *ExecMetric) Setup(options interface{}) error {
+	opts, ok := options.(ExecMetricOptions)
+	if !ok {
+		return tester.ErrInvalidOptions
+	}
+
+	command, ok := opts.GetExecMetric(m.Source)
+	if !ok {
+		return fmt.Errorf("%w: metric command %q is not defined (use --exec-metric %s=COMMAND)",
+			tester.ErrInvalidOptions, m.Source, m.Source)
+	}
+
+	words, err := splitWords(command)
+	if err != nil {
+		return fmt.Errorf("%w: metric command %q: %v", tester.ErrInvalidOptions, m.Source, err)
+	}
+
+	m.command = words
+	if len(m.command) == 0 {
+		return fmt.Errorf("%w: metric command %q is empty", tester.ErrInvalidOptions, m.Source)
+	}
+
+	return nil
+}
+
+// splitWords splits the command into words the way a POSIX shell does, without
+// any expansions: words are separated by blanks, single quotes preserve all the
+// characters, double quotes preserve all but the escaped \, ", $ and ` and
+// a backslash outside quotes escapes the next character.
+func splitWords(command string) ([]string, error) {
+	var (
+		words []string
+		word  strings.Builder
+		// Quoted empty strings are words as well
+		inWord bool
+	)
+
+	for i := 0; i < len(command); i++ {
+		switch c := command[i]; c {
+		case ' ', '\t', '\n':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+
+			continue
+		case '\\':
+			if i++; i >= len(command) {
+				return nil, fmt.Errorf("%w: trailing backslash", tester.ErrInvalidFormat)
+			}
+
+			word.WriteByte(command[i])
+		case '\'':
+			end := strings.IndexByte(command[i+1:], '\'')
+			if end < 0 {
+				return nil, fmt.Errorf("%w: unterminated single quote", tester.ErrInvalidFormat)
+			}
+
+			word.WriteString(command[i+1 : i+1+end])
+			i += end + 1
+		case '"':
+			for i++; ; i++ {
+				if i >= len(command) {
+					return nil, fmt.Errorf("%w: unterminated double quote", tester.ErrInvalidFormat)
+				}
+
+				if command[i] == '"' {
+					break
+				}
+
+				if command[i] == '\\' && i+1 < len(command) && strings.IndexByte("\\\"$`", command[i+1]) >= 0 {
+					i++
+				}
+
+				word.WriteByte(command[i])
+			}
+		default:
+			word.WriteByte(c)
+		}
+
+		inWord = true
+	}
+
+	if inWord {
+		words = append(words, word.String())
+	}
+
+	return words, nil
+}
+
+// Fetch runs the command with the start (Unix time in seconds) and the duration
+// (in seconds) of the given period appended to its arguments. They are also set
+// in the FBENDER_START, FBENDER_END and FBENDER_DURATION environment variables.
+// The command prints a "Time Value" data point per line, the time either in
+// seconds or in the RFC 3339 format. Empty lines and lines starting with "#"
+// are ignored.
+func (m *ExecMetric) Fetch(start time.Time, duration time.Duration) ([]tester.DataPoint, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), execTimeout)
+	defer cancel()
+
+	startArg := formatTimestamp(start)
+	durationArg := strconv.FormatFloat(duration.Seconds(), 'f', -1, 64)
+
+	//nolint:gosec
+	cmd := exec.CommandContext(ctx, m.command[0], append(m.command[1:], startArg, durationArg)...)
+	cmd.Env = append(os.Environ(),
+		"FBENDER_START="+startArg,
+		"FBENDER_END="+formatTimestamp(start.Add(duration)),
+		"FBENDER_DURATION="+durationArg,
+	)
+
+	stderr := new(bytes.Buffer)
+	cmd.Stderr = stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v: %s", ErrExec, m.Source, err, strings.TrimSpace(stderr.String()))
+	}
+
+	return parseDataPoints(m.Source, output)
+}
+
+// Name returns the name of the external command metric.
+func (m *ExecMetric) Name() string {
+	return "exec:" + m.Source
+}
+
+// parseDataPoints parses the "Time Value" lines printed by a metric command.
+func parseDataPoints(source string, output []byte) ([]tester.DataPoint, error) {
+	// No points if the command prints nothing, so it fails the checks
+	var points []tester.DataPoint
+
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		t, rest, err := tester.ParseTimedLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: line %d: %v", ErrExec, source, n, err)
+		}
+
+		value, err := strconv.ParseFloat(rest, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: line %d: invalid value: %q", ErrExec, source, n, rest)
+		}
+
+		points = append(points, tester.DataPoint{Time: t, Value: value})
+	}
+
+	//nolint:wrapcheck
+	return points, scanner.Err()
+}
@@ -0,0 +1,130 @@
+/*
+Copyright (c) Facebook, Inc. and its affiliates.
+All rights reserved.
+
+This source code is licensed under the BSD-style license found in the
+LICENSE file in the root directory of this source tree.
+*/
+
+package metric_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/facebookincubator/fbender/metric"
+	"github.com/facebookincubator/fbender/tester"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type execOptions map[string]string
+
+func (o execOptions) GetExecMetric(name string) (string, bool) {
+	command, ok := o[name]
+
+	return command, ok
+}
+
+// writeScript creates an executable shell script in a temporary directory.
+func writeScript(t *testing.T, script string) string {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "fbender")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	path := filepath.Join(dir, "metric.sh")
+	require.NoError(t, ioutil.WriteFile(path, []byte("#!/bin/sh\n"+script), 0o700))
+
+	return path
+}
+
+func TestExecMetric(t *testing.T) {
+	script := writeScript(t, `
+echo "# $1 $2 $3"
+echo "$2 $1"
+echo
+echo "$FBENDER_START $FBENDER_DURATION"
+echo "$FBENDER_END 1.5"
+echo "2020-09-13T12:26:40Z 42"
+`)
+
+	m, err := metric.Parser("exec:cpu")
+	require.NoError(t, err)
+	assert.Equal(t, "exec:cpu", m.Name())
+	require.NoError(t, m.Setup(execOptions{"cpu": script + " 7"}))
+
+	points, err := m.Fetch(time.Unix(1600000000, 0), 10*time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, []tester.DataPoint{
+		{Time: time.Unix(1600000000, 0), Value: 7},
+		{Time: time.Unix(1600000000, 0), Value: 10},
+		{Time: time.Unix(1600000010, 0), Value: 1.5},
+		{Time: time.Unix(1600000000, 0).UTC(), Value: 42},
+	}, points)
+}
+
+func TestExecMetric_Quoting(t *testing.T) {
+	// Prints the number of arguments and the lengths of the first two
+	script := writeScript(t, `
+echo "$FBENDER_START $#"
+echo "$FBENDER_START ${#1}"
+echo "$FBENDER_START ${#2}"
+`)
+
+	m, err := metric.ParseExecMetric("args")
+	require.NoError(t, err)
+	require.NoError(t, m.Setup(execOptions{"args": script + ` 'a b' "c \"$d\""  e\ f`}))
+
+	points, err := m.Fetch(time.Unix(1600000000, 0), 10*time.Second)
+	require.NoError(t, err)
+	require.Len(t, points, 3)
+	assert.Equal(t, []float64{5, 3, 6}, []float64{points[0].Value, points[1].Value, points[2].Value})
+
+	for _, command := range []string{script + " 'a", script + ` "a`, script + ` a\`} {
+		assert.ErrorIs(t, m.Setup(execOptions{"args": command}), tester.ErrInvalidOptions, command)
+	}
+}
+
+func TestExecMetric_Errors(t *testing.T) {
+	m, err := metric.ParseExecMetric("cpu")
+	require.NoError(t, err)
+
+	// Invalid options
+	assert.ErrorIs(t, m.Setup(execOptions{}), tester.ErrInvalidOptions)
+	assert.ErrorIs(t, m.Setup(execOptions{"cpu": " "}), tester.ErrInvalidOptions)
+	assert.ErrorIs(t, m.Setup(nil), tester.ErrInvalidOptions)
+
+	// Failing command
+	require.NoError(t, m.Setup(execOptions{"cpu": writeScript(t, "echo failed >&2; exit 1")}))
+	_, err = m.Fetch(time.Unix(1600000000, 0), 10*time.Second)
+	assert.ErrorIs(t, err, metric.ErrExec)
+	assert.Contains(t, err.Error(), "failed")
+
+	// Invalid output
+	require.NoError(t, m.Setup(execOptions{"cpu": writeScript(t, "echo 1600000000 high")}))
+	_, err = m.Fetch(time.Unix(1600000000, 0), 10*time.Second)
+	assert.ErrorIs(t, err, metric.ErrExec)
+
+	require.NoError(t, m.Setup(execOptions{"cpu": writeScript(t, "echo yesterday 1")}))
+	_, err = m.Fetch(time.Unix(1600000000, 0), 10*time.Second)
+	assert.ErrorIs(t, err, metric.ErrExec)
+
+	// No output
+	c, err := tester.ParseConstraint("MAX(exec:cpu) < 80", metric.Parser)
+	require.NoError(t, err)
+	require.NoError(t, c.Setup(execOptions{"cpu": writeScript(t, "echo '# nothing'")}))
+
+	points, err := c.Metric.Fetch(time.Unix(1600000000, 0), 10*time.Second)
+	require.NoError(t, err)
+	assert.Nil(t, points)
+	assert.ErrorIs(t, c.Check(time.Unix(1600000000, 0), 10*time.Second), tester.ErrNoDataPoints)
+
+	// Empty name
+	_, err = metric.Parser("exec:")
+	assert.ErrorIs(t, err, tester.ErrInvalidFormat)
+}
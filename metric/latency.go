@@ -39,11 +39,13 @@ func (m *LatencyMetric) Setup(options interface{}) error {
 	opts.AddRecorder(func(msg interface{}) {
 		switch msg := msg.(type) {
 		case *bender.StartEvent:
+			m.mutex.Lock()
 			m.points = make([]tester.DataPoint, 0)
+			m.mutex.Unlock()
 		case *bender.EndRequestEvent:
 			m.mutex.Lock()
 			m.points = append(m.points, tester.DataPoint{
-				Time:  time.Unix(msg.Start, 0),
+				Time:  time.Unix(0, msg.Start),
 				Value: float64(msg.End-msg.Start) / float64(unit),
 			})
 			m.mutex.Unlock()
@@ -53,9 +55,21 @@ func (m *LatencyMetric) Setup(options interface{}) error {
 	return nil
 }
 
-// Fetch calculates the errors percentage for the statistics.
+// Fetch returns latencies of the requests started during the given period.
 func (m *LatencyMetric) Fetch(start time.Time, duration time.Duration) ([]tester.DataPoint, error) {
-	return m.points, nil
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	end := start.Add(duration)
+	points := make([]tester.DataPoint, 0, len(m.points))
+
+	for _, point := range m.points {
+		if !point.Time.Before(start) && !point.Time.After(end) {
+			points = append(points, point)
+		}
+	}
+
+	return points, nil
 }
 
 // Name returns the name of the errors statistic.
@@ -9,6 +9,8 @@ LICENSE file in the root directory of this source tree.
 package metric
 
 import (
+	"strings"
+
 	"github.com/facebookincubator/fbender/tester"
 )
 
@@ -16,18 +18,110 @@ import (
 const Help = `
 Basic Metrics:
 * errors - errors percentage of all requests, ignores aggregator
+* errors.CLASS - percentage of all requests failed with an error of the class:
+  timeout, network, status (unexpected HTTP status or DNS rcode), validation
+  (invalid response), dropped (dropped by the load generator) or other
 * latency - latency of the packets (in unit specified by --unit)
+* throughput - successful responses per second, sampled every second
+* goodput:LIMIT - successful responses within the latency limit (in unit
+  specified by --unit) per second, sampled every second
   MAX(errors) < 10.0
   MIN(errors) < 42.0
-  AVG(latency) < 30`
+  MAX(errors.timeout) < 1.0
+  AVG(latency) < 30
+  MIN(throughput) > 9500
+  MIN(goodput:20) > 9000
+
+External metrics:
+* prom:QUERY - values of a Prometheus query (PromQL) evaluated during the test
+  every --prometheus-step on the server given with --prometheus
+  MAX(prom:cpu_usage{job="dns"}) < 80
+* exec:NAME - data points printed as "Time Value" lines by the command given
+  with --exec-metric NAME=COMMAND, run when the constraints are checked with the
+  start (Unix time) and duration (in seconds) of the checked period appended to
+  the arguments, the command is split with the shell quoting rules but it's
+  not run by a shell
+  AVG(exec:cpu) < 80
+
+Local process metrics, sampled every second of the test:
+* proc:PROCESS.cpu - CPU usage in percent of a single CPU
+* proc:PROCESS.rss - resident set size in MiB
+* proc:PROCESS.fds - number of open file descriptors
+* proc:PROCESS.threads - number of threads
+  The process is either a pid or a name, usage of all the processes of the
+  same name is summed up
+  MAX(proc:named.cpu) < 400
+  MAX(proc:1234.rss) < 2048
+
+Metrics of a single target or a single scenario part:
+* metric@target - e.g. latency@dns, errors@10.0.0.1:53
+  AVG(latency@dns) < 10`
 
 // Parser is a parser for standard metrics.
 func Parser(value string) (tester.Metric, error) {
+	// Queries and process names may contain "@", they are never restricted to
+	// a target
+	if strings.HasPrefix(value, "prom:") {
+		metric, err := ParsePrometheusMetric(strings.TrimPrefix(value, "prom:"))
+		if err != nil {
+			return nil, err
+		}
+
+		return metric, nil
+	}
+
+	if strings.HasPrefix(value, "proc:") {
+		metric, err := ParseProcMetric(strings.TrimPrefix(value, "proc:"))
+		if err != nil {
+			return nil, err
+		}
+
+		return metric, nil
+	}
+
+	if i := strings.LastIndex(value, "@"); i > 0 && i < len(value)-1 {
+		metric, err := Parser(value[:i])
+		if err != nil {
+			return nil, err
+		}
+
+		return &TargetMetric{Metric: metric, Target: value[i+1:]}, nil
+	}
+
+	if strings.HasPrefix(value, "errors.") {
+		metric, err := ParseErrorsMetric(strings.TrimPrefix(value, "errors."))
+		if err != nil {
+			return nil, err
+		}
+
+		return metric, nil
+	}
+
+	if strings.HasPrefix(value, "exec:") {
+		metric, err := ParseExecMetric(strings.TrimPrefix(value, "exec:"))
+		if err != nil {
+			return nil, err
+		}
+
+		return metric, nil
+	}
+
+	if strings.HasPrefix(value, "goodput:") {
+		metric, err := ParseGoodputMetric(strings.TrimPrefix(value, "goodput:"))
+		if err != nil {
+			return nil, err
+		}
+
+		return metric, nil
+	}
+
 	switch value {
 	case "errors":
 		return new(ErrorsMetric), nil
 	case "latency":
 		return new(LatencyMetric), nil
+	case "throughput":
+		return new(ThroughputMetric), nil
 	default:
 		return nil, tester.ErrNotParsed
 	}
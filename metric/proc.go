@@ -0,0 +1,100 @@
+/*
+Copyright (c) Facebook, Inc. and its affiliates.
+All rights reserved.
+
+This source code is licensed under the BSD-style license found in the
+LICENSE file in the root directory of this source tree.
+*/
+
+package metric
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/facebookincubator/fbender/recorders"
+	"github.com/facebookincubator/fbender/tester"
+)
+
+// ProcResources lists the process resources available as metrics.
+//nolint:gochecknoglobals
+var ProcResources = map[string]func(recorders.ProcessSample) float64{
+	"cpu":     func(s recorders.ProcessSample) float64 { return s.CPU },
+	"rss":     func(s recorders.ProcessSample) float64 { return float64(s.RSS) / recorders.BytesInMiB },
+	"fds":     func(s recorders.ProcessSample) float64 { return float64(s.FDs) },
+	"threads": func(s recorders.ProcessSample) float64 { return float64(s.Threads) },
+}
+
+// ProcMetric fetches the resource usage of a local process sampled during the
+// test: CPU usage in percent of a single CPU, resident set size in MiB, number
+// of open file descriptors or number of threads.
+type ProcMetric struct {
+	Process  string
+	Resource string
+
+	usage *recorders.ProcessUsage
+}
+
+// ProcMetricOptions represents process metric options.
+type ProcMetricOptions interface {
+	WatchProcess(process string) *recorders.ProcessUsage
+}
+
+// ParseProcMetric parses a process metric "Process.Resource", the process is
+// either a pid or a name.
+func ParseProcMetric(value string) (*ProcMetric, error) {
+	i := strings.LastIndex(value, ".")
+	if i <= 0 {
+		return nil, fmt.Errorf("%w, proc requires a process and a resource (e.g. proc:named.cpu), got: %q",
+			tester.ErrInvalidFormat, value)
+	}
+
+	process, resource := value[:i], value[i+1:]
+	if _, ok := ProcResources[resource]; !ok {
+		return nil, fmt.Errorf("%w, proc resource must be cpu, rss, fds or threads, got: %q",
+			tester.ErrInvalidFormat, resource)
+	}
+
+	return &ProcMetric{Process: process, Resource: resource}, nil
+}
+
+// Setup prepares process metric.
+func (m *ProcMetric) Setup(options interface{}) error {
+	opts, ok := options.(ProcMetricOptions)
+	if !ok {
+		return tester.ErrInvalidOptions
+	}
+
+	m.usage = opts.WatchProcess(m.Process)
+
+	return nil
+}
+
+// Fetch returns the samples taken during the given period. Unavailable values
+// (CPU usage of the first sample, file descriptors of other users' processes)
+// are skipped. There are no points if the process wasn't found, so it fails
+// the checks.
+func (m *ProcMetric) Fetch(start time.Time, duration time.Duration) ([]tester.DataPoint, error) {
+	end := start.Add(duration)
+	value := ProcResources[m.Resource]
+
+	var points []tester.DataPoint
+
+	for _, sample := range m.usage.Samples() {
+		if sample.Time.Before(start) || sample.Time.After(end) {
+			continue
+		}
+
+		if v := value(sample); v >= 0 {
+			points = append(points, tester.DataPoint{Time: sample.Time, Value: v})
+		}
+	}
+
+	return points, nil
+}
+
+// Name returns the name of the process metric.
+func (m *ProcMetric) Name() string {
+	return fmt.Sprintf("proc:%s.%s", m.Process, m.Resource)
+}
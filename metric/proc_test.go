@@ -0,0 +1,103 @@
+/*
+Copyright (c) Facebook, Inc. and its affiliates.
+All rights reserved.
+
+This source code is licensed under the BSD-style license found in the
+LICENSE file in the root directory of this source tree.
+*/
+
+package metric_test
+
+import (
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/facebookincubator/fbender/metric"
+	"github.com/facebookincubator/fbender/recorders"
+	"github.com/facebookincubator/fbender/tester"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type procOptions map[string]*recorders.ProcessUsage
+
+func (o procOptions) WatchProcess(process string) *recorders.ProcessUsage {
+	usage, ok := o[process]
+	if !ok {
+		usage = &recorders.ProcessUsage{Process: process}
+		o[process] = usage
+	}
+
+	return usage
+}
+
+func TestProcMetric(t *testing.T) {
+	pid := strconv.Itoa(os.Getpid())
+	options := make(procOptions)
+	metrics := make(map[string]tester.Metric)
+
+	for _, resource := range []string{"cpu", "rss", "fds", "threads"} {
+		m, err := metric.Parser("proc:" + pid + "." + resource)
+		require.NoError(t, err)
+		assert.Equal(t, "proc:"+pid+"."+resource, m.Name())
+		require.NoError(t, m.Setup(options))
+
+		metrics[resource] = m
+	}
+
+	// All the metrics of the process share the samples
+	require.Len(t, options, 1)
+
+	start := time.Now()
+	options[pid].Sample(start)
+	options[pid].Sample(start.Add(time.Second))
+	options[pid].Sample(start.Add(time.Hour))
+
+	for resource, m := range metrics {
+		points, err := m.Fetch(start, time.Second)
+		require.NoError(t, err)
+
+		// CPU usage is unavailable in the first sample
+		if resource == "cpu" {
+			require.Len(t, points, 1, resource)
+			assert.GreaterOrEqual(t, points[0].Value, 0., resource)
+		} else {
+			require.Len(t, points, 2, resource)
+			assert.Greater(t, points[0].Value, 0., resource)
+		}
+	}
+}
+
+func TestProcMetric_NoProcess(t *testing.T) {
+	options := make(procOptions)
+
+	c, err := tester.ParseConstraint("MAX(proc:fbender-nonexistent.cpu) < 80", metric.Parser)
+	require.NoError(t, err)
+	require.NoError(t, c.Setup(options))
+
+	start := time.Now()
+	options["fbender-nonexistent"].Sample(start)
+	options["fbender-nonexistent"].Sample(start.Add(time.Second))
+
+	points, err := c.Metric.Fetch(start, time.Second)
+	require.NoError(t, err)
+	assert.Nil(t, points)
+
+	// A missing process must not satisfy the constraint
+	assert.ErrorIs(t, c.Check(start, time.Second), tester.ErrNoDataPoints)
+}
+
+func TestProcMetric_Errors(t *testing.T) {
+	for _, value := range []string{"proc:named", "proc:.cpu", "proc:named.memory", "proc:named."} {
+		_, err := metric.Parser(value)
+		assert.ErrorIs(t, err, tester.ErrInvalidFormat, value)
+	}
+
+	m, err := metric.ParseProcMetric("named.bin.cpu")
+	require.NoError(t, err)
+	assert.Equal(t, "named.bin", m.Process)
+	assert.Equal(t, "cpu", m.Resource)
+	assert.ErrorIs(t, m.Setup(nil), tester.ErrInvalidOptions)
+}
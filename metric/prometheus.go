@@ -0,0 +1,176 @@
+/*
+Copyright (c) Facebook, Inc. and its affiliates.
+All rights reserved.
+
+This source code is licensed under the BSD-style license found in the
+LICENSE file in the root directory of this source tree.
+*/
+
+package metric
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/facebookincubator/fbender/log"
+	"github.com/facebookincubator/fbender/tester"
+)
+
+// ErrPrometheus is returned when a Prometheus query fails.
+var ErrPrometheus = errors.New("prometheus query failed")
+
+// prometheusTimeout limits the time of a single Prometheus query.
+const prometheusTimeout = 30 * time.Second
+
+// PrometheusMetric fetches the values of a Prometheus query evaluated during
+// the test. The values of all the returned series are combined.
+type PrometheusMetric struct {
+	Query string
+
+	url    string
+	step   time.Duration
+	client *http.Client
+}
+
+// PrometheusMetricOptions represents Prometheus metric options.
+type PrometheusMetricOptions interface {
+	GetPrometheusURL() string
+	GetPrometheusStep() time.Duration
+}
+
+// ParsePrometheusMetric creates a Prometheus metric of the query.
+func ParsePrometheusMetric(query string) (*PrometheusMetric, error) {
+	if len(strings.TrimSpace(query)) == 0 {
+		return nil, fmt.Errorf("%w, prom requires a query (e.g. prom:up), got: %q", tester.ErrInvalidFormat, query)
+	}
+
+	return &PrometheusMetric{Query: query}, nil
+}
+
+// Setup prepares Prometheus metric.
+func (m *PrometheusMetric) Setup(options interface{}) error {
+	opts, ok := options.(PrometheusMetricOptions)
+	if !ok {
+		return tester.ErrInvalidOptions
+	}
+
+	m.url = strings.TrimSuffix(opts.GetPrometheusURL(), "/")
+	if len(m.url) == 0 {
+		return fmt.Errorf("%w: prometheus url is required", tester.ErrInvalidOptions)
+	}
+
+	m.step = opts.GetPrometheusStep()
+	if m.step <= 0 {
+		return fmt.Errorf("%w: prometheus step must be positive, got: %s", tester.ErrInvalidOptions, m.step)
+	}
+
+	m.client = &http.Client{Timeout: prometheusTimeout}
+
+	return nil
+}
+
+// prometheusResponse is a response of the Prometheus HTTP API range query.
+type prometheusResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error"`
+	Data   struct {
+		ResultType string `json:"resultType"`
+		Result     []struct {
+			Values [][2]interface{} `json:"values"`
+		} `json:"result"`
+	} `json:"data"`
+}
+
+// Fetch evaluates the query every step of the given period.
+func (m *PrometheusMetric) Fetch(start time.Time, duration time.Duration) ([]tester.DataPoint, error) {
+	params := url.Values{}
+	params.Set("query", m.Query)
+	params.Set("start", formatTimestamp(start))
+	params.Set("end", formatTimestamp(start.Add(duration)))
+	params.Set("step", strconv.FormatFloat(m.step.Seconds(), 'f', -1, 64))
+
+	resp, err := m.client.PostForm(m.url+"/api/v1/query_range", params)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrPrometheus, err)
+	}
+
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			log.Errorf("Warning: Error closing prometheus response: %v\n", err)
+		}
+	}()
+
+	var result prometheusResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrPrometheus, resp.Status, err)
+	}
+
+	if result.Status != "success" {
+		return nil, fmt.Errorf("%w: %s: %s", ErrPrometheus, resp.Status, result.Error)
+	}
+
+	if result.Data.ResultType != "matrix" {
+		return nil, fmt.Errorf("%w: want matrix result, got: %q", ErrPrometheus, result.Data.ResultType)
+	}
+
+	// No points if the query doesn't match any series, so it fails the checks
+	var points []tester.DataPoint
+
+	for _, series := range result.Data.Result {
+		for _, value := range series.Values {
+			point, err := parsePrometheusValue(value)
+			if err != nil {
+				return nil, err
+			}
+
+			// Missing data (e.g. division by zero) isn't comparable
+			if !math.IsNaN(point.Value) {
+				points = append(points, point)
+			}
+		}
+	}
+
+	return points, nil
+}
+
+// Name returns the name of the Prometheus metric.
+func (m *PrometheusMetric) Name() string {
+	return "prom:" + m.Query
+}
+
+// formatTimestamp formats the time as Unix time in seconds with a fraction.
+func formatTimestamp(t time.Time) string {
+	return strconv.FormatFloat(float64(t.UnixNano())/float64(time.Second), 'f', 3, 64)
+}
+
+// parsePrometheusValue parses a [time, "value"] pair of a range query result.
+func parsePrometheusValue(value [2]interface{}) (tester.DataPoint, error) {
+	timestamp, ok := value[0].(float64)
+	if !ok {
+		return tester.DataPoint{}, fmt.Errorf("%w: invalid timestamp: %v", ErrPrometheus, value[0])
+	}
+
+	s, ok := value[1].(string)
+	if !ok {
+		return tester.DataPoint{}, fmt.Errorf("%w: invalid value: %v", ErrPrometheus, value[1])
+	}
+
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return tester.DataPoint{}, fmt.Errorf("%w: invalid value: %v", ErrPrometheus, err)
+	}
+
+	seconds, fraction := math.Modf(timestamp)
+
+	return tester.DataPoint{
+		Time:  time.Unix(int64(seconds), int64(fraction*float64(time.Second))),
+		Value: v,
+	}, nil
+}
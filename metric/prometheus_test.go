@@ -0,0 +1,121 @@
+/*
+Copyright (c) Facebook, Inc. and its affiliates.
+All rights reserved.
+
+This source code is licensed under the BSD-style license found in the
+LICENSE file in the root directory of this source tree.
+*/
+
+package metric_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/facebookincubator/fbender/metric"
+	"github.com/facebookincubator/fbender/tester"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type prometheusOptions struct {
+	url  string
+	step time.Duration
+}
+
+func (o *prometheusOptions) GetPrometheusURL() string {
+	return o.url
+}
+
+func (o *prometheusOptions) GetPrometheusStep() time.Duration {
+	return o.step
+}
+
+// fakePrometheus serves the range queries with the given response and records
+// the query parameters.
+func fakePrometheus(t *testing.T, status int, response string, params map[string]string) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/query_range", r.URL.Path)
+		assert.NoError(t, r.ParseForm())
+
+		for _, param := range []string{"query", "start", "end", "step"} {
+			params[param] = r.Form.Get(param)
+		}
+
+		w.WriteHeader(status)
+		fmt.Fprint(w, response)
+	}))
+}
+
+func TestPrometheusMetric(t *testing.T) {
+	params := make(map[string]string)
+	server := fakePrometheus(t, http.StatusOK, `{"status": "success", "data": {"resultType": "matrix", "result": [
+		{"metric": {"instance": "a"}, "values": [[1600000000, "10"], [1600000005.5, "NaN"]]},
+		{"metric": {"instance": "b"}, "values": [[1600000000, "42.5"]]}
+	]}}`, params)
+	defer server.Close()
+
+	m, err := metric.Parser(`prom:cpu_usage{job="dns"}`)
+	require.NoError(t, err)
+	assert.Equal(t, `prom:cpu_usage{job="dns"}`, m.Name())
+	require.NoError(t, m.Setup(&prometheusOptions{url: server.URL + "/", step: 5 * time.Second}))
+
+	points, err := m.Fetch(time.Unix(1600000000, 0), 10*time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, []tester.DataPoint{
+		{Time: time.Unix(1600000000, 0), Value: 10},
+		{Time: time.Unix(1600000000, 0), Value: 42.5},
+	}, points)
+	assert.Equal(t, map[string]string{
+		"query": `cpu_usage{job="dns"}`,
+		"start": "1600000000.000",
+		"end":   "1600000010.000",
+		"step":  "5",
+	}, params)
+}
+
+func TestPrometheusMetric_NoSeries(t *testing.T) {
+	server := fakePrometheus(t, http.StatusOK, `{"status": "success", "data": {"resultType": "matrix", "result": [
+		{"metric": {"instance": "a"}, "values": [[1600000000, "NaN"]]}
+	]}}`, make(map[string]string))
+	defer server.Close()
+
+	c, err := tester.ParseConstraint(`MAX(prom:typo{job="dns"}) < 80`, metric.Parser)
+	require.NoError(t, err)
+	require.NoError(t, c.Setup(&prometheusOptions{url: server.URL, step: time.Second}))
+
+	points, err := c.Metric.Fetch(time.Unix(1600000000, 0), 10*time.Second)
+	require.NoError(t, err)
+	assert.Nil(t, points)
+
+	// A query matching nothing must not satisfy the constraint
+	assert.ErrorIs(t, c.Check(time.Unix(1600000000, 0), 10*time.Second), tester.ErrNoDataPoints)
+}
+
+func TestPrometheusMetric_Errors(t *testing.T) {
+	server := fakePrometheus(t, http.StatusBadRequest,
+		`{"status": "error", "errorType": "bad_data", "error": "parse error"}`, make(map[string]string))
+	defer server.Close()
+
+	m, err := metric.ParsePrometheusMetric("rate(x[")
+	require.NoError(t, err)
+	require.NoError(t, m.Setup(&prometheusOptions{url: server.URL, step: time.Second}))
+
+	_, err = m.Fetch(time.Unix(1600000000, 0), 10*time.Second)
+	assert.ErrorIs(t, err, metric.ErrPrometheus)
+	assert.Contains(t, err.Error(), "parse error")
+
+	// Invalid options
+	assert.ErrorIs(t, m.Setup(&prometheusOptions{step: time.Second}), tester.ErrInvalidOptions)
+	assert.ErrorIs(t, m.Setup(&prometheusOptions{url: server.URL}), tester.ErrInvalidOptions)
+	assert.ErrorIs(t, m.Setup(nil), tester.ErrInvalidOptions)
+
+	// Empty query
+	_, err = metric.Parser("prom: ")
+	assert.ErrorIs(t, err, tester.ErrInvalidFormat)
+}
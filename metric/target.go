@@ -0,0 +1,78 @@
+/*
+Copyright (c) Facebook, Inc. and its affiliates.
+All rights reserved.
+
+This source code is licensed under the BSD-style license found in the
+LICENSE file in the root directory of this source tree.
+*/
+
+package metric
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/facebookincubator/fbender/tester"
+	"github.com/pinterest/bender"
+)
+
+// TargetMetric restricts a metric to the requests sent to a single target of
+// a multi target test or a single part of a scenario.
+type TargetMetric struct {
+	tester.Metric
+	Target string
+}
+
+// targetOptions filters the events passed to the recorders added by a metric.
+type targetOptions struct {
+	options interface{}
+	target  string
+}
+
+// AddRecorder adds a recorder receiving only the requests sent to the target.
+func (o *targetOptions) AddRecorder(recorder bender.Recorder) {
+	opts, ok := o.options.(ErrorsMetricOptions)
+	if !ok {
+		return
+	}
+
+	opts.AddRecorder(func(msg interface{}) {
+		if msg, ok := msg.(*bender.EndRequestEvent); ok {
+			response, ok := msg.Response.(*tester.TargetedResponse)
+			if !ok || response.Target != o.target {
+				return
+			}
+		}
+
+		// Dropped requests cannot be attributed to the targets
+		if _, ok := msg.(*tester.DroppedRequestEvent); ok {
+			return
+		}
+
+		recorder(msg)
+	})
+}
+
+// GetUnit returns a unit used in tests.
+func (o *targetOptions) GetUnit() time.Duration {
+	if opts, ok := o.options.(LatencyMetricOptions); ok {
+		return opts.GetUnit()
+	}
+
+	return time.Millisecond
+}
+
+// Setup prepares the metric to gather only the requests sent to the target.
+func (m *TargetMetric) Setup(options interface{}) error {
+	if _, ok := options.(ErrorsMetricOptions); !ok {
+		return tester.ErrInvalidOptions
+	}
+
+	//nolint:wrapcheck
+	return m.Metric.Setup(&targetOptions{options: options, target: m.Target})
+}
+
+// Name returns the name of the metric with the target.
+func (m *TargetMetric) Name() string {
+	return fmt.Sprintf("%s@%s", m.Metric.Name(), m.Target)
+}
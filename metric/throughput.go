@@ -0,0 +1,161 @@
+/*
+Copyright (c) Facebook, Inc. and its affiliates.
+All rights reserved.
+
+This source code is licensed under the BSD-style license found in the
+LICENSE file in the root directory of this source tree.
+*/
+
+package metric
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/facebookincubator/fbender/tester"
+	"github.com/pinterest/bender"
+)
+
+// ThroughputMetric fetches the number of successful responses per second. With
+// a latency limit only the responses received within the limit are counted
+// (goodput).
+type ThroughputMetric struct {
+	// Latency limit of the goodput in the test unit, zero means no limit
+	Limit float64
+
+	limit time.Duration
+
+	// Successful responses received in every second of the test, which lasts
+	// from its start to its end (zero until the test ends)
+	mutex      sync.Mutex
+	seconds    map[int64]int64
+	start, end int64
+}
+
+// ThroughputMetricOptions represents throughput metric options.
+type ThroughputMetricOptions interface {
+	AddRecorder(bender.Recorder)
+}
+
+// ParseGoodputMetric parses a goodput metric "goodput:Limit".
+func ParseGoodputMetric(value string) (*ThroughputMetric, error) {
+	limit, err := strconv.ParseFloat(value, 64)
+	if err != nil || limit <= 0 {
+		return nil, fmt.Errorf("%w, goodput requires a positive latency limit (e.g. goodput:20), got: %q",
+			tester.ErrInvalidFormat, value)
+	}
+
+	return &ThroughputMetric{Limit: limit}, nil
+}
+
+// Setup prepares throughput metric.
+func (m *ThroughputMetric) Setup(options interface{}) error {
+	opts, ok := options.(ThroughputMetricOptions)
+	if !ok {
+		return tester.ErrInvalidOptions
+	}
+
+	if m.Limit > 0 {
+		unitOpts, ok := options.(LatencyMetricOptions)
+		if !ok {
+			return tester.ErrInvalidOptions
+		}
+
+		m.limit = time.Duration(m.Limit * float64(unitOpts.GetUnit()))
+	}
+
+	opts.AddRecorder(func(msg interface{}) {
+		switch msg := msg.(type) {
+		case *bender.StartEvent:
+			m.mutex.Lock()
+			m.seconds = make(map[int64]int64)
+			m.start, m.end = msg.Start, 0
+			m.mutex.Unlock()
+		case *bender.EndEvent:
+			m.mutex.Lock()
+			m.end = msg.End
+			m.mutex.Unlock()
+		case *bender.EndRequestEvent:
+			if msg.Err != nil || (m.limit > 0 && time.Duration(msg.End-msg.Start) > m.limit) {
+				return
+			}
+
+			m.mutex.Lock()
+			m.seconds[msg.End/int64(time.Second)]++
+			m.mutex.Unlock()
+		}
+	})
+
+	return nil
+}
+
+// Fetch returns the number of successful responses in every full second of the
+// given period the test was running in, the seconds before the test started
+// (e.g. during a warm-up) and after it ended aren't counted. If the period
+// doesn't contain any full second a single data point with the average rate is
+// returned.
+func (m *ThroughputMetric) Fetch(start time.Time, duration time.Duration) ([]tester.DataPoint, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.seconds == nil {
+		return nil, nil
+	}
+
+	end := start.Add(duration)
+
+	if testStart := time.Unix(0, m.start); start.Before(testStart) {
+		start = testStart
+	}
+
+	if testEnd := time.Unix(0, m.end); m.end > 0 && end.After(testEnd) {
+		end = testEnd
+	}
+
+	if !end.After(start) {
+		return nil, nil
+	}
+
+	duration = end.Sub(start)
+	first := start.Truncate(time.Second)
+
+	if first.Before(start) {
+		first = first.Add(time.Second)
+	}
+
+	points := make([]tester.DataPoint, 0, int(duration/time.Second))
+
+	for second := first; !second.Add(time.Second).After(end); second = second.Add(time.Second) {
+		points = append(points, tester.DataPoint{
+			Time:  second,
+			Value: float64(m.seconds[second.Unix()]),
+		})
+	}
+
+	if len(points) > 0 {
+		return points, nil
+	}
+
+	var responses int64
+
+	for second, count := range m.seconds {
+		if second >= start.Unix() && second <= end.Unix() {
+			responses += count
+		}
+	}
+
+	return []tester.DataPoint{
+		{Time: end, Value: float64(responses) / duration.Seconds()},
+	}, nil
+}
+
+// Name returns the name of the throughput statistic.
+func (m *ThroughputMetric) Name() string {
+	if m.Limit > 0 {
+		return fmt.Sprintf("goodput:%s", strconv.FormatFloat(m.Limit, 'f', -1, 64))
+	}
+
+	return "throughput"
+}
@@ -0,0 +1,95 @@
+/*
+Copyright (c) Facebook, Inc. and its affiliates.
+All rights reserved.
+
+This source code is licensed under the BSD-style license found in the
+LICENSE file in the root directory of this source tree.
+*/
+
+package metric_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/facebookincubator/fbender/metric"
+	"github.com/facebookincubator/fbender/tester"
+	"github.com/pinterest/bender"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type throughputOptions struct {
+	recorders []bender.Recorder
+}
+
+func (o *throughputOptions) AddRecorder(recorder bender.Recorder) {
+	o.recorders = append(o.recorders, recorder)
+}
+
+func (o *throughputOptions) GetUnit() time.Duration {
+	return time.Millisecond
+}
+
+func (o *throughputOptions) record(msgs ...interface{}) {
+	for _, msg := range msgs {
+		for _, recorder := range o.recorders {
+			recorder(msg)
+		}
+	}
+}
+
+// second returns the time of the given second of the test period.
+func second(s float64) int64 {
+	return time.Unix(1600000000, 0).Add(time.Duration(s * float64(time.Second))).UnixNano()
+}
+
+func TestThroughputMetric(t *testing.T) {
+	options := new(throughputOptions)
+
+	throughput, err := metric.Parser("throughput")
+	require.NoError(t, err)
+	require.NoError(t, throughput.Setup(options))
+
+	goodput, err := metric.Parser("goodput:20")
+	require.NoError(t, err)
+	require.NoError(t, goodput.Setup(options))
+
+	start := time.Unix(1600000000, 0)
+
+	// No test has run yet
+	points, err := throughput.Fetch(start, 10*time.Second)
+	require.NoError(t, err)
+	assert.Nil(t, points)
+
+	// The test runs from 2.5s to 5s of the period
+	options.record(
+		&bender.StartEvent{Start: second(2.5)},
+		&bender.EndRequestEvent{Start: second(3), End: second(3.01)},
+		&bender.EndRequestEvent{Start: second(3), End: second(3.5)},
+		&bender.EndRequestEvent{Start: second(4), End: second(4.01), Err: assert.AnError},
+		&bender.EndEvent{Start: second(2.5), End: second(5)},
+	)
+
+	// Only the full seconds of the test are reported
+	points, err = throughput.Fetch(start, 10*time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, []tester.DataPoint{
+		{Time: time.Unix(1600000003, 0), Value: 2},
+		{Time: time.Unix(1600000004, 0), Value: 0},
+	}, points)
+
+	points, err = goodput.Fetch(start, 10*time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, []tester.DataPoint{
+		{Time: time.Unix(1600000003, 0), Value: 1},
+		{Time: time.Unix(1600000004, 0), Value: 0},
+	}, points)
+
+	// Periods outside of the test have no data points
+	points, err = throughput.Fetch(start, 2*time.Second)
+	require.NoError(t, err)
+	assert.Nil(t, points)
+
+	assert.ErrorIs(t, throughput.Setup(nil), tester.ErrInvalidOptions)
+}
@@ -0,0 +1,82 @@
+/*
+Copyright (c) Facebook, Inc. and its affiliates.
+All rights reserved.
+
+This source code is licensed under the BSD-style license found in the
+LICENSE file in the root directory of this source tree.
+*/
+
+package recorders
+
+import (
+	"sync"
+
+	"github.com/facebookincubator/fbender/tester"
+	"github.com/pinterest/bender"
+)
+
+// ErrorClasses groups the number of all requests and the number of failed
+// requests of every error class.
+type ErrorClasses struct {
+	mutex    sync.Mutex
+	requests int64
+	errors   map[string]int64
+}
+
+// Reset zeroes the counters.
+func (e *ErrorClasses) Reset() {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	e.requests = 0
+	e.errors = make(map[string]int64)
+}
+
+// add counts a request, failed with an error of the given class unless the
+// class is empty.
+func (e *ErrorClasses) add(class string) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if e.errors == nil {
+		e.errors = make(map[string]int64)
+	}
+
+	e.requests++
+
+	if len(class) > 0 {
+		e.errors[class]++
+	}
+}
+
+// Requests returns the number of all requests.
+func (e *ErrorClasses) Requests() int64 {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	return e.requests
+}
+
+// Errors returns the number of requests failed with an error of the class.
+func (e *ErrorClasses) Errors(class string) int64 {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	return e.errors[class]
+}
+
+// NewErrorClassesRecorder creates new recorder which counts the failed requests
+// by their error class. Dropped requests are counted as failed requests of the
+// dropped class.
+func NewErrorClassesRecorder(classes *ErrorClasses) bender.Recorder {
+	return func(msg interface{}) {
+		switch msg := msg.(type) {
+		case *bender.StartEvent:
+			classes.Reset()
+		case *bender.EndRequestEvent:
+			classes.add(tester.ClassifyError(msg.Err))
+		case *tester.DroppedRequestEvent:
+			classes.add(tester.ErrorClassDropped)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+/*
+Copyright (c) Facebook, Inc. and its affiliates.
+All rights reserved.
+
+This source code is licensed under the BSD-style license found in the
+LICENSE file in the root directory of this source tree.
+*/
+
+package recorders_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/facebookincubator/fbender/recorders"
+	"github.com/facebookincubator/fbender/tester"
+	"github.com/pinterest/bender"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorClassesRecorder(t *testing.T) {
+	classes := new(recorders.ErrorClasses)
+	recorder := make(chan interface{}, 7)
+	recorder <- &bender.StartEvent{Start: 0}
+	recorder <- &bender.EndRequestEvent{Start: 0, End: 10}
+	recorder <- &bender.EndRequestEvent{Start: 0, End: 20, Err: context.DeadlineExceeded}
+	recorder <- &bender.EndRequestEvent{Start: 0, End: 20, Err: context.DeadlineExceeded}
+	recorder <- &bender.EndRequestEvent{Start: 0, End: 30, Err: fmt.Errorf("%w: 42", tester.ErrInvalidResponse)}
+	recorder <- &tester.DroppedRequestEvent{Time: 40}
+	recorder <- &bender.EndEvent{End: 100}
+	close(recorder)
+	bender.Record(recorder, recorders.NewErrorClassesRecorder(classes))
+
+	assert.Equal(t, int64(5), classes.Requests())
+	assert.Equal(t, int64(2), classes.Errors(tester.ErrorClassTimeout))
+	assert.Equal(t, int64(1), classes.Errors(tester.ErrorClassValidation))
+	assert.Equal(t, int64(1), classes.Errors(tester.ErrorClassDropped))
+	assert.Equal(t, int64(0), classes.Errors(tester.ErrorClassNetwork))
+
+	recorder = make(chan interface{}, 1)
+	recorder <- &bender.StartEvent{Start: 0}
+	close(recorder)
+	bender.Record(recorder, recorders.NewErrorClassesRecorder(classes))
+
+	assert.Equal(t, int64(0), classes.Requests())
+	assert.Equal(t, int64(0), classes.Errors(tester.ErrorClassTimeout))
+}
@@ -0,0 +1,38 @@
+/*
+Copyright (c) Facebook, Inc. and its affiliates.
+All rights reserved.
+
+This source code is licensed under the BSD-style license found in the
+LICENSE file in the root directory of this source tree.
+*/
+
+package recorders
+
+import (
+	"github.com/facebookincubator/fbender/tester"
+	"github.com/pinterest/bender"
+	"github.com/pinterest/bender/hist"
+)
+
+// NewCorrectedHistogramRecorder creates new recorder which gathers latencies
+// corrected for the coordinated omission. The latency is measured from the
+// time the request was scheduled to be sent rather than the time it was sent,
+// so the time requests spent waiting because the load generator fell behind
+// the schedule is accounted for.
+func NewCorrectedHistogramRecorder(h *hist.Histogram) bender.Recorder {
+	return func(msg interface{}) {
+		switch msg := msg.(type) {
+		case *bender.StartEvent:
+			h.Start(int(msg.Start))
+		case *bender.EndEvent:
+			h.End(int(msg.End))
+		case *tester.ScheduledRequestEvent:
+			elapsed := int(msg.End - msg.Scheduled)
+			if msg.Err == nil {
+				h.Add(elapsed)
+			} else {
+				h.AddError(elapsed)
+			}
+		}
+	}
+}
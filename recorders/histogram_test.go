@@ -0,0 +1,34 @@
+/*
+Copyright (c) Facebook, Inc. and its affiliates.
+All rights reserved.
+
+This source code is licensed under the BSD-style license found in the
+LICENSE file in the root directory of this source tree.
+*/
+
+package recorders_test
+
+import (
+	"testing"
+
+	"github.com/facebookincubator/fbender/recorders"
+	"github.com/facebookincubator/fbender/tester"
+	"github.com/pinterest/bender"
+	"github.com/pinterest/bender/hist"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCorrectedHistogramRecorder(t *testing.T) {
+	h := hist.NewHistogram(100, 1)
+	recorder := make(chan interface{}, 5)
+	recorder <- &bender.StartEvent{Start: 0}
+	// Uncorrected latencies are ignored
+	recorder <- &bender.EndRequestEvent{Start: 10, End: 90}
+	recorder <- &tester.ScheduledRequestEvent{Scheduled: 0, Start: 40, End: 50}
+	recorder <- &tester.ScheduledRequestEvent{Scheduled: 10, Start: 10, End: 30, Err: assert.AnError}
+	recorder <- &bender.EndEvent{End: 100}
+	close(recorder)
+	bender.Record(recorder, recorders.NewCorrectedHistogramRecorder(h))
+
+	assert.Equal(t, []int{20, 50}, h.Percentiles(0, 1))
+}
@@ -9,6 +9,7 @@ LICENSE file in the root directory of this source tree.
 package recorders
 
 import (
+	"github.com/facebookincubator/fbender/tester"
 	"github.com/pinterest/bender"
 	"github.com/sirupsen/logrus"
 )
@@ -29,6 +30,8 @@ func NewLogrusRecorder(l *logrus.Logger, defaults ...logrus.Fields) bender.Recor
 			logStartRequestEvent(log, msg)
 		case *bender.EndRequestEvent:
 			logEndRequestEvent(log, msg)
+		case *tester.DroppedRequestEvent:
+			logDroppedRequestEvent(log, msg)
 		}
 	}
 }
@@ -54,3 +57,10 @@ func logEndRequestEvent(log *logrus.Entry, msg *bender.EndRequestEvent) {
 		log.Info("Success")
 	}
 }
+
+func logDroppedRequestEvent(log *logrus.Entry, msg *tester.DroppedRequestEvent) {
+	log.WithFields(logrus.Fields{
+		"start":   msg.Time,
+		"request": msg.Request,
+	}).Warn("Dropped")
+}
@@ -12,6 +12,7 @@ import (
 	"testing"
 
 	"github.com/facebookincubator/fbender/recorders"
+	"github.com/facebookincubator/fbender/tester"
 	"github.com/pinterest/bender"
 	"github.com/sirupsen/logrus"
 	"github.com/sirupsen/logrus/hooks/test"
@@ -184,6 +185,17 @@ func (s *LogrusRecorderTestSuite) TestEndRequestEvent_Error() {
 	}, s.hook.LastEntry().Data)
 }
 
+func (s *LogrusRecorderTestSuite) TestDroppedRequestEvent() {
+	s.recordSingleEvent(&tester.DroppedRequestEvent{Time: 420, Request: "request"})
+	s.Require().Len(s.hook.Entries, 1)
+	s.Assert().Equal(logrus.WarnLevel, s.hook.LastEntry().Level)
+	s.Assert().Equal("Dropped", s.hook.LastEntry().Message)
+	s.Assert().Equal(logrus.Fields{
+		"start":   int64(420),
+		"request": "request",
+	}, s.hook.LastEntry().Data)
+}
+
 func TestLogrusRecorderTestSuite(t *testing.T) {
 	suite.Run(t, new(LogrusRecorderTestSuite))
 }
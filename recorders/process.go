@@ -0,0 +1,271 @@
+/*
+Copyright (c) Facebook, Inc. and its affiliates.
+All rights reserved.
+
+This source code is licensed under the BSD-style license found in the
+LICENSE file in the root directory of this source tree.
+*/
+
+package recorders
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pinterest/bender"
+)
+
+// clockTicks is the number of clock ticks per second the process CPU times are
+// measured in (USER_HZ), which is 100 on all the supported architectures.
+const clockTicks = 100
+
+// BytesInMiB is the number of bytes in a mebibyte, the unit of the reported
+// resident set size.
+const BytesInMiB = 1 << 20
+
+// maxCommLength is the length limit of the process names in /proc.
+const maxCommLength = 15
+
+// ProcessSample is a resource usage sample of a process. CPU is the usage
+// since the previous sample in percent of a single CPU, it's negative in the
+// first sample. FDs is negative when the descriptors can't be listed (e.g. the
+// process belongs to another user).
+type ProcessSample struct {
+	Time    time.Time
+	CPU     float64
+	RSS     int64
+	FDs     int64
+	Threads int64
+}
+
+// ProcessUsage gathers the resource usage samples of a process given either
+// by its pid or by its name. The usage of all the processes of the same name
+// is summed up.
+type ProcessUsage struct {
+	Process string
+	// Root is the procfs mount point, it defaults to /proc
+	Root string
+
+	mutex   sync.Mutex
+	samples []ProcessSample
+
+	// CPU times of the sampled processes at the time of the previous sample
+	ticks map[string]int64
+	last  time.Time
+}
+
+// Samples returns the samples gathered in the current test.
+func (u *ProcessUsage) Samples() []ProcessSample {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+
+	return append([]ProcessSample(nil), u.samples...)
+}
+
+// Reset drops all the samples.
+func (u *ProcessUsage) Reset() {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+
+	u.samples = nil
+	u.ticks = nil
+}
+
+// Sample reads the current usage of the process from procfs. The sample is
+// skipped if no such process exists.
+func (u *ProcessUsage) Sample(now time.Time) {
+	root := u.Root
+	if len(root) == 0 {
+		root = "/proc"
+	}
+
+	sample := ProcessSample{Time: now, CPU: -1}
+	ticks := make(map[string]int64)
+
+	for _, pid := range findProcesses(root, u.Process) {
+		stat, err := readProcessStat(filepath.Join(root, pid))
+		if err != nil {
+			// The process has just exited
+			continue
+		}
+
+		ticks[pid] = stat.ticks
+		sample.RSS += stat.rss
+		sample.Threads += stat.threads
+
+		if sample.FDs >= 0 {
+			fds, err := ioutil.ReadDir(filepath.Join(root, pid, "fd"))
+			if err != nil {
+				sample.FDs = -1
+			} else {
+				sample.FDs += int64(len(fds))
+			}
+		}
+	}
+
+	if len(ticks) == 0 {
+		return
+	}
+
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+
+	if u.ticks != nil && now.After(u.last) {
+		var elapsed int64
+
+		// Only the processes present in both samples are comparable
+		for pid, t := range ticks {
+			if previous, ok := u.ticks[pid]; ok {
+				elapsed += t - previous
+			}
+		}
+
+		sample.CPU = float64(elapsed) / clockTicks / now.Sub(u.last).Seconds() * 100
+	}
+
+	u.ticks, u.last = ticks, now
+	u.samples = append(u.samples, sample)
+}
+
+// findProcesses returns the pids of the process, either the pid itself or the
+// pids of all the processes with the name.
+func findProcesses(root string, process string) []string {
+	if _, err := strconv.Atoi(process); err == nil {
+		return []string{process}
+	}
+
+	if len(process) > maxCommLength {
+		process = process[:maxCommLength]
+	}
+
+	entries, err := ioutil.ReadDir(root)
+	if err != nil {
+		return nil
+	}
+
+	var pids []string
+
+	for _, entry := range entries {
+		if _, err := strconv.Atoi(entry.Name()); err != nil {
+			continue
+		}
+
+		comm, err := ioutil.ReadFile(filepath.Join(root, entry.Name(), "comm"))
+		if err == nil && strings.TrimSpace(string(comm)) == process {
+			pids = append(pids, entry.Name())
+		}
+	}
+
+	return pids
+}
+
+// processStat groups the fields read from /proc/PID/stat.
+type processStat struct {
+	ticks   int64
+	rss     int64
+	threads int64
+}
+
+// readProcessStat reads the CPU time, the resident set size (in bytes) and the
+// number of threads of a process.
+func readProcessStat(dir string) (*processStat, error) {
+	content, err := ioutil.ReadFile(filepath.Join(dir, "stat"))
+	if err != nil {
+		//nolint:wrapcheck
+		return nil, err
+	}
+
+	// The process name may contain spaces and parentheses
+	stat := string(content)
+	fields := strings.Fields(stat[strings.LastIndex(stat, ")")+1:])
+
+	// Fields following the name, starting with the state (3rd field)
+	const (
+		utime   = 14 - 3
+		stime   = 15 - 3
+		threads = 20 - 3
+		rss     = 24 - 3
+	)
+
+	if len(fields) <= rss {
+		return nil, os.ErrInvalid
+	}
+
+	values := make(map[int]int64)
+
+	for _, i := range []int{utime, stime, threads, rss} {
+		values[i], err = strconv.ParseInt(fields[i], 10, 64)
+		if err != nil {
+			//nolint:wrapcheck
+			return nil, err
+		}
+	}
+
+	return &processStat{
+		ticks:   values[utime] + values[stime],
+		rss:     values[rss] * int64(os.Getpagesize()),
+		threads: values[threads],
+	}, nil
+}
+
+// NewProcessRecorder creates new recorder which samples the resource usage of
+// a process every interval while the test is running.
+func NewProcessRecorder(usage *ProcessUsage, interval time.Duration) bender.Recorder {
+	var (
+		cancel context.CancelFunc
+		done   chan struct{}
+	)
+
+	stop := func() {
+		if cancel != nil {
+			cancel()
+			<-done
+			cancel = nil
+		}
+	}
+
+	return func(msg interface{}) {
+		switch msg.(type) {
+		case *bender.StartEvent:
+			stop()
+			usage.Reset()
+			usage.Sample(time.Now())
+
+			var ctx context.Context
+
+			ctx, cancel = context.WithCancel(context.Background())
+			done = make(chan struct{})
+
+			go sampleProcess(ctx, done, usage, interval)
+		case *bender.EndEvent:
+			if cancel == nil {
+				return
+			}
+
+			stop()
+			usage.Sample(time.Now())
+		}
+	}
+}
+
+func sampleProcess(ctx context.Context, done chan struct{}, usage *ProcessUsage, interval time.Duration) {
+	defer close(done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			usage.Sample(now)
+		}
+	}
+}
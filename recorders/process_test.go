@@ -0,0 +1,118 @@
+/*
+Copyright (c) Facebook, Inc. and its affiliates.
+All rights reserved.
+
+This source code is licensed under the BSD-style license found in the
+LICENSE file in the root directory of this source tree.
+*/
+
+package recorders_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/facebookincubator/fbender/recorders"
+	"github.com/pinterest/bender"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeProc is a fake procfs tree.
+type fakeProc struct {
+	t    *testing.T
+	root string
+}
+
+func newFakeProc(t *testing.T) *fakeProc {
+	t.Helper()
+
+	root, err := ioutil.TempDir("", "fbender")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(root) })
+
+	return &fakeProc{t: t, root: root}
+}
+
+// process creates or updates a process, negative fds means no fd directory.
+func (p *fakeProc) process(pid int, name string, ticks, threads, pages, fds int) {
+	dir := filepath.Join(p.root, strconv.Itoa(pid))
+	require.NoError(p.t, os.MkdirAll(dir, 0o700))
+
+	stat := fmt.Sprintf("%d (%s) S 0 0 0 0 0 0 0 0 0 0 %d %d 0 0 0 0 %d 0 0 0 %d 0 0\n",
+		pid, name, ticks/2, ticks-ticks/2, threads, pages)
+	require.NoError(p.t, ioutil.WriteFile(filepath.Join(dir, "stat"), []byte(stat), 0o600))
+	require.NoError(p.t, ioutil.WriteFile(filepath.Join(dir, "comm"), []byte(name+"\n"), 0o600))
+
+	for i := 0; i < fds; i++ {
+		require.NoError(p.t, os.MkdirAll(filepath.Join(dir, "fd", strconv.Itoa(i)), 0o700))
+	}
+}
+
+func TestProcessUsage(t *testing.T) {
+	proc := newFakeProc(t)
+	proc.process(1, "init", 0, 1, 1, 3)
+	proc.process(10, "named (x)", 100, 4, 10, 5)
+	proc.process(11, "named (x)", 200, 2, 20, 1)
+
+	page := int64(os.Getpagesize())
+	now := time.Unix(1600000000, 0)
+	byName := &recorders.ProcessUsage{Process: "named (x)", Root: proc.root}
+	byPid := &recorders.ProcessUsage{Process: "11", Root: proc.root}
+	missing := &recorders.ProcessUsage{Process: "missing", Root: proc.root}
+
+	for _, usage := range []*recorders.ProcessUsage{byName, byPid, missing} {
+		usage.Sample(now)
+	}
+
+	proc.process(10, "named (x)", 150, 4, 10, 0)
+	proc.process(11, "named (x)", 300, 2, 20, 0)
+	// Processes which weren't present in the previous sample are not counted
+	proc.process(12, "named (x)", 1000, 1, 10, -1)
+
+	for _, usage := range []*recorders.ProcessUsage{byName, byPid, missing} {
+		usage.Sample(now.Add(2 * time.Second))
+	}
+
+	assert.Equal(t, []recorders.ProcessSample{
+		{Time: now, CPU: -1, RSS: 30 * page, FDs: 6, Threads: 6},
+		{Time: now.Add(2 * time.Second), CPU: 75, RSS: 40 * page, FDs: -1, Threads: 7},
+	}, byName.Samples())
+	assert.Equal(t, []recorders.ProcessSample{
+		{Time: now, CPU: -1, RSS: 20 * page, FDs: 1, Threads: 2},
+		{Time: now.Add(2 * time.Second), CPU: 50, RSS: 20 * page, FDs: 1, Threads: 2},
+	}, byPid.Samples())
+	assert.Empty(t, missing.Samples())
+
+	byName.Reset()
+	assert.Empty(t, byName.Samples())
+}
+
+func TestProcessRecorder(t *testing.T) {
+	proc := newFakeProc(t)
+	proc.process(1, "init", 0, 1, 1, 3)
+
+	usage := &recorders.ProcessUsage{Process: "init", Root: proc.root}
+	recorder := recorders.NewProcessRecorder(usage, time.Millisecond)
+
+	// Samples are taken only during the test
+	recorder(&bender.StartEvent{})
+	time.Sleep(20 * time.Millisecond)
+	recorder(&bender.EndEvent{})
+
+	samples := usage.Samples()
+	assert.Greater(t, len(samples), 2)
+
+	time.Sleep(20 * time.Millisecond)
+	assert.Len(t, usage.Samples(), len(samples))
+
+	// Every test starts over
+	recorder(&bender.StartEvent{})
+	recorder(&bender.EndEvent{})
+	assert.Len(t, usage.Samples(), 2)
+}
@@ -12,6 +12,7 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/facebookincubator/fbender/tester"
 	"github.com/gosuri/uiprogress"
 	"github.com/pinterest/bender"
 )
@@ -40,7 +41,7 @@ func NewProgressBarRecorder(bar *uiprogress.Bar) bender.Recorder {
 	return func(msg interface{}) {
 		//nolint:gocritic
 		switch msg.(type) {
-		case *bender.EndRequestEvent:
+		case *bender.EndRequestEvent, *tester.DroppedRequestEvent:
 			bar.Incr()
 		}
 	}
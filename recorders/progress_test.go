@@ -13,6 +13,7 @@ import (
 	"testing"
 
 	"github.com/facebookincubator/fbender/recorders"
+	"github.com/facebookincubator/fbender/tester"
 	"github.com/gosuri/uiprogress"
 	"github.com/pinterest/bender"
 	"github.com/stretchr/testify/suite"
@@ -70,6 +71,11 @@ func (s *ProgressBarRecorderTestSuite) TestEndRequestEvent() {
 	s.Equal(1, s.bar.Current())
 }
 
+func (s *ProgressBarRecorderTestSuite) TestDroppedRequestEvent() {
+	s.recordSingleEvent(new(tester.DroppedRequestEvent))
+	s.Equal(1, s.bar.Current())
+}
+
 func TestProgressBarRecorderTestSuite(t *testing.T) {
 	suite.Run(t, new(ProgressBarRecorderTestSuite))
 }
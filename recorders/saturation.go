@@ -0,0 +1,114 @@
+/*
+Copyright (c) Facebook, Inc. and its affiliates.
+All rights reserved.
+
+This source code is licensed under the BSD-style license found in the
+LICENSE file in the root directory of this source tree.
+*/
+
+package recorders
+
+import (
+	"time"
+
+	"github.com/facebookincubator/fbender/tester"
+	"github.com/pinterest/bender"
+)
+
+// Saturation groups statistics of how well the load generator kept up with
+// the schedule of a throughput test. The lag is how late the requests were
+// sent compared to their schedule.
+type Saturation struct {
+	Start         int64
+	Sent          int64
+	LastSent      int64
+	LastScheduled int64
+
+	Waits    int64
+	TotalLag int64
+	MaxLag   int64
+}
+
+// AchievedQPS returns the rate at which the requests were actually sent.
+func (s *Saturation) AchievedQPS() float64 {
+	return rate(s.Sent, s.LastSent-s.Start)
+}
+
+// ScheduledQPS returns the rate at which the requests were supposed to be sent.
+func (s *Saturation) ScheduledQPS() float64 {
+	return rate(s.Sent, s.LastScheduled-s.Start)
+}
+
+// AverageLag returns the average scheduling lag.
+func (s *Saturation) AverageLag() time.Duration {
+	if s.Waits == 0 {
+		return 0
+	}
+
+	return time.Duration(s.TotalLag / s.Waits)
+}
+
+// Saturated returns whether the achieved QPS fell below the scheduled QPS by
+// more than the given fraction.
+func (s *Saturation) Saturated(tolerance float64) bool {
+	return s.Sent > 1 && s.AchievedQPS() < (1-tolerance)*s.ScheduledQPS()
+}
+
+// Merge adds the statistics of another load generator running the same test at
+// the same time, e.g. on another machine. The times are taken relative to the
+// start of every generator, so their clocks don't need to be synchronized.
+func (s *Saturation) Merge(other *Saturation) {
+	s.Sent += other.Sent
+	s.Waits += other.Waits
+	s.TotalLag += other.TotalLag
+
+	if lastSent := s.Start + other.LastSent - other.Start; lastSent > s.LastSent {
+		s.LastSent = lastSent
+	}
+
+	if lastScheduled := s.Start + other.LastScheduled - other.Start; lastScheduled > s.LastScheduled {
+		s.LastScheduled = lastScheduled
+	}
+
+	if other.MaxLag > s.MaxLag {
+		s.MaxLag = other.MaxLag
+	}
+}
+
+func rate(n, elapsed int64) float64 {
+	if elapsed <= 0 {
+		return 0
+	}
+
+	return float64(n) / time.Duration(elapsed).Seconds()
+}
+
+// NewSaturationRecorder creates a new recorder which gathers the saturation
+// statistics. The lag is taken from the wait events and the send rates from
+// the scheduled requests events. The dropped requests are never sent, so they
+// are not counted.
+func NewSaturationRecorder(saturation *Saturation) bender.Recorder {
+	return func(msg interface{}) {
+		switch msg := msg.(type) {
+		case *bender.StartEvent:
+			*saturation = Saturation{Start: msg.Start}
+		case *bender.WaitEvent:
+			saturation.Waits++
+			saturation.TotalLag += msg.Overage
+
+			if msg.Overage > saturation.MaxLag {
+				saturation.MaxLag = msg.Overage
+			}
+		case *tester.ScheduledRequestEvent:
+			saturation.Sent++
+
+			if msg.Start > saturation.LastSent {
+				saturation.LastSent = msg.Start
+			}
+
+			if msg.Scheduled > saturation.LastScheduled {
+				saturation.LastScheduled = msg.Scheduled
+			}
+		}
+	}
+}
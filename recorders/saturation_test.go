@@ -0,0 +1,96 @@
+/*
+Copyright (c) Facebook, Inc. and its affiliates.
+All rights reserved.
+
+This source code is licensed under the BSD-style license found in the
+LICENSE file in the root directory of this source tree.
+*/
+
+package recorders_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/facebookincubator/fbender/recorders"
+	"github.com/facebookincubator/fbender/tester"
+	"github.com/pinterest/bender"
+	"github.com/stretchr/testify/assert"
+)
+
+func recordSaturation(events ...interface{}) *recorders.Saturation {
+	saturation := &recorders.Saturation{Sent: 42}
+	recorder := make(chan interface{}, len(events))
+
+	for _, event := range events {
+		recorder <- event
+	}
+
+	close(recorder)
+	bender.Record(recorder, recorders.NewSaturationRecorder(saturation))
+
+	return saturation
+}
+
+func TestSaturationRecorder(t *testing.T) {
+	second := int64(time.Second)
+	// 10 requests scheduled every 100ms, sent every 200ms.
+	events := []interface{}{&bender.StartEvent{Start: second}}
+
+	for i := int64(1); i <= 10; i++ {
+		scheduled, sent := second+i*second/10, second+i*second/5
+		events = append(events,
+			&bender.WaitEvent{Overage: sent - scheduled},
+			&tester.ScheduledRequestEvent{Scheduled: scheduled, Start: sent, End: sent + 1},
+		)
+	}
+
+	saturation := recordSaturation(events...)
+	assert.Equal(t, int64(10), saturation.Sent)
+	assert.InDelta(t, 10., saturation.ScheduledQPS(), 1e-9)
+	assert.InDelta(t, 5., saturation.AchievedQPS(), 1e-9)
+	assert.Equal(t, 550*time.Millisecond, saturation.AverageLag())
+	assert.Equal(t, second, saturation.MaxLag)
+	assert.True(t, saturation.Saturated(0.05))
+	assert.False(t, saturation.Saturated(0.5))
+}
+
+func TestSaturationRecorder_Dropped(t *testing.T) {
+	second := int64(time.Second)
+	saturation := recordSaturation(
+		&bender.StartEvent{Start: second},
+		&bender.WaitEvent{},
+		&tester.ScheduledRequestEvent{Scheduled: 2 * second, Start: 2 * second},
+		&bender.WaitEvent{},
+		// Dropped requests are not sent, so the rates are not affected
+		&tester.DroppedRequestEvent{Time: 5 * second},
+	)
+	assert.Equal(t, int64(1), saturation.Sent)
+	assert.InDelta(t, 1., saturation.ScheduledQPS(), 1e-9)
+	assert.InDelta(t, 1., saturation.AchievedQPS(), 1e-9)
+	assert.Equal(t, time.Duration(0), saturation.AverageLag())
+	assert.False(t, saturation.Saturated(0.05))
+}
+
+func TestSaturation__Merge(t *testing.T) {
+	second := int64(time.Second)
+	// The generators start at different times, e.g. because of their clocks
+	saturation := &recorders.Saturation{Start: 0, Sent: 10, LastSent: second, LastScheduled: second,
+		Waits: 10, TotalLag: 10, MaxLag: 2}
+	saturation.Merge(&recorders.Saturation{Start: 100 * second, Sent: 10, LastSent: 102 * second,
+		LastScheduled: 101 * second, Waits: 10, TotalLag: 30, MaxLag: 5})
+	assert.Equal(t, int64(20), saturation.Sent)
+	assert.InDelta(t, 20., saturation.ScheduledQPS(), 1e-9)
+	assert.InDelta(t, 10., saturation.AchievedQPS(), 1e-9)
+	assert.Equal(t, time.Duration(2), saturation.AverageLag())
+	assert.Equal(t, int64(5), saturation.MaxLag)
+	assert.True(t, saturation.Saturated(0.05))
+}
+
+func TestSaturation_Empty(t *testing.T) {
+	saturation := recordSaturation(&bender.StartEvent{Start: 1})
+	assert.Equal(t, 0., saturation.AchievedQPS())
+	assert.Equal(t, 0., saturation.ScheduledQPS())
+	assert.Equal(t, time.Duration(0), saturation.AverageLag())
+	assert.False(t, saturation.Saturated(0.05))
+}
@@ -0,0 +1,179 @@
+/*
+Copyright (c) Facebook, Inc. and its affiliates.
+All rights reserved.
+
+This source code is licensed under the BSD-style license found in the
+LICENSE file in the root directory of this source tree.
+*/
+
+package recorders
+
+import (
+	"sync"
+	"time"
+
+	"github.com/facebookincubator/fbender/tester"
+	"github.com/pinterest/bender"
+	"github.com/pinterest/bender/hist"
+)
+
+// Snapshot groups statistics of the requests finished within a single report
+// interval. The histogram is nil if the statistics are disabled.
+type Snapshot struct {
+	// The Unix epoch times (in nanoseconds) at which the interval starts and ends
+	Start, End int64
+	Statistics
+	Histogram *hist.Histogram
+}
+
+// ErrorsPercent returns the percentage of failed requests.
+func (s *Snapshot) ErrorsPercent() float64 {
+	if s.Requests == 0 {
+		return 0
+	}
+
+	return float64(s.Errors) / float64(s.Requests) * 100
+}
+
+// NewSnapshotRecorder creates a new recorder which gathers statistics in
+// consecutive intervals since the start of a test and passes every snapshot to
+// the report function once the interval is over. The intervals are reported by
+// a ticker, so they're reported on time even if no requests finish. The last,
+// possibly shorter, snapshot is reported when the test ends. The histogram
+// function creates a histogram for every snapshot, it may return nil.
+//nolint:funlen
+func NewSnapshotRecorder(interval time.Duration, histogram func() *hist.Histogram,
+	report func(*Snapshot)) bender.Recorder {
+	var (
+		current *Snapshot
+		mutex   sync.Mutex
+		stop    chan struct{}
+	)
+
+	start := func(t int64) {
+		current = &Snapshot{Start: t, End: t + int64(interval), Histogram: histogram()}
+		if current.Histogram != nil {
+			current.Histogram.Start(int(t))
+		}
+	}
+
+	// advance reports all the intervals which are over by the given time.
+	advance := func(t int64) {
+		for current != nil && t >= current.End {
+			if current.Histogram != nil {
+				current.Histogram.End(int(current.End))
+			}
+
+			report(current)
+			start(current.End)
+		}
+	}
+
+	// tick reports the intervals which are over until stopped.
+	tick := func(stop <-chan struct{}) {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case now := <-ticker.C:
+				mutex.Lock()
+				advance(now.UnixNano())
+				mutex.Unlock()
+			}
+		}
+	}
+
+	return func(msg interface{}) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		switch msg := msg.(type) {
+		case *bender.StartEvent:
+			start(msg.Start)
+
+			stop = make(chan struct{})
+			go tick(stop)
+		case *bender.EndRequestEvent:
+			advance(msg.End)
+
+			if current == nil {
+				return
+			}
+
+			current.Requests++
+
+			elapsed := int(msg.End - msg.Start)
+			if msg.Err != nil {
+				current.Errors++
+			}
+
+			if current.Histogram == nil {
+				return
+			}
+
+			if msg.Err == nil {
+				current.Histogram.Add(elapsed)
+			} else {
+				current.Histogram.AddError(elapsed)
+			}
+		case *tester.DroppedRequestEvent:
+			advance(msg.Time)
+
+			if current != nil {
+				current.Requests++
+				current.Errors++
+				current.Dropped++
+			}
+		case *bender.EndEvent:
+			advance(msg.End)
+
+			if current != nil && current.Requests > 0 {
+				current.End = msg.End
+				if current.Histogram != nil {
+					current.Histogram.End(int(msg.End))
+				}
+
+				report(current)
+			}
+
+			current = nil
+
+			if stop != nil {
+				close(stop)
+				stop = nil
+			}
+		}
+	}
+}
+
+// Trend fits a line to the values of consecutive snapshots using the least
+// squares method and returns the fitted values for the first and the last
+// snapshot.
+func Trend(values []float64) (float64, float64) {
+	switch len(values) {
+	case 0:
+		return 0, 0
+	case 1:
+		return values[0], values[0]
+	}
+
+	n := float64(len(values))
+
+	var sumX, sumY, sumXY, sumXX float64
+
+	for i, y := range values {
+		x := float64(i)
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumXX += x * x
+	}
+
+	slope := (n*sumXY - sumX*sumY) / (n*sumXX - sumX*sumX)
+	intercept := (sumY - slope*sumX) / n
+
+	return intercept, intercept + slope*(n-1)
+}
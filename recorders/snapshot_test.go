@@ -0,0 +1,103 @@
+/*
+Copyright (c) Facebook, Inc. and its affiliates.
+All rights reserved.
+
+This source code is licensed under the BSD-style license found in the
+LICENSE file in the root directory of this source tree.
+*/
+
+package recorders_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/facebookincubator/fbender/recorders"
+	"github.com/facebookincubator/fbender/tester"
+	"github.com/pinterest/bender"
+	"github.com/pinterest/bender/hist"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshotRecorder(t *testing.T) {
+	const second = int64(time.Second)
+
+	snapshots := []*recorders.Snapshot{}
+	recorder := make(chan interface{}, 10)
+	recorder <- &bender.StartEvent{Start: 0}
+	recorder <- &bender.EndRequestEvent{Start: 0, End: second / 2}
+	recorder <- &bender.EndRequestEvent{Start: second / 2, End: second, Err: assert.AnError}
+	recorder <- &tester.DroppedRequestEvent{Time: second + 1}
+	// No requests finished in the third interval
+	recorder <- &bender.EndRequestEvent{Start: 3 * second, End: 3*second + 1}
+	recorder <- &bender.EndEvent{Start: 0, End: 3*second + 2}
+	close(recorder)
+
+	bender.Record(recorder, recorders.NewSnapshotRecorder(time.Second, func() *hist.Histogram {
+		return hist.NewHistogram(1000, int(time.Millisecond))
+	}, func(snapshot *recorders.Snapshot) {
+		snapshots = append(snapshots, snapshot)
+	}))
+
+	require.Len(t, snapshots, 4)
+
+	assert.Equal(t, int64(0), snapshots[0].Start)
+	assert.Equal(t, second, snapshots[0].End)
+	assert.Equal(t, int64(1), snapshots[0].Requests)
+	assert.Equal(t, int64(0), snapshots[0].Errors)
+	assert.Equal(t, []int{500}, snapshots[0].Histogram.Percentiles(0.5))
+
+	assert.Equal(t, int64(2), snapshots[1].Requests)
+	assert.Equal(t, int64(2), snapshots[1].Errors)
+	assert.Equal(t, int64(1), snapshots[1].Dropped)
+	assert.InDelta(t, 100, snapshots[1].ErrorsPercent(), 1e-9)
+
+	assert.Equal(t, int64(0), snapshots[2].Requests)
+	assert.InDelta(t, 0, snapshots[2].ErrorsPercent(), 1e-9)
+
+	assert.Equal(t, 3*second, snapshots[3].Start)
+	assert.Equal(t, 3*second+2, snapshots[3].End)
+	assert.Equal(t, int64(1), snapshots[3].Requests)
+}
+
+func TestSnapshotRecorder__Ticker(t *testing.T) {
+	const interval = 10 * time.Millisecond
+
+	snapshots := make(chan *recorders.Snapshot, 10)
+	recorder := recorders.NewSnapshotRecorder(interval, func() *hist.Histogram {
+		return nil
+	}, func(snapshot *recorders.Snapshot) {
+		snapshots <- snapshot
+	})
+
+	start := time.Now().UnixNano()
+	recorder(&bender.StartEvent{Start: start})
+
+	// The intervals are reported even though no requests finish
+	for i := int64(0); i < 2; i++ {
+		select {
+		case snapshot := <-snapshots:
+			assert.Equal(t, start+i*int64(interval), snapshot.Start)
+			assert.Equal(t, int64(0), snapshot.Requests)
+		case <-time.After(time.Second):
+			require.Fail(t, "Expected an interim snapshot")
+		}
+	}
+
+	recorder(&bender.EndEvent{Start: start, End: time.Now().UnixNano()})
+}
+
+func TestTrend(t *testing.T) {
+	first, last := recorders.Trend([]float64{1, 3, 2, 4})
+	assert.InDelta(t, 1.3, first, 1e-9)
+	assert.InDelta(t, 3.7, last, 1e-9)
+
+	first, last = recorders.Trend([]float64{5})
+	assert.InDelta(t, 5, first, 1e-9)
+	assert.InDelta(t, 5, last, 1e-9)
+
+	first, last = recorders.Trend(nil)
+	assert.InDelta(t, 0, first, 1e-9)
+	assert.InDelta(t, 0, last, 1e-9)
+}
@@ -11,19 +11,23 @@ package recorders
 import (
 	"sync/atomic"
 
+	"github.com/facebookincubator/fbender/tester"
 	"github.com/pinterest/bender"
 )
 
-// Statistics groups statistics gathered by statistics recoreder.
+// Statistics groups statistics gathered by statistics recoreder. Dropped
+// requests are counted as failed requests as well.
 type Statistics struct {
 	Requests int64
 	Errors   int64
+	Dropped  int64
 }
 
 // Reset zeroes statistics.
 func (s *Statistics) Reset() {
 	atomic.StoreInt64(&s.Requests, 0)
 	atomic.StoreInt64(&s.Errors, 0)
+	atomic.StoreInt64(&s.Dropped, 0)
 }
 
 // NewStatisticsRecorder creates new recorder which gathers statistics.
@@ -38,6 +42,10 @@ func NewStatisticsRecorder(statistics *Statistics) bender.Recorder {
 			if msg.Err != nil {
 				atomic.AddInt64(&statistics.Errors, 1)
 			}
+		case *tester.DroppedRequestEvent:
+			atomic.AddInt64(&statistics.Requests, 1)
+			atomic.AddInt64(&statistics.Errors, 1)
+			atomic.AddInt64(&statistics.Dropped, 1)
 		}
 	}
 }
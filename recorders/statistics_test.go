@@ -12,6 +12,7 @@ import (
 	"testing"
 
 	"github.com/facebookincubator/fbender/recorders"
+	"github.com/facebookincubator/fbender/tester"
 	"github.com/pinterest/bender"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
@@ -39,9 +40,11 @@ func (s *StatisticsRecorderTestSuite) recordSingleEvent(event interface{}) {
 func (s *StatisticsRecorderTestSuite) TestStartEvent() {
 	s.statistics.Requests = 42
 	s.statistics.Errors = 6
+	s.statistics.Dropped = 3
 	s.recordSingleEvent(new(bender.StartEvent))
 	s.Equal(int64(0), s.statistics.Requests)
 	s.Equal(int64(0), s.statistics.Errors)
+	s.Equal(int64(0), s.statistics.Dropped)
 }
 
 func (s *StatisticsRecorderTestSuite) TestEndEvent() {
@@ -74,6 +77,13 @@ func (s *StatisticsRecorderTestSuite) TestEndRequestEvent_Error() {
 	s.Equal(int64(1), s.statistics.Errors)
 }
 
+func (s *StatisticsRecorderTestSuite) TestDroppedRequestEvent() {
+	s.recordSingleEvent(new(tester.DroppedRequestEvent))
+	s.Equal(int64(1), s.statistics.Requests)
+	s.Equal(int64(1), s.statistics.Errors)
+	s.Equal(int64(1), s.statistics.Dropped)
+}
+
 func TestStatisticsRecorderTestSuite(t *testing.T) {
 	suite.Run(t, new(StatisticsRecorderTestSuite))
 }
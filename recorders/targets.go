@@ -0,0 +1,79 @@
+/*
+Copyright (c) Facebook, Inc. and its affiliates.
+All rights reserved.
+
+This source code is licensed under the BSD-style license found in the
+LICENSE file in the root directory of this source tree.
+*/
+
+package recorders
+
+import (
+	"sync/atomic"
+
+	"github.com/facebookincubator/fbender/tester"
+	"github.com/pinterest/bender"
+	"github.com/pinterest/bender/hist"
+)
+
+// TargetStatistics groups statistics gathered for a single target. The
+// histogram is optional.
+type TargetStatistics struct {
+	Statistics
+	Histogram *hist.Histogram
+}
+
+// NewTargetsRecorder creates new recorder which gathers statistics for every
+// target of a multi target test. It relies on the responses being wrapped in
+// tester.TargetedResponse, responses of unknown targets are ignored.
+func NewTargetsRecorder(targets map[string]*TargetStatistics) bender.Recorder {
+	return func(msg interface{}) {
+		switch msg := msg.(type) {
+		case *bender.StartEvent:
+			for _, statistics := range targets {
+				statistics.Reset()
+
+				if statistics.Histogram != nil {
+					statistics.Histogram.Start(int(msg.Start))
+				}
+			}
+		case *bender.EndEvent:
+			for _, statistics := range targets {
+				if statistics.Histogram != nil {
+					statistics.Histogram.End(int(msg.End))
+				}
+			}
+		case *bender.EndRequestEvent:
+			recordTargetedRequest(targets, msg)
+		}
+	}
+}
+
+func recordTargetedRequest(targets map[string]*TargetStatistics, msg *bender.EndRequestEvent) {
+	response, ok := msg.Response.(*tester.TargetedResponse)
+	if !ok {
+		return
+	}
+
+	statistics, ok := targets[response.Target]
+	if !ok {
+		return
+	}
+
+	atomic.AddInt64(&statistics.Requests, 1)
+
+	if msg.Err != nil {
+		atomic.AddInt64(&statistics.Errors, 1)
+	}
+
+	if statistics.Histogram == nil {
+		return
+	}
+
+	elapsed := int(msg.End - msg.Start)
+	if msg.Err == nil {
+		statistics.Histogram.Add(elapsed)
+	} else {
+		statistics.Histogram.AddError(elapsed)
+	}
+}
@@ -0,0 +1,40 @@
+/*
+Copyright (c) Facebook, Inc. and its affiliates.
+All rights reserved.
+
+This source code is licensed under the BSD-style license found in the
+LICENSE file in the root directory of this source tree.
+*/
+
+package recorders_test
+
+import (
+	"testing"
+
+	"github.com/facebookincubator/fbender/recorders"
+	"github.com/facebookincubator/fbender/tester"
+	"github.com/pinterest/bender"
+	"github.com/pinterest/bender/hist"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTargetsRecorder(t *testing.T) {
+	targets := map[string]*recorders.TargetStatistics{
+		"a": {Histogram: hist.NewHistogram(100, 1)},
+		"b": {Statistics: recorders.Statistics{Requests: 42, Errors: 6}},
+	}
+	recorder := make(chan interface{}, 6)
+	recorder <- &bender.StartEvent{Start: 0}
+	recorder <- &bender.EndRequestEvent{Start: 0, End: 10, Response: &tester.TargetedResponse{Target: "a"}}
+	recorder <- &bender.EndRequestEvent{Start: 0, End: 30, Response: &tester.TargetedResponse{Target: "b"}, Err: assert.AnError}
+	// Unknown targets and responses are ignored
+	recorder <- &bender.EndRequestEvent{Start: 0, End: 30, Response: &tester.TargetedResponse{Target: "c"}}
+	recorder <- &bender.EndRequestEvent{Start: 0, End: 30, Response: "response"}
+	recorder <- &bender.EndEvent{End: 100}
+	close(recorder)
+	bender.Record(recorder, recorders.NewTargetsRecorder(targets))
+
+	assert.Equal(t, recorders.Statistics{Requests: 1, Errors: 0}, targets["a"].Statistics)
+	assert.Equal(t, []int{10, 10}, targets["a"].Histogram.Percentiles(0, 1))
+	assert.Equal(t, recorders.Statistics{Requests: 1, Errors: 1}, targets["b"].Statistics)
+}
@@ -34,15 +34,23 @@ func (c *comparator) Name() string {
 // Available comparators.
 //nolint:gochecknoglobals
 var (
-	LessThan    Comparator = &comparator{repr: "<", cmp: func(x, y float64) bool { return x < y }}
-	GreaterThan            = &comparator{repr: ">", cmp: func(x, y float64) bool { return x > y }}
+	LessThan       Comparator = &comparator{repr: "<", cmp: func(x, y float64) bool { return x < y }}
+	GreaterThan               = &comparator{repr: ">", cmp: func(x, y float64) bool { return x > y }}
+	LessOrEqual               = &comparator{repr: "<=", cmp: func(x, y float64) bool { return x <= y }}
+	GreaterOrEqual            = &comparator{repr: ">=", cmp: func(x, y float64) bool { return x >= y }}
+	EqualTo                   = &comparator{repr: "==", cmp: func(x, y float64) bool { return x == y }}
+	NotEqualTo                = &comparator{repr: "!=", cmp: func(x, y float64) bool { return x != y }}
 )
 
 // Comparators is a map of comparators representation to the actual comparator.
 //nolint:gochecknoglobals
 var Comparators = map[string]Comparator{
-	LessThan.Name():    LessThan,
-	GreaterThan.Name(): GreaterThan,
+	LessThan.Name():       LessThan,
+	GreaterThan.Name():    GreaterThan,
+	LessOrEqual.Name():    LessOrEqual,
+	GreaterOrEqual.Name(): GreaterOrEqual,
+	EqualTo.Name():        EqualTo,
+	NotEqualTo.Name():     NotEqualTo,
 }
 
 // ErrInvalidComparator is returned when a comparator cannot be found.
@@ -57,6 +57,32 @@ func TestParseComparator_GreaterThan(t *testing.T) {
 	assertPointerEqual(t, tester.GreaterThan, cmp)
 }
 
+func TestComparators__Compare(t *testing.T) {
+	tests := []struct {
+		comparator tester.Comparator
+		name       string
+		less       bool
+		equal      bool
+		greater    bool
+	}{
+		{tester.LessOrEqual, "<=", true, true, false},
+		{tester.GreaterOrEqual, ">=", false, true, true},
+		{tester.EqualTo, "==", false, true, false},
+		{tester.NotEqualTo, "!=", true, false, true},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.name, test.comparator.Name())
+		assert.Equal(t, test.less, test.comparator.Compare(1, 2), "1 %s 2", test.name)
+		assert.Equal(t, test.equal, test.comparator.Compare(2, 2), "2 %s 2", test.name)
+		assert.Equal(t, test.greater, test.comparator.Compare(3, 2), "3 %s 2", test.name)
+
+		cmp, err := tester.ParseComparator(test.name)
+		assert.NoError(t, err)
+		assertPointerEqual(t, test.comparator, cmp)
+	}
+}
+
 func TestParseComparator(t *testing.T) {
 	cmp, err := tester.ParseComparator("!")
 	assert.Nil(t, cmp)
@@ -18,19 +18,64 @@ import (
 )
 
 // Constraint represents a constraint tests should meet to be considered
-// successful.
+// successful. A compound constraint combines its operands with the operator
+// instead of checking a metric.
 type Constraint struct {
 	Metric     Metric
 	Aggregator Aggregator
 	Comparator Comparator
 	Threshold  float64
+
+	Operator Operator
+	Operands []*Constraint
 }
 
 func (c *Constraint) String() string {
+	if c.Operator != nil {
+		return c.Operator.String(c.Operands...)
+	}
+
 	return fmt.Sprintf("%s(%s) %s %.2f",
 		c.Aggregator.Name(), c.Metric.Name(), c.Comparator.Name(), c.Threshold)
 }
 
+// Setup sets up the metrics of the constraint.
+func (c *Constraint) Setup(options interface{}) error {
+	for _, constraint := range c.Simple() {
+		if err := constraint.Metric.Setup(options); err != nil {
+			//nolint:wrapcheck
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Simple returns the simple constraints the constraint consists of, the
+// constraint itself if it's not compound.
+func (c *Constraint) Simple() []*Constraint {
+	if c.Operator == nil {
+		return []*Constraint{c}
+	}
+
+	simple := make([]*Constraint, 0, len(c.Operands))
+	for _, operand := range c.Operands {
+		simple = append(simple, operand.Simple()...)
+	}
+
+	return simple
+}
+
+// Evaluate checks the constraint using the given check of the simple
+// constraints.
+func (c *Constraint) Evaluate(check func(*Constraint) error) error {
+	if c.Operator == nil {
+		return check(c)
+	}
+
+	return c.Operator.Evaluate(check, c.Operands...)
+}
+
 // ErrNoDataPoints is raised when no data points are found.
 var ErrNoDataPoints = errors.New("no data points")
 
@@ -39,12 +84,20 @@ var ErrNotSatisfied = errors.New("unsatisfied condition")
 
 // Check fetches metric and checks if the constraint has been satisfied.
 func (c *Constraint) Check(start time.Time, duration time.Duration) error {
-	points, err := c.Metric.Fetch(start, duration)
-	if err != nil {
-		//nolint:wrapcheck
-		return err
-	}
+	return c.Evaluate(func(c *Constraint) error {
+		points, err := c.Metric.Fetch(start, duration)
+		if err != nil {
+			//nolint:wrapcheck
+			return err
+		}
 
+		return c.CheckPoints(points)
+	})
+}
+
+// CheckPoints checks if a simple constraint is satisfied by already fetched
+// data points, e.g. gathered in multiple trials of a test.
+func (c *Constraint) CheckPoints(points []DataPoint) error {
 	if points == nil {
 		return ErrNoDataPoints
 	}
@@ -60,16 +113,26 @@ func (c *Constraint) Check(start time.Time, duration time.Duration) error {
 // ConstraintsHelp is an help message on how to use constraints.
 const ConstraintsHelp = `
 Constraints follow the syntax:
+  Expression ::= <Expression> "OR" <Expression> | <Expression> "AND" <Expression> |
+                 "NOT" <Expression> | "(" <Expression> ")" | <Constraint>
   Constraint ::= <Aggregator>(<Metric>)<Cmp><Threshold>
-  Aggregator ::= "MIN" | "MAX"
+  Aggregator ::= "MIN" | "MAX" | "AVG" | "MEDIAN" | "STDDEV" | "COUNT" | "SUM" |
+                 "P50" | "P90" | "P99" | "P999" | "P(" <float> ")"
   Metric     ::= <string>
-  Cmp        ::= "<" | ">"
+  Cmp        ::= "<" | ">" | "<=" | ">=" | "==" | "!="
   Threshold  ::= <float>
 
+NOT binds tighter than AND, which binds tighter than OR. Multiple constraints
+must all be satisfied.
+
 Constraints examples:
   MIN(metric) < 20.5
   MAX(metric) > 0.45
   MIN(metric) < 123
+  P99(metric) < 20
+  P(99.5)(metric) < 25
+  P99(metric) < 50 OR AVG(other) <= 0.1
+  NOT (MAX(metric) == 0) AND (MIN(other) > 1 OR MIN(another) > 1)
 
 ` + GrowthHelp
 
@@ -87,8 +150,8 @@ type MetricParser func(string) (Metric, error)
 
 // Named capture groups of the constraints matching regexp.
 const (
-	aggregatorMatch = `(?P<aggregator>\w+)`
-	metricMatch     = `(?P<metric>\S+)`
+	aggregatorMatch = `(?P<aggregator>P\([^()]*\)|\w+)`
+	metricMatch     = `(?P<metric>\S.*)`
 	comparatorMatch = `(?P<comparator>[<>=~!@#$%^&?]+)`
 	thresholdMatch  = `(?P<threshold>[-+]?\d*\.?\d+)`
 )
@@ -101,8 +164,9 @@ var constraintRegexp = utils.MustCompile(
 	),
 )
 
-// ParseConstraint creates a constraint from a string representation.
-func ParseConstraint(s string, parsers ...MetricParser) (*Constraint, error) {
+// parseSimpleConstraint creates a simple constraint from a string
+// representation.
+func parseSimpleConstraint(s string, parsers ...MetricParser) (*Constraint, error) {
 	if !constraintRegexp.MatchString(s) {
 		return nil, ErrInvalidFormat
 	}
@@ -79,6 +79,18 @@ func (s *ParseConstraintTestSuite) TestConstructor() {
 	c, err = tester.ParseConstraint("MAX(errors) < 20", s.parsers...)
 	s.Assert().NotNil(c)
 	s.Assert().NoError(err)
+	// Valid constraint - P99.
+	c, err = tester.ParseConstraint("P99(latency) < 20", s.parsers...)
+	s.Assert().NoError(err)
+	s.Assert().Equal("P99(latency) < 20.00", c.String())
+	// Valid constraint - generic percentile.
+	c, err = tester.ParseConstraint("P(99.5)(latency) < 25", s.parsers...)
+	s.Assert().NoError(err)
+	s.Assert().Equal("P(99.5)(latency) < 25.00", c.String())
+	// Invalid percentile.
+	c, err = tester.ParseConstraint("P(200)(latency) < 25", s.parsers...)
+	s.Assert().Nil(c)
+	s.Assert().ErrorIs(err, tester.ErrInvalidAggregator)
 	// Invalid metric '0xdeadbeef'.
 	c, err = tester.ParseConstraint("MAX(0xdeadbeef) < 10", s.parsers...)
 	s.Assert().Nil(c)
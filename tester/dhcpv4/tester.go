@@ -9,11 +9,13 @@ LICENSE file in the root directory of this source tree.
 package dhcpv4
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net"
 	"time"
 
+	"github.com/facebookincubator/fbender/tester"
 	"github.com/insomniacslk/dhcp/dhcpv4"
 	"github.com/insomniacslk/dhcp/dhcpv4/async"
 	"github.com/pinterest/bender"
@@ -68,9 +70,22 @@ func validator(req, res *dhcpv4.DHCPv4) error {
 	return nil
 }
 
+// ErrTimeout is raised when no response is received before the timeout.
+var ErrTimeout = tester.ErrTimeout
+
 // RequestExecutor returns a request executor.
-func (t *Tester) RequestExecutor(_ interface{}) (bender.RequestExecutor, error) {
-	return protocol.CreateExecutor(t.client, validator)
+func (t *Tester) RequestExecutor(_ context.Context, _ interface{}) (bender.RequestExecutor, error) {
+	executor, err := protocol.CreateExecutor(t.client, validator)
+	if err != nil {
+		//nolint:wrapcheck
+		return nil, err
+	}
+
+	return func(n int64, request interface{}) (interface{}, error) {
+		response, err := executor(n, request)
+
+		return response, tester.WrapPlainTimeout(err, t.Timeout)
+	}, nil
 }
 
 // ErrNoAddress is raised when an interface has no ipv4 addresses assigned.
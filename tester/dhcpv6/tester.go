@@ -9,10 +9,12 @@ LICENSE file in the root directory of this source tree.
 package dhcpv6
 
 import (
+	"context"
 	"fmt"
 	"net"
 	"time"
 
+	"github.com/facebookincubator/fbender/tester"
 	"github.com/insomniacslk/dhcp/dhcpv6"
 	"github.com/insomniacslk/dhcp/dhcpv6/async"
 	"github.com/pinterest/bender"
@@ -67,7 +69,16 @@ func validator(req, res dhcpv6.DHCPv6) error {
 	return nil
 }
 
+// ErrTimeout is raised when no response is received before the timeout.
+var ErrTimeout = tester.ErrTimeout
+
 // RequestExecutor returns a request executor.
-func (t *Tester) RequestExecutor(_ interface{}) (bender.RequestExecutor, error) {
-	return protocol.CreateExecutor(t.client, validator), nil
+func (t *Tester) RequestExecutor(_ context.Context, _ interface{}) (bender.RequestExecutor, error) {
+	executor := protocol.CreateExecutor(t.client, validator)
+
+	return func(n int64, request interface{}) (interface{}, error) {
+		response, err := executor(n, request)
+
+		return response, tester.WrapPlainTimeout(err, t.Timeout)
+	}, nil
 }
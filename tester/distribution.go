@@ -0,0 +1,151 @@
+/*
+Copyright (c) Facebook, Inc. and its affiliates.
+All rights reserved.
+
+This source code is licensed under the BSD-style license found in the
+LICENSE file in the root directory of this source tree.
+*/
+
+package tester
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/pinterest/bender"
+)
+
+// BurstDistribution sends the requests in bursts of size requests spread
+// evenly over the spread duration (zero sends them back-to-back). Zero period
+// makes every cycle a single burst, the cycle is then size / QPS. Otherwise a
+// cycle holds the QPS * period requests (at least one) sent in bursts of size
+// requests starting evenly across the cycle, the last burst of the cycle may be
+// smaller. The cycle is adjusted to keep the average QPS. Bursts which don't
+// fit between their starts at the given QPS are compressed, so the QPS is never
+// exceeded.
+func BurstDistribution(size int, spread, period time.Duration) func(float64) bender.IntervalGenerator {
+	return func(qps float64) bender.IntervalGenerator {
+		requests := size
+		if period > 0 {
+			requests = int(math.Max(math.Round(qps*period.Seconds()), 1))
+		}
+
+		cycle := float64(requests) / qps * float64(time.Second)
+		slot := cycle / math.Ceil(float64(requests)/float64(size))
+
+		within := float64(spread) / float64(size)
+		if within*float64(size-1) > slot {
+			within = slot / float64(size)
+		}
+
+		// offset returns the time of the k-th request since the cycle start
+		offset := func(k int) float64 {
+			return float64(k/size)*slot + float64(k%size)*within
+		}
+
+		i := 0
+
+		return func(_ int64) int64 {
+			defer func() { i++ }()
+
+			k := i % requests
+			if k == 0 {
+				return int64(cycle - offset(requests-1))
+			}
+
+			return int64(offset(k)) - int64(offset(k-1))
+		}
+	}
+}
+
+// ParetoDistribution draws heavy-tailed intervals from the Pareto distribution
+// with the given shape (alpha > 1), the scale keeps the average QPS.
+func ParetoDistribution(alpha float64) func(float64) bender.IntervalGenerator {
+	return func(qps float64) bender.IntervalGenerator {
+		scale := (alpha - 1) / (alpha * qps) * float64(time.Second)
+
+		return func(_ int64) int64 {
+			//nolint:gosec
+			return int64(scale / math.Pow(1-rand.Float64(), 1/alpha))
+		}
+	}
+}
+
+// SinusoidalDistribution modulates the QPS with a sine wave of the given period
+// and relative amplitude (0 <= amplitude < 1) around the average QPS.
+func SinusoidalDistribution(period time.Duration, amplitude float64) func(float64) bender.IntervalGenerator {
+	return func(qps float64) bender.IntervalGenerator {
+		elapsed := 0.
+
+		return func(_ int64) int64 {
+			rate := qps * (1 + amplitude*math.Sin(2*math.Pi*elapsed/float64(period)))
+			interval := float64(time.Second) / rate
+			elapsed += interval
+
+			return int64(interval)
+		}
+	}
+}
+
+// EmpiricalDistribution draws the intervals at random from the given samples,
+// they are rescaled so their average matches the average QPS.
+func EmpiricalDistribution(samples []time.Duration) func(float64) bender.IntervalGenerator {
+	sum := 0.
+	for _, sample := range samples {
+		sum += float64(sample)
+	}
+
+	mean := sum / float64(len(samples))
+
+	return func(qps float64) bender.IntervalGenerator {
+		factor := float64(time.Second) / qps / mean
+
+		return func(_ int64) int64 {
+			//nolint:gosec
+			return int64(float64(samples[rand.Intn(len(samples))]) * factor)
+		}
+	}
+}
+
+// ErrInvalidIntervals is returned when the intervals cannot be parsed.
+var ErrInvalidIntervals = errors.New("invalid intervals")
+
+// ParseIntervals reads intervals, one per line, either as durations (250ms) or
+// in seconds (0.25). Empty lines and lines starting with # are skipped.
+func ParseIntervals(r io.Reader) ([]time.Duration, error) {
+	intervals := []time.Duration{}
+	scanner := bufio.NewScanner(r)
+	sum := time.Duration(0)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		interval, err := parseOffset(line)
+		if err != nil || interval < 0 {
+			return nil, fmt.Errorf("%w, want: non-negative duration, got: %q", ErrInvalidIntervals, line)
+		}
+
+		intervals = append(intervals, interval)
+		sum += interval
+	}
+
+	if err := scanner.Err(); err != nil {
+		//nolint:wrapcheck
+		return nil, err
+	}
+
+	if sum <= 0 {
+		return nil, fmt.Errorf("%w, at least one positive interval is required", ErrInvalidIntervals)
+	}
+
+	return intervals, nil
+}
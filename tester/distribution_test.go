@@ -0,0 +1,140 @@
+/*
+Copyright (c) Facebook, Inc. and its affiliates.
+All rights reserved.
+
+This source code is licensed under the BSD-style license found in the
+LICENSE file in the root directory of this source tree.
+*/
+
+package tester_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/facebookincubator/fbender/tester"
+	"github.com/pinterest/bender"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// meanInterval returns the average of n intervals drawn from the generator.
+func meanInterval(generator bender.IntervalGenerator, n int) time.Duration {
+	sum := int64(0)
+	for i := 0; i < n; i++ {
+		sum += generator(0)
+	}
+
+	return time.Duration(sum / int64(n))
+}
+
+func TestBurstDistribution(t *testing.T) {
+	generator := tester.BurstDistribution(4, 30*time.Millisecond, 0)(100)
+	// A burst of 4 requests every 40ms, spread over 30ms.
+	for i := 0; i < 2; i++ {
+		assert.Equal(t, int64(17500*time.Microsecond), generator(0))
+
+		for j := 0; j < 3; j++ {
+			assert.Equal(t, int64(7500*time.Microsecond), generator(0))
+		}
+	}
+
+	// Back-to-back bursts.
+	generator = tester.BurstDistribution(5, 0, 0)(10)
+	assert.Equal(t, []int64{int64(500 * time.Millisecond), 0, 0, 0, 0, int64(500 * time.Millisecond)},
+		[]int64{generator(0), generator(0), generator(0), generator(0), generator(0), generator(0)})
+
+	// Bursts which don't fit in the 20ms cycle are compressed to keep the QPS.
+	generator = tester.BurstDistribution(2, 100*time.Millisecond, 0)(100)
+	assert.Equal(t, []int64{int64(10 * time.Millisecond), int64(10 * time.Millisecond)},
+		[]int64{generator(0), generator(0)})
+}
+
+func TestBurstDistribution__Period(t *testing.T) {
+	// 6 requests every 100ms in 3 bursts of 2 requests spread over 10ms.
+	generator := tester.BurstDistribution(2, 10*time.Millisecond, 100*time.Millisecond)(60)
+	for i := 0; i < 2; i++ {
+		for j := 0; j < 3; j++ {
+			assert.InDelta(t, float64(28333333), float64(generator(0)), 1)
+			assert.Equal(t, int64(5*time.Millisecond), generator(0))
+		}
+	}
+
+	// The average QPS is kept.
+	assert.InDelta(t, float64(time.Second/60), float64(meanInterval(generator, 6000)), float64(time.Microsecond))
+
+	// 1000 requests every second in 20 back-to-back bursts of 50 requests.
+	generator = tester.BurstDistribution(50, 0, time.Second)(1000)
+	for i := 0; i < 20; i++ {
+		assert.Equal(t, int64(50*time.Millisecond), generator(0))
+
+		for j := 0; j < 49; j++ {
+			assert.Equal(t, int64(0), generator(0))
+		}
+	}
+
+	// The last burst of the cycle is smaller.
+	generator = tester.BurstDistribution(4, 0, time.Second)(6)
+	assert.Equal(t, []int64{int64(500 * time.Millisecond), 0, 0, 0, int64(500 * time.Millisecond), 0},
+		[]int64{generator(0), generator(0), generator(0), generator(0), generator(0), generator(0)})
+
+	// Cycles shorter than a request interval are stretched to keep the QPS.
+	generator = tester.BurstDistribution(1, 0, 100*time.Millisecond)(3)
+	assert.Equal(t, int64(time.Second/3), generator(0))
+	assert.Equal(t, int64(time.Second/3), generator(0))
+}
+
+func TestParetoDistribution(t *testing.T) {
+	generator := tester.ParetoDistribution(3)(100)
+	// The scale is the minimum interval.
+	for i := 0; i < 1000; i++ {
+		assert.GreaterOrEqual(t, generator(0), int64(6666666))
+	}
+
+	assert.InDelta(t, float64(10*time.Millisecond), float64(meanInterval(generator, 100000)), float64(time.Millisecond))
+}
+
+func TestSinusoidalDistribution(t *testing.T) {
+	generator := tester.SinusoidalDistribution(time.Second, 0.5)(100)
+	// The QPS starts at the average and rises during the first half period.
+	assert.Equal(t, int64(10*time.Millisecond), generator(0))
+
+	elapsed, fastest, slowest := 10*time.Millisecond, time.Hour, time.Duration(0)
+	for elapsed < 10*time.Second {
+		interval := time.Duration(generator(0))
+		elapsed += interval
+
+		if interval < fastest {
+			fastest = interval
+		}
+
+		if interval > slowest {
+			slowest = interval
+		}
+	}
+
+	assert.InDelta(t, float64(time.Second/150), float64(fastest), float64(50*time.Microsecond))
+	assert.InDelta(t, float64(time.Second/50), float64(slowest), float64(time.Millisecond))
+}
+
+func TestEmpiricalDistribution(t *testing.T) {
+	intervals, err := tester.ParseIntervals(strings.NewReader("# intervals\n10ms\n\n0.03\n"))
+	require.NoError(t, err)
+	assert.Equal(t, []time.Duration{10 * time.Millisecond, 30 * time.Millisecond}, intervals)
+
+	generator := tester.EmpiricalDistribution(intervals)(10)
+	for i := 0; i < 100; i++ {
+		interval := generator(0)
+		assert.Contains(t, []int64{int64(50 * time.Millisecond), int64(150 * time.Millisecond)}, interval)
+	}
+
+	assert.InDelta(t, float64(100*time.Millisecond), float64(meanInterval(generator, 10000)), float64(5*time.Millisecond))
+
+	_, err = tester.ParseIntervals(strings.NewReader("10ms\n-1s\n"))
+	assert.ErrorIs(t, err, tester.ErrInvalidIntervals)
+	assert.EqualError(t, err, "invalid intervals, want: non-negative duration, got: \"-1s\"")
+
+	_, err = tester.ParseIntervals(strings.NewReader("0\n"))
+	assert.ErrorIs(t, err, tester.ErrInvalidIntervals)
+}
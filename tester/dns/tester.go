@@ -9,10 +9,12 @@ LICENSE file in the root directory of this source tree.
 package dns
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"time"
 
+	"github.com/facebookincubator/fbender/tester"
 	"github.com/miekg/dns"
 	"github.com/pinterest/bender"
 	protocol "github.com/pinterest/bender/dns"
@@ -36,7 +38,10 @@ type Tester struct {
 var ErrInvalidRequest = errors.New("invalid request")
 
 // ErrInvalidResponse is raised when the response is invalid.
-var ErrInvalidResponse = errors.New("invalid response")
+var ErrInvalidResponse = tester.ErrInvalidResponse
+
+// ErrUnexpectedRcode is raised when the response rcode isn't the expected one.
+var ErrUnexpectedRcode = tester.ErrUnexpectedStatus
 
 // Before is called before the first test.
 func (t *Tester) Before(options interface{}) error {
@@ -71,7 +76,7 @@ func validator(request, response *dns.Msg) error {
 }
 
 // RequestExecutor returns a request executor.
-func (t *Tester) RequestExecutor(options interface{}) (bender.RequestExecutor, error) {
+func (t *Tester) RequestExecutor(_ context.Context, options interface{}) (bender.RequestExecutor, error) {
 	innerExecutor := protocol.CreateExecutor(t.client, validator, t.Target)
 
 	return func(n int64, request interface{}) (interface{}, error) {
@@ -92,7 +97,7 @@ func (t *Tester) RequestExecutor(options interface{}) (bender.RequestExecutor, e
 
 		if asExtended.Rcode != -1 && asExtended.Rcode != asMsg.Rcode {
 			return resp, fmt.Errorf(
-				"%w: invalid rcode want: %q, got: %q", ErrInvalidResponse,
+				"%w: invalid rcode want: %q, got: %q", ErrUnexpectedRcode,
 				dns.RcodeToString[asExtended.Rcode], dns.RcodeToString[asMsg.Rcode])
 		}
 
@@ -0,0 +1,116 @@
+/*
+Copyright (c) Facebook, Inc. and its affiliates.
+All rights reserved.
+
+This source code is licensed under the BSD-style license found in the
+LICENSE file in the root directory of this source tree.
+*/
+
+package tester
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"time"
+)
+
+// Error classes of the failed requests.
+const (
+	ErrorClassTimeout    = "timeout"
+	ErrorClassNetwork    = "network"
+	ErrorClassStatus     = "status"
+	ErrorClassValidation = "validation"
+	ErrorClassDropped    = "dropped"
+	ErrorClassOther      = "other"
+)
+
+// ErrorClasses lists all the error classes in the order they are reported.
+//nolint:gochecknoglobals
+var ErrorClasses = []string{
+	ErrorClassTimeout,
+	ErrorClassNetwork,
+	ErrorClassStatus,
+	ErrorClassValidation,
+	ErrorClassDropped,
+	ErrorClassOther,
+}
+
+// ErrUnexpectedStatus is wrapped by the errors of responses with an unexpected
+// status, e.g. HTTP status or DNS rcode.
+var ErrUnexpectedStatus = errors.New("invalid response status")
+
+// ErrInvalidResponse is wrapped by the errors of responses failing the protocol
+// validation.
+var ErrInvalidResponse = errors.New("invalid response")
+
+// ErrTimeout is wrapped by the errors of requests which timed out, when the
+// protocol doesn't report the timeouts as network errors.
+var ErrTimeout = errors.New("timeout")
+
+// WrapPlainTimeout wraps ErrTimeout around the timeouts of the DHCP executors
+// of github.com/pinterest/bender. They report the timeouts with a plain
+// errors.New("timeout") and no sentinel or net.Error to check, so the message
+// is the only way to recognize them. Other errors are returned as they are.
+func WrapPlainTimeout(err error, timeout time.Duration) error {
+	if err != nil && err.Error() == "timeout" {
+		return fmt.Errorf("%w: no response in %s", ErrTimeout, timeout)
+	}
+
+	return err
+}
+
+// classifiedError is an error of an explicitly given class.
+type classifiedError struct {
+	class string
+	err   error
+}
+
+func (e *classifiedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *classifiedError) Unwrap() error {
+	return e.err
+}
+
+// WithErrorClass returns the error classified as the given class by
+// ClassifyError. The message and the wrapped errors are kept.
+func WithErrorClass(err error, class string) error {
+	if err == nil {
+		return nil
+	}
+
+	return &classifiedError{class: class, err: err}
+}
+
+// ClassifyError returns the class of an error returned by a request executor,
+// an empty string if there is no error. Errors of dropped requests are never
+// returned by the executors, they are classified by the recorders.
+func ClassifyError(err error) string {
+	var (
+		classified *classifiedError
+		netErr     net.Error
+		opErr      *net.OpError
+	)
+
+	switch {
+	case err == nil:
+		return ""
+	case errors.As(err, &classified):
+		return classified.class
+	case errors.Is(err, ErrTimeout), errors.Is(err, context.DeadlineExceeded), errors.Is(err, os.ErrDeadlineExceeded),
+		errors.As(err, &netErr) && netErr.Timeout():
+		return ErrorClassTimeout
+	case errors.Is(err, ErrUnexpectedStatus):
+		return ErrorClassStatus
+	case errors.Is(err, ErrInvalidResponse):
+		return ErrorClassValidation
+	case errors.As(err, &opErr), errors.As(err, &netErr):
+		return ErrorClassNetwork
+	default:
+		return ErrorClassOther
+	}
+}
@@ -0,0 +1,65 @@
+/*
+Copyright (c) Facebook, Inc. and its affiliates.
+All rights reserved.
+
+This source code is licensed under the BSD-style license found in the
+LICENSE file in the root directory of this source tree.
+*/
+
+package tester_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/facebookincubator/fbender/tester"
+	"github.com/stretchr/testify/assert"
+)
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestClassifyError(t *testing.T) {
+	refused := &net.OpError{Op: "read", Net: "udp", Err: syscall.ECONNREFUSED}
+	timeout := &net.OpError{Op: "read", Net: "udp", Err: timeoutError{}}
+
+	tests := []struct {
+		err      error
+		expected string
+	}{
+		{err: nil, expected: ""},
+		{err: context.DeadlineExceeded, expected: tester.ErrorClassTimeout},
+		{err: timeout, expected: tester.ErrorClassTimeout},
+		{err: fmt.Errorf("wrapped: %w", timeout), expected: tester.ErrorClassTimeout},
+		{err: fmt.Errorf("%w: no response", tester.ErrTimeout), expected: tester.ErrorClassTimeout},
+		{err: errors.New("timeout"), expected: tester.ErrorClassOther},
+		{err: tester.WrapPlainTimeout(errors.New("timeout"), time.Second), expected: tester.ErrorClassTimeout},
+		{err: tester.WrapPlainTimeout(errors.New("timeout: x"), time.Second), expected: tester.ErrorClassOther},
+		{err: refused, expected: tester.ErrorClassNetwork},
+		{err: &net.DNSError{Err: "no such host"}, expected: tester.ErrorClassNetwork},
+		{err: fmt.Errorf("%w, got: 404", tester.ErrUnexpectedStatus), expected: tester.ErrorClassStatus},
+		{err: fmt.Errorf("%w: id", tester.ErrInvalidResponse), expected: tester.ErrorClassValidation},
+		{err: assert.AnError, expected: tester.ErrorClassOther},
+		{err: tester.WithErrorClass(assert.AnError, tester.ErrorClassStatus), expected: tester.ErrorClassStatus},
+		{err: tester.WithErrorClass(refused, tester.ErrorClassValidation), expected: tester.ErrorClassValidation},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, tester.ClassifyError(test.err), test.err)
+	}
+}
+
+func TestWithErrorClass(t *testing.T) {
+	err := tester.WithErrorClass(assert.AnError, tester.ErrorClassStatus)
+	assert.Equal(t, assert.AnError.Error(), err.Error())
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.NoError(t, tester.WithErrorClass(nil, tester.ErrorClassStatus))
+}
@@ -0,0 +1,31 @@
+/*
+Copyright (c) Facebook, Inc. and its affiliates.
+All rights reserved.
+
+This source code is licensed under the BSD-style license found in the
+LICENSE file in the root directory of this source tree.
+*/
+
+package tester
+
+// ScheduledRequestEvent is sent in throughput tests after a request has
+// completed, right after the bender.EndRequestEvent. Besides the actual start
+// it holds the time at which the request was scheduled to be sent, which
+// allows to correct the latency for the coordinated omission.
+type ScheduledRequestEvent struct {
+	// The Unix epoch times (in nanoseconds) at which the request was scheduled,
+	// started and finished
+	Scheduled, Start, End int64
+	// An error or nil if there was no error
+	Err error
+}
+
+// DroppedRequestEvent is sent in throughput tests instead of executing
+// a request when the limit of in-flight requests has been reached and the
+// overflowing requests are dropped.
+type DroppedRequestEvent struct {
+	// The Unix epoch time (in nanoseconds) at which the request was dropped
+	Time int64
+	// The request that was dropped
+	Request interface{}
+}
@@ -0,0 +1,301 @@
+/*
+Copyright (c) Facebook, Inc. and its affiliates.
+All rights reserved.
+
+This source code is licensed under the BSD-style license found in the
+LICENSE file in the root directory of this source tree.
+*/
+
+package tester
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Operator combines the operands of a compound constraint.
+type Operator interface {
+	// Evaluate checks the operands using the given check of the simple
+	// constraints and combines the results.
+	Evaluate(check func(*Constraint) error, operands ...*Constraint) error
+	// String returns the representation of the operator applied to the operands.
+	String(operands ...*Constraint) string
+}
+
+type operator struct {
+	repr string
+	eval func(check func(*Constraint) error, operands ...*Constraint) error
+}
+
+func (o *operator) Evaluate(check func(*Constraint) error, operands ...*Constraint) error {
+	return o.eval(check, operands...)
+}
+
+func (o *operator) String(operands ...*Constraint) string {
+	reprs := make([]string, 0, len(operands))
+
+	// NOT binds tightest, so only AND and OR operands need parentheses
+	for _, operand := range operands {
+		if operand.Operator != nil && operand.Operator != NotOperator {
+			reprs = append(reprs, fmt.Sprintf("(%s)", operand))
+		} else {
+			reprs = append(reprs, operand.String())
+		}
+	}
+
+	if len(reprs) == 1 {
+		return fmt.Sprintf("%s %s", o.repr, reprs[0])
+	}
+
+	return strings.Join(reprs, fmt.Sprintf(" %s ", o.repr))
+}
+
+// reason returns the reason why a constraint is not satisfied.
+func reason(err error) string {
+	return strings.TrimPrefix(err.Error(), ErrNotSatisfied.Error()+": ")
+}
+
+// Available operators.
+//nolint:gochecknoglobals
+var (
+	// AndOperator is satisfied if all of its operands are satisfied.
+	AndOperator Operator = &operator{
+		repr: "AND",
+		eval: func(check func(*Constraint) error, operands ...*Constraint) error {
+			for _, operand := range operands {
+				if err := operand.Evaluate(check); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		},
+	}
+	// OrOperator is satisfied if any of its operands is satisfied.
+	OrOperator Operator = &operator{
+		repr: "OR",
+		eval: func(check func(*Constraint) error, operands ...*Constraint) error {
+			var failure error
+
+			reasons := make([]string, 0, len(operands))
+
+			for _, operand := range operands {
+				err := operand.Evaluate(check)
+				if err == nil {
+					return nil
+				}
+
+				// The alternative cannot be checked, so neither can be the whole
+				if !errors.Is(err, ErrNotSatisfied) && failure == nil {
+					failure = err
+				}
+
+				reasons = append(reasons, reason(err))
+			}
+
+			if failure != nil {
+				return failure
+			}
+
+			return fmt.Errorf("%w: %s", ErrNotSatisfied, strings.Join(reasons, " OR "))
+		},
+	}
+	// NotOperator is satisfied if its only operand is not satisfied.
+	NotOperator Operator = &operator{
+		repr: "NOT",
+		eval: func(check func(*Constraint) error, operands ...*Constraint) error {
+			err := operands[0].Evaluate(check)
+			if err == nil {
+				return fmt.Errorf("%w: NOT %s", ErrNotSatisfied, operands[0])
+			}
+
+			if errors.Is(err, ErrNotSatisfied) {
+				return nil
+			}
+
+			return err
+		},
+	}
+)
+
+// ParseConstraint creates a constraint from a string representation. Simple
+// constraints can be combined with AND, OR, NOT and parentheses.
+func ParseConstraint(s string, parsers ...MetricParser) (*Constraint, error) {
+	p := &expressionParser{input: s, parsers: parsers}
+
+	constraint, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.skipSpaces(); p.pos < len(p.input) {
+		return nil, fmt.Errorf("%w, unexpected: %q", ErrInvalidFormat, p.input[p.pos:])
+	}
+
+	return constraint, nil
+}
+
+// expressionParser is a recursive descent parser of the constraint
+// expressions.
+type expressionParser struct {
+	input   string
+	pos     int
+	parsers []MetricParser
+}
+
+func (p *expressionParser) skipSpaces() {
+	for p.pos < len(p.input) && isSpace(p.input[p.pos]) {
+		p.pos++
+	}
+}
+
+// keyword consumes the keyword if it's next in the input.
+func (p *expressionParser) keyword(keyword string) bool {
+	p.skipSpaces()
+
+	if !strings.HasPrefix(p.input[p.pos:], keyword) {
+		return false
+	}
+
+	end := p.pos + len(keyword)
+	if end < len(p.input) && !isSpace(p.input[end]) && p.input[end] != '(' {
+		return false
+	}
+
+	p.pos = end
+
+	return true
+}
+
+// compound parses operands separated by the operator keyword.
+func (p *expressionParser) compound(op Operator, keyword string,
+	operand func() (*Constraint, error)) (*Constraint, error) {
+	first, err := operand()
+	if err != nil {
+		return nil, err
+	}
+
+	operands := []*Constraint{first}
+
+	for p.keyword(keyword) {
+		next, err := operand()
+		if err != nil {
+			return nil, err
+		}
+
+		operands = append(operands, next)
+	}
+
+	if len(operands) == 1 {
+		return first, nil
+	}
+
+	return &Constraint{Operator: op, Operands: operands}, nil
+}
+
+func (p *expressionParser) parseOr() (*Constraint, error) {
+	return p.compound(OrOperator, "OR", p.parseAnd)
+}
+
+func (p *expressionParser) parseAnd() (*Constraint, error) {
+	return p.compound(AndOperator, "AND", p.parseUnary)
+}
+
+func (p *expressionParser) parseUnary() (*Constraint, error) {
+	if p.keyword("NOT") {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+
+		return &Constraint{Operator: NotOperator, Operands: []*Constraint{operand}}, nil
+	}
+
+	if p.pos < len(p.input) && p.input[p.pos] == '(' {
+		p.pos++
+
+		constraint, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+
+		if p.skipSpaces(); p.pos >= len(p.input) || p.input[p.pos] != ')' {
+			return nil, fmt.Errorf("%w, missing closing parenthesis", ErrInvalidFormat)
+		}
+
+		p.pos++
+
+		return constraint, nil
+	}
+
+	return p.parseSimple()
+}
+
+// parseSimple finds the end of a simple constraint and parses it. The metric
+// may contain balanced parentheses and quoted strings.
+func (p *expressionParser) parseSimple() (*Constraint, error) {
+	start := p.pos
+
+	// Aggregator
+	if strings.HasPrefix(p.input[p.pos:], "P(") {
+		end := strings.IndexByte(p.input[p.pos:], ')')
+		if end < 0 {
+			return nil, ErrInvalidFormat
+		}
+
+		p.pos += end + 1
+	} else {
+		p.scan(isWord)
+	}
+
+	// Metric
+	if p.pos >= len(p.input) || p.input[p.pos] != '(' {
+		return nil, ErrInvalidFormat
+	}
+
+	for depth, quoted := 0, false; ; p.pos++ {
+		if p.pos >= len(p.input) {
+			return nil, ErrInvalidFormat
+		}
+
+		switch c := p.input[p.pos]; {
+		case c == '"':
+			quoted = !quoted
+		case quoted:
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		}
+
+		if depth == 0 {
+			p.pos++
+
+			break
+		}
+	}
+
+	// Comparator and threshold
+	p.skipSpaces()
+	p.scan(func(c byte) bool { return strings.IndexByte("<>=~!@#$%^&?", c) >= 0 })
+	p.skipSpaces()
+	p.scan(func(c byte) bool { return c == '-' || c == '+' || c == '.' || ('0' <= c && c <= '9') })
+
+	return parseSimpleConstraint(p.input[start:p.pos], p.parsers...)
+}
+
+// scan consumes the characters matching the predicate.
+func (p *expressionParser) scan(match func(byte) bool) {
+	for p.pos < len(p.input) && match(p.input[p.pos]) {
+		p.pos++
+	}
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+func isWord(c byte) bool {
+	return c == '_' || ('0' <= c && c <= '9') || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
@@ -0,0 +1,157 @@
+/*
+Copyright (c) Facebook, Inc. and its affiliates.
+All rights reserved.
+
+This source code is licensed under the BSD-style license found in the
+LICENSE file in the root directory of this source tree.
+*/
+
+package tester_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/facebookincubator/fbender/tester"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// constantMetric returns a single data point with its value.
+type constantMetric struct {
+	name  string
+	value float64
+}
+
+func (m *constantMetric) Setup(_ interface{}) error {
+	return nil
+}
+
+func (m *constantMetric) Fetch(start time.Time, duration time.Duration) ([]tester.DataPoint, error) {
+	if m.value < 0 {
+		return nil, nil
+	}
+
+	return []tester.DataPoint{{Time: start.Add(duration), Value: m.value}}, nil
+}
+
+func (m *constantMetric) Name() string {
+	return m.name
+}
+
+// constantParser parses metrics named a..z with values 0..25, "none" has no
+// data points.
+func constantParser(value string) (tester.Metric, error) {
+	if value == "none" {
+		return &constantMetric{name: value, value: -1}, nil
+	}
+
+	if len(value) != 1 || value[0] < 'a' || value[0] > 'z' {
+		return nil, tester.ErrNotParsed
+	}
+
+	return &constantMetric{name: value, value: float64(value[0] - 'a')}, nil
+}
+
+func TestParseConstraint__Expression(t *testing.T) {
+	tests := []struct {
+		input     string
+		repr      string
+		satisfied bool
+	}{
+		{"MAX(b) <= 1", "MAX(b) <= 1.00", true},
+		{"MAX(b) >= 2", "MAX(b) >= 2.00", false},
+		{"MAX(b) == 1", "MAX(b) == 1.00", true},
+		{"MAX(b) != 1", "MAX(b) != 1.00", false},
+		{"MAX(b) < 1 OR MAX(c) < 3", "MAX(b) < 1.00 OR MAX(c) < 3.00", true},
+		{"MAX(b) < 1 OR MAX(c) < 2", "MAX(b) < 1.00 OR MAX(c) < 2.00", false},
+		{"MAX(b) < 2 AND MAX(c) < 3", "MAX(b) < 2.00 AND MAX(c) < 3.00", true},
+		{"MAX(b) < 2 AND MAX(c) < 2", "MAX(b) < 2.00 AND MAX(c) < 2.00", false},
+		{"NOT MAX(b) < 2", "NOT MAX(b) < 2.00", false},
+		{"NOT(MAX(b) > 2)", "NOT MAX(b) > 2.00", true},
+		// AND binds tighter than OR
+		{"MAX(a) > 0 AND MAX(b) > 0 OR MAX(c) > 0", "(MAX(a) > 0.00 AND MAX(b) > 0.00) OR MAX(c) > 0.00", true},
+		{"MAX(a) > 0 AND (MAX(b) > 0 OR MAX(c) > 0)", "MAX(a) > 0.00 AND (MAX(b) > 0.00 OR MAX(c) > 0.00)", false},
+		{"NOT (MAX(a) > 0 OR MAX(b) > 5) AND P(50)(c)<3", "NOT (MAX(a) > 0.00 OR MAX(b) > 5.00) AND P(50)(c) < 3.00", true},
+		{" ( ( MAX(a) == 0 ) ) ", "MAX(a) == 0.00", true},
+	}
+
+	for _, test := range tests {
+		c, err := tester.ParseConstraint(test.input, constantParser)
+		require.NoError(t, err, test.input)
+		assert.Equal(t, test.repr, c.String(), test.input)
+
+		err = c.Check(time.Now(), time.Second)
+		if test.satisfied {
+			assert.NoError(t, err, test.input)
+		} else {
+			assert.ErrorIs(t, err, tester.ErrNotSatisfied, test.input)
+		}
+	}
+}
+
+func TestParseConstraint__ExpressionErrors(t *testing.T) {
+	for _, input := range []string{
+		"",
+		"MAX(a) < 1 AND",
+		"MAX(a) < 1 OR OR MAX(b) < 1",
+		"(MAX(a) < 1",
+		"MAX(a) < 1)",
+		"NOT",
+		"MAX(a < 1",
+		"MAX(a) < 1 MAX(b) < 1",
+	} {
+		c, err := tester.ParseConstraint(input, constantParser)
+		assert.Nil(t, c, input)
+		assert.ErrorIs(t, err, tester.ErrInvalidFormat, input)
+	}
+}
+
+func TestConstraint__Simple(t *testing.T) {
+	c, err := tester.ParseConstraint("NOT MAX(a) > 1 AND (MAX(b) < 1 OR MAX(c) < 1)", constantParser)
+	require.NoError(t, err)
+
+	names := []string{}
+	for _, simple := range c.Simple() {
+		names = append(names, simple.Metric.Name())
+	}
+
+	assert.Equal(t, []string{"a", "b", "c"}, names)
+}
+
+func TestConstraint__EvaluateNoDataPoints(t *testing.T) {
+	// An alternative without data points cannot be checked
+	c, err := tester.ParseConstraint("MAX(none) < 1 OR MAX(c) < 1", constantParser)
+	require.NoError(t, err)
+
+	err = c.Check(time.Now(), time.Second)
+	assert.True(t, errors.Is(err, tester.ErrNoDataPoints))
+
+	// Unless any other alternative is satisfied
+	c, err = tester.ParseConstraint("MAX(none) < 1 OR MAX(a) < 1", constantParser)
+	require.NoError(t, err)
+	assert.NoError(t, c.Check(time.Now(), time.Second))
+
+	// Negation of a constraint without data points cannot be checked either
+	c, err = tester.ParseConstraint("NOT MAX(none) < 1", constantParser)
+	require.NoError(t, err)
+
+	err = c.Check(time.Now(), time.Second)
+	assert.True(t, errors.Is(err, tester.ErrNoDataPoints))
+}
+
+func TestParseConstraint__QueryMetric(t *testing.T) {
+	var names []string
+
+	parser := func(value string) (tester.Metric, error) {
+		names = append(names, value)
+
+		return &constantMetric{name: value}, nil
+	}
+
+	_, err := tester.ParseConstraint(
+		`MAX(q:sum by (job) (rate(x{job="a) OR b"}[1m]))) < 80 AND P(99)(q:y > 1) >= 0`, parser)
+	require.NoError(t, err)
+	assert.Equal(t, []string{`q:sum by (job) (rate(x{job="a) OR b"}[1m]))`, "q:y > 1"}, names)
+}
@@ -9,13 +9,14 @@ LICENSE file in the root directory of this source tree.
 package http
 
 import (
-	"errors"
+	"context"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
 	"time"
 
+	"github.com/facebookincubator/fbender/tester"
 	"github.com/pinterest/bender"
 	protocol "github.com/pinterest/bender/http"
 )
@@ -31,7 +32,7 @@ type Tester struct {
 const httpStatusOK = 200
 
 // ErrInvalidResponse is raised when a request returns a code different than 200.
-var ErrInvalidResponse = errors.New("invalid response status")
+var ErrInvalidResponse = tester.ErrUnexpectedStatus
 
 // Before is called before the first test.
 func (t *Tester) Before(options interface{}) error {
@@ -66,11 +67,22 @@ func validator(request interface{}, response *http.Response) error {
 	return err
 }
 
-// RequestExecutor returns a request executor.
-func (t *Tester) RequestExecutor(options interface{}) (bender.RequestExecutor, error) {
+// RequestExecutor returns a request executor. Requests are bound to the given
+// context so they are abandoned once it's canceled.
+func (t *Tester) RequestExecutor(ctx context.Context, options interface{}) (bender.RequestExecutor, error) {
+	var executor bender.RequestExecutor
+
 	if t.Validator == nil {
-		return protocol.CreateExecutor(nil, t.client, validator), nil
+		executor = protocol.CreateExecutor(nil, t.client, validator)
+	} else {
+		executor = protocol.CreateExecutor(nil, t.client, t.Validator)
 	}
 
-	return protocol.CreateExecutor(nil, t.client, t.Validator), nil
+	return func(n int64, request interface{}) (interface{}, error) {
+		if req, ok := request.(*http.Request); ok {
+			request = req.WithContext(ctx)
+		}
+
+		return executor(n, request)
+	}, nil
 }
@@ -10,6 +10,11 @@ package tester
 
 import (
 	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -108,22 +113,152 @@ var AverageAggregator Aggregator = &metricAggregator{
 	},
 }
 
+// MedianAggregator returns the median data point value, the average of the
+// two middle values for an even number of data points.
+//nolint:gochecknoglobals
+var MedianAggregator Aggregator = &metricAggregator{
+	repr: "MEDIAN",
+	aggr: func(points []DataPoint) float64 {
+		if len(points) == 0 {
+			return 0.
+		}
+
+		values := sortedValues(points)
+		middle := len(values) / 2
+
+		if len(values)%2 == 0 {
+			return (values[middle-1] + values[middle]) / 2
+		}
+
+		return values[middle]
+	},
+}
+
+// StandardDeviationAggregator returns the standard deviation of the data point
+// values.
+//nolint:gochecknoglobals
+var StandardDeviationAggregator Aggregator = &metricAggregator{
+	repr: "STDDEV",
+	aggr: func(points []DataPoint) float64 {
+		if len(points) == 0 {
+			return 0.
+		}
+
+		average := AverageAggregator.Aggregate(points)
+
+		sum := 0.
+		for _, point := range points {
+			sum += (point.Value - average) * (point.Value - average)
+		}
+
+		return math.Sqrt(sum / float64(len(points)))
+	},
+}
+
+// CountAggregator returns the number of data points.
+//nolint:gochecknoglobals
+var CountAggregator Aggregator = &metricAggregator{
+	repr: "COUNT",
+	aggr: func(points []DataPoint) float64 {
+		return float64(len(points))
+	},
+}
+
+// SumAggregator returns the sum of the data point values.
+//nolint:gochecknoglobals
+var SumAggregator Aggregator = &metricAggregator{
+	repr: "SUM",
+	aggr: func(points []DataPoint) float64 {
+		sum := 0.
+		for _, point := range points {
+			sum += point.Value
+		}
+
+		return sum
+	},
+}
+
+// PercentileAggregator returns an aggregator of the p-th percentile (0 < p <=
+// 100) of the data point values, using the nearest-rank method.
+func PercentileAggregator(p float64) Aggregator {
+	return percentileAggregator(fmt.Sprintf("P(%s)", strconv.FormatFloat(p, 'f', -1, 64)), p)
+}
+
+func percentileAggregator(repr string, p float64) Aggregator {
+	return &metricAggregator{
+		repr: repr,
+		aggr: func(points []DataPoint) float64 {
+			if len(points) == 0 {
+				return 0.
+			}
+
+			values := sortedValues(points)
+			rank := int(math.Ceil(p / 100 * float64(len(values))))
+
+			if rank < 1 {
+				rank = 1
+			}
+
+			return values[rank-1]
+		},
+	}
+}
+
+// Percentile aggregators of the most common percentiles.
+//nolint:gochecknoglobals
+var (
+	P50Aggregator  = percentileAggregator("P50", 50)
+	P90Aggregator  = percentileAggregator("P90", 90)
+	P99Aggregator  = percentileAggregator("P99", 99)
+	P999Aggregator = percentileAggregator("P999", 99.9)
+)
+
+// sortedValues returns the data point values in increasing order.
+func sortedValues(points []DataPoint) []float64 {
+	values := make([]float64, 0, len(points))
+	for _, point := range points {
+		values = append(values, point.Value)
+	}
+
+	sort.Float64s(values)
+
+	return values
+}
+
 // Aggregators is a map of aggregators representation to the actual aggregator.
 //nolint:gochecknoglobals
 var Aggregators = map[string]Aggregator{
-	MinimumAggregator.Name(): MinimumAggregator,
-	MaximumAggregator.Name(): MaximumAggregator,
-	AverageAggregator.Name(): AverageAggregator,
+	MinimumAggregator.Name():           MinimumAggregator,
+	MaximumAggregator.Name():           MaximumAggregator,
+	AverageAggregator.Name():           AverageAggregator,
+	MedianAggregator.Name():            MedianAggregator,
+	StandardDeviationAggregator.Name(): StandardDeviationAggregator,
+	CountAggregator.Name():             CountAggregator,
+	SumAggregator.Name():               SumAggregator,
+	P50Aggregator.Name():               P50Aggregator,
+	P90Aggregator.Name():               P90Aggregator,
+	P99Aggregator.Name():               P99Aggregator,
+	P999Aggregator.Name():              P999Aggregator,
 }
 
 // ErrInvalidAggregator is returned when a metric aggregator cannot be found.
 var ErrInvalidAggregator = errors.New("invalid aggregator")
 
-// ParseAggregator returns metric aggregator from its name.
+// ParseAggregator returns metric aggregator from its name, P(x) is the x-th
+// percentile.
 func ParseAggregator(name string) (Aggregator, error) {
 	if aggregator, ok := Aggregators[name]; ok {
 		return aggregator, nil
 	}
 
-	return nil, ErrInvalidAggregator
+	if !strings.HasPrefix(name, "P(") || !strings.HasSuffix(name, ")") {
+		return nil, ErrInvalidAggregator
+	}
+
+	p, err := strconv.ParseFloat(name[2:len(name)-1], 64)
+	if err != nil || p <= 0 || p > 100 {
+		return nil, fmt.Errorf("%w, percentile must be in (0, 100], got: %q", ErrInvalidAggregator, name)
+	}
+
+	return PercentileAggregator(p), nil
 }
@@ -212,12 +212,75 @@ func TestParseAggregator(t *testing.T) {
 	assert.NoError(t, err)
 	assertPointerEqual(t, tester.AverageAggregator, a, "Expected average aggregator")
 
+	a, err = tester.ParseAggregator("P99")
+	assert.NoError(t, err)
+	assertPointerEqual(t, tester.P99Aggregator, a, "Expected 99th percentile aggregator")
+
+	a, err = tester.ParseAggregator("P(99.5)")
+	assert.NoError(t, err)
+	assert.Equal(t, "P(99.5)", a.Name())
+
+	for _, name := range []string{"P(0)", "P(101)", "P(x)", "P()"} {
+		a, err = tester.ParseAggregator(name)
+		assert.ErrorIs(t, err, tester.ErrInvalidAggregator, name)
+		assert.Nil(t, a)
+	}
+
 	a, err = tester.ParseAggregator("Nonexistent")
 	assert.Error(t, err)
 	assert.Nil(t, a)
 	assert.Equal(t, tester.ErrInvalidAggregator, err)
 }
 
+func TestPercentileAggregators(t *testing.T) {
+	// Values 1..100 in reverse order
+	points := make([]tester.DataPoint, 0, 100)
+	for i := 100; i > 0; i-- {
+		points = append(points, tester.DataPoint{Value: float64(i)})
+	}
+
+	assert.Equal(t, 50., tester.P50Aggregator.Aggregate(points))
+	assert.Equal(t, 90., tester.P90Aggregator.Aggregate(points))
+	assert.Equal(t, 99., tester.P99Aggregator.Aggregate(points))
+	assert.Equal(t, 100., tester.P999Aggregator.Aggregate(points))
+	assert.Equal(t, 100., tester.PercentileAggregator(100).Aggregate(points))
+	assert.Equal(t, 1., tester.PercentileAggregator(0.1).Aggregate(points))
+	assert.Equal(t, 0., tester.P99Aggregator.Aggregate(nil))
+
+	// Data points are not reordered
+	assert.Equal(t, 100., points[0].Value)
+}
+
+func TestMedianAggregator(t *testing.T) {
+	assert.Equal(t, "MEDIAN", tester.MedianAggregator.Name())
+	assert.Equal(t, 0., tester.MedianAggregator.Aggregate(nil))
+	assert.Equal(t, 2., tester.MedianAggregator.Aggregate([]tester.DataPoint{{Value: 3}, {Value: 1}, {Value: 2}}))
+	assert.Equal(t, 2.5, tester.MedianAggregator.Aggregate([]tester.DataPoint{
+		{Value: 4}, {Value: 1}, {Value: 3}, {Value: 2},
+	}))
+}
+
+func TestStandardDeviationAggregator(t *testing.T) {
+	assert.Equal(t, "STDDEV", tester.StandardDeviationAggregator.Name())
+	assert.Equal(t, 0., tester.StandardDeviationAggregator.Aggregate(nil))
+	assert.Equal(t, 0., tester.StandardDeviationAggregator.Aggregate([]tester.DataPoint{{Value: 5}, {Value: 5}}))
+	assert.Equal(t, 2., tester.StandardDeviationAggregator.Aggregate([]tester.DataPoint{
+		{Value: 2}, {Value: 4}, {Value: 4}, {Value: 4}, {Value: 5}, {Value: 5}, {Value: 7}, {Value: 9},
+	}))
+}
+
+func TestCountAndSumAggregators(t *testing.T) {
+	points := []tester.DataPoint{{Value: 1.5}, {Value: 2.5}, {Value: -1}}
+
+	assert.Equal(t, "COUNT", tester.CountAggregator.Name())
+	assert.Equal(t, 3., tester.CountAggregator.Aggregate(points))
+	assert.Equal(t, 0., tester.CountAggregator.Aggregate(nil))
+
+	assert.Equal(t, "SUM", tester.SumAggregator.Name())
+	assert.Equal(t, 3., tester.SumAggregator.Aggregate(points))
+	assert.Equal(t, 0., tester.SumAggregator.Aggregate(nil))
+}
+
 func TestMinimumAggregatorTestSuite(t *testing.T) {
 	suite.Run(t, new(MinimumAggregatorTestSuite))
 }
@@ -0,0 +1,67 @@
+/*
+Copyright (c) Facebook, Inc. and its affiliates.
+All rights reserved.
+
+This source code is licensed under the BSD-style license found in the
+LICENSE file in the root directory of this source tree.
+*/
+
+package tester
+
+import (
+	"math/rand"
+)
+
+// Indexer returns an index of the input line for the i-th request. It returns
+// false once the input is exhausted.
+type Indexer func(i int) (int, bool)
+
+// Order creates an indexer for an input of n lines. The seed initializes the
+// random orders, the same seed gives the same sequence of lines.
+type Order func(n int, seed int64) Indexer
+
+// RoundRobinOrder cycles through the input lines.
+func RoundRobinOrder(n int, _ int64) Indexer {
+	return func(i int) (int, bool) {
+		return i % n, true
+	}
+}
+
+// RandomOrder picks the input lines at random. The index depends only on the
+// seed and the request number, so the indexer is safe for concurrent use.
+func RandomOrder(n int, seed int64) Indexer {
+	return func(i int) (int, bool) {
+		return int(mix(uint64(seed), uint64(i)) % uint64(n)), true
+	}
+}
+
+// ShuffleOnceOrder shuffles the input lines once and then cycles through them.
+func ShuffleOnceOrder(n int, seed int64) Indexer {
+	//nolint:gosec
+	permutation := rand.New(rand.NewSource(seed)).Perm(n)
+
+	return func(i int) (int, bool) {
+		return permutation[i%n], true
+	}
+}
+
+// OnceOrder goes through the input lines exactly once.
+func OnceOrder(n int, _ int64) Indexer {
+	return func(i int) (int, bool) {
+		if i >= n {
+			return 0, false
+		}
+
+		return i, true
+	}
+}
+
+// mix returns a pseudo-random number for the i-th element of the sequence
+// generated from the seed (SplitMix64).
+func mix(seed, i uint64) uint64 {
+	z := seed + (i+1)*0x9e3779b97f4a7c15
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+
+	return z ^ (z >> 31)
+}
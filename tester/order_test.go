@@ -0,0 +1,76 @@
+/*
+Copyright (c) Facebook, Inc. and its affiliates.
+All rights reserved.
+
+This source code is licensed under the BSD-style license found in the
+LICENSE file in the root directory of this source tree.
+*/
+
+package tester_test
+
+import (
+	"testing"
+
+	"github.com/facebookincubator/fbender/tester"
+	"github.com/stretchr/testify/assert"
+)
+
+// indices returns the first count indices of the indexer and whether all of
+// them were available.
+func indices(indexer tester.Indexer, count int) ([]int, bool) {
+	result := make([]int, 0, count)
+
+	for i := 0; i < count; i++ {
+		index, ok := indexer(i)
+		if !ok {
+			return result, false
+		}
+
+		result = append(result, index)
+	}
+
+	return result, true
+}
+
+func TestRoundRobinOrder(t *testing.T) {
+	result, ok := indices(tester.RoundRobinOrder(3, 0), 7)
+	assert.True(t, ok)
+	assert.Equal(t, []int{0, 1, 2, 0, 1, 2, 0}, result)
+}
+
+func TestRandomOrder(t *testing.T) {
+	result, ok := indices(tester.RandomOrder(5, 42), 1000)
+	assert.True(t, ok)
+
+	counts := make([]int, 5)
+	for _, index := range result {
+		counts[index]++
+	}
+
+	for i, count := range counts {
+		assert.InDelta(t, 200, count, 60, "Expected line %d to be picked uniformly", i)
+	}
+
+	// The same seed gives the same sequence, a different one doesn't.
+	same, _ := indices(tester.RandomOrder(5, 42), 1000)
+	assert.Equal(t, result, same)
+
+	different, _ := indices(tester.RandomOrder(5, 43), 1000)
+	assert.NotEqual(t, result, different)
+}
+
+func TestShuffleOnceOrder(t *testing.T) {
+	result, ok := indices(tester.ShuffleOnceOrder(5, 42), 10)
+	assert.True(t, ok)
+	assert.ElementsMatch(t, []int{0, 1, 2, 3, 4}, result[:5])
+	assert.Equal(t, result[:5], result[5:])
+
+	same, _ := indices(tester.ShuffleOnceOrder(5, 42), 10)
+	assert.Equal(t, result, same)
+}
+
+func TestOnceOrder(t *testing.T) {
+	result, ok := indices(tester.OnceOrder(3, 0), 5)
+	assert.False(t, ok)
+	assert.Equal(t, []int{0, 1, 2}, result)
+}
@@ -0,0 +1,338 @@
+/*
+Copyright (c) Facebook, Inc. and its affiliates.
+All rights reserved.
+
+This source code is licensed under the BSD-style license found in the
+LICENSE file in the root directory of this source tree.
+*/
+
+package tester
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/facebookincubator/fbender/utils"
+	"github.com/pinterest/bender"
+)
+
+// Stage represents a part of a load profile in which the QPS changes linearly
+// from one value to another.
+type Stage struct {
+	From, To float64
+	Duration time.Duration
+}
+
+func (s *Stage) String() string {
+	if s.From == s.To {
+		return fmt.Sprintf("hold %g for %s", s.From, s.Duration)
+	}
+
+	return fmt.Sprintf("ramp %g->%g over %s", s.From, s.To, s.Duration)
+}
+
+// Requests returns the expected number of requests sent during the stage.
+func (s *Stage) Requests() float64 {
+	return (s.From + s.To) / 2 * s.Duration.Seconds()
+}
+
+// QPS returns the QPS after elapsed time since the beginning of the stage.
+func (s *Stage) QPS(elapsed time.Duration) float64 {
+	return s.From + (s.To-s.From)*float64(elapsed)/float64(s.Duration)
+}
+
+// elapsed returns the time after which the expected number of requests sent
+// since the beginning of the stage reaches n.
+func (s *Stage) elapsed(n float64) time.Duration {
+	d := s.Duration.Seconds()
+	if s.From == s.To {
+		return time.Duration(n / s.From * float64(time.Second))
+	}
+
+	// Solve From * t + (To - From) / (2 * d) * t^2 = n for t
+	a := (s.To - s.From) / (2 * d)
+	t := (-s.From + math.Sqrt(s.From*s.From+4*a*n)) / (2 * a)
+
+	return time.Duration(math.Min(t, d) * float64(time.Second))
+}
+
+// Profile represents a load profile consisting of consecutive stages.
+type Profile []*Stage
+
+func (p Profile) String() string {
+	stages := make([]string, 0, len(p))
+	for _, stage := range p {
+		stages = append(stages, stage.String())
+	}
+
+	return strings.Join(stages, ", ")
+}
+
+// Duration returns the total duration of the profile.
+func (p Profile) Duration() time.Duration {
+	var duration time.Duration
+	for _, stage := range p {
+		duration += stage.Duration
+	}
+
+	return duration
+}
+
+// Requests returns the expected number of requests sent during the profile.
+func (p Profile) Requests() int {
+	requests := 0.
+	for _, stage := range p {
+		requests += stage.Requests()
+	}
+
+	return int(requests)
+}
+
+// Peak returns the highest QPS in the profile.
+func (p Profile) Peak() float64 {
+	peak := 0.
+	for _, stage := range p {
+		peak = math.Max(peak, math.Max(stage.From, stage.To))
+	}
+
+	return peak
+}
+
+// Scale returns the profile with the QPS of all stages multiplied by factor.
+func (p Profile) Scale(factor float64) Profile {
+	scaled := make(Profile, 0, len(p))
+	for _, stage := range p {
+		scaled = append(scaled, &Stage{From: stage.From * factor, To: stage.To * factor, Duration: stage.Duration})
+	}
+
+	return scaled
+}
+
+// IntervalGenerator returns an interval generator following the profile. The
+// intervals drawn from a unit rate distribution are rescaled to the profile
+// QPS, so both uniform and exponential distributions keep their properties.
+// The onStage callback (if not nil) is called every time a new stage begins.
+func (p Profile) IntervalGenerator(distribution func(float64) bender.IntervalGenerator,
+	onStage func(i int, stage *Stage)) bender.IntervalGenerator {
+	unit := distribution(1.)
+	stage, offset, elapsed := -1, 0., time.Duration(0)
+	n, last := 0., time.Duration(0)
+
+	return func(now int64) int64 {
+		n += float64(unit(now)) / float64(time.Second)
+
+		// Move on to the stage in which the n-th request should be sent,
+		// skipping the stages in which no requests are sent at all.
+		for stage < len(p) && (stage < 0 || n-offset > p[stage].Requests() || p[stage].Requests() == 0) {
+			if stage >= 0 {
+				offset += p[stage].Requests()
+				elapsed += p[stage].Duration
+			}
+
+			stage++
+
+			if stage < len(p) && onStage != nil {
+				onStage(stage, p[stage])
+			}
+		}
+
+		next := elapsed
+		if stage < len(p) {
+			next += p[stage].elapsed(n - offset)
+		}
+
+		wait := next - last
+		last = next
+
+		return int64(wait)
+	}
+}
+
+// ProfileHelp provides usage help about the load profiles.
+const ProfileHelp = `
+Profile is a comma separated list of stages which are run one after another:
+  Stage ::= "ramp" <From>-><To> "over" <Duration>
+          | "step" <QPS> <Duration>
+          | "hold" <Duration>
+* ramp changes QPS linearly from one value to another over the duration
+* step changes QPS to a given value and keeps it for the duration
+* hold keeps the QPS from the previous stage for the duration
+
+Alternatively a file with "Time QPS" lines may be given, the QPS changes
+linearly between consecutive points. Time is an offset from the beginning of
+the test either as a duration (90s, 1m30s) or in seconds (90).
+
+Profile examples:
+  ramp 0->5000 over 2m, hold 10m, step 8000 1m
+  step 100 30s, ramp 100->1000 over 5m, step 200 1m`
+
+// ErrInvalidProfile is returned when a profile cannot be parsed.
+var ErrInvalidProfile = errors.New("invalid profile")
+
+// Patterns used in the stage matching regexps.
+const (
+	qpsMatch      = `[-+]?\d*\.?\d+`
+	durationMatch = `\S+`
+)
+
+//nolint:gochecknoglobals
+var (
+	rampRegexp = utils.MustCompile(fmt.Sprintf(
+		`^ramp\s+(?P<from>%s)\s*->\s*(?P<to>%s)\s+over\s+(?P<duration>%s)$`, qpsMatch, qpsMatch, durationMatch,
+	))
+	stepRegexp = utils.MustCompile(fmt.Sprintf(
+		`^step\s+(?P<qps>%s)\s+(?P<duration>%s)$`, qpsMatch, durationMatch,
+	))
+	holdRegexp = utils.MustCompile(fmt.Sprintf(
+		`^hold\s+(?P<duration>%s)$`, durationMatch,
+	))
+)
+
+// ParseProfile creates a profile from its string representation.
+func ParseProfile(s string) (Profile, error) {
+	profile := Profile{}
+	qps := 0.
+
+	for _, value := range strings.Split(s, ",") {
+		stage, err := parseStage(strings.TrimSpace(value), qps)
+		if err != nil {
+			return nil, err
+		}
+
+		profile = append(profile, stage)
+		qps = stage.To
+	}
+
+	return profile, nil
+}
+
+func parseStage(s string, qps float64) (*Stage, error) {
+	var (
+		stage = &Stage{From: qps, To: qps}
+		match map[string]string
+		err   error
+	)
+
+	switch {
+	case rampRegexp.MatchString(s):
+		match = rampRegexp.FindStringSubmatchMap(s)
+		if stage.From, err = parseQPS(match["from"]); err != nil {
+			return nil, err
+		}
+
+		if stage.To, err = parseQPS(match["to"]); err != nil {
+			return nil, err
+		}
+	case stepRegexp.MatchString(s):
+		match = stepRegexp.FindStringSubmatchMap(s)
+		if stage.From, err = parseQPS(match["qps"]); err != nil {
+			return nil, err
+		}
+
+		stage.To = stage.From
+	case holdRegexp.MatchString(s):
+		match = holdRegexp.FindStringSubmatchMap(s)
+	default:
+		return nil, fmt.Errorf("%w, unknown stage: %q", ErrInvalidProfile, s)
+	}
+
+	if stage.Duration, err = time.ParseDuration(match["duration"]); err != nil {
+		return nil, fmt.Errorf("%w, invalid duration: %v", ErrInvalidProfile, err)
+	}
+
+	if stage.Duration <= 0 {
+		return nil, fmt.Errorf("%w, non-positive duration in stage: %q", ErrInvalidProfile, s)
+	}
+
+	return stage, nil
+}
+
+func parseQPS(s string) (float64, error) {
+	qps, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w, invalid qps: %v", ErrInvalidProfile, err)
+	}
+
+	if qps < 0 {
+		return 0, fmt.Errorf("%w, negative qps: %g", ErrInvalidProfile, qps)
+	}
+
+	return qps, nil
+}
+
+// ParseProfilePoints creates a profile from "Time QPS" lines, the QPS changes
+// linearly between consecutive points. Empty lines and lines starting with #
+// are skipped.
+func ParseProfilePoints(r io.Reader) (Profile, error) {
+	profile := Profile{}
+	scanner := bufio.NewScanner(r)
+
+	var (
+		last    time.Duration
+		lastQPS float64
+		points  int
+	)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("%w, want: \"Time QPS\", got: %q", ErrInvalidProfile, line)
+		}
+
+		offset, err := parseOffset(fields[0])
+		if err != nil {
+			return nil, err
+		}
+
+		qps, err := parseQPS(fields[1])
+		if err != nil {
+			return nil, err
+		}
+
+		if points > 0 {
+			if offset <= last {
+				return nil, fmt.Errorf("%w, time must be increasing, got: %q", ErrInvalidProfile, line)
+			}
+
+			profile = append(profile, &Stage{From: lastQPS, To: qps, Duration: offset - last})
+		}
+
+		last, lastQPS = offset, qps
+		points++
+	}
+
+	if err := scanner.Err(); err != nil {
+		//nolint:wrapcheck
+		return nil, err
+	}
+
+	if points < 2 {
+		return nil, fmt.Errorf("%w, at least two points are required", ErrInvalidProfile)
+	}
+
+	return profile, nil
+}
+
+func parseOffset(s string) (time.Duration, error) {
+	if seconds, err := strconv.ParseFloat(s, 64); err == nil {
+		return time.Duration(seconds * float64(time.Second)), nil
+	}
+
+	offset, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("%w, invalid time: %v", ErrInvalidProfile, err)
+	}
+
+	return offset, nil
+}
@@ -0,0 +1,117 @@
+/*
+Copyright (c) Facebook, Inc. and its affiliates.
+All rights reserved.
+
+This source code is licensed under the BSD-style license found in the
+LICENSE file in the root directory of this source tree.
+*/
+
+package tester_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/facebookincubator/fbender/tester"
+	"github.com/pinterest/bender"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseProfile(t *testing.T) {
+	p, err := tester.ParseProfile("ramp 0->5000 over 2m, hold 10m, step 8000 1m")
+	require.NoError(t, err)
+	assert.Equal(t, tester.Profile{
+		{From: 0, To: 5000, Duration: 2 * time.Minute},
+		{From: 5000, To: 5000, Duration: 10 * time.Minute},
+		{From: 8000, To: 8000, Duration: time.Minute},
+	}, p)
+	assert.Equal(t, "ramp 0->5000 over 2m0s, hold 5000 for 10m0s, hold 8000 for 1m0s", p.String())
+	assert.Equal(t, 13*time.Minute, p.Duration())
+	assert.Equal(t, 5000*60+5000*600+8000*60, p.Requests())
+	assert.Equal(t, 8000., p.Peak())
+	assert.Equal(t, 2000., p.Scale(.25).Peak())
+
+	// Hold at the beginning keeps zero QPS
+	p, err = tester.ParseProfile("hold 1s,ramp 10.5->0 over 1s")
+	require.NoError(t, err)
+	assert.Equal(t, tester.Profile{
+		{From: 0, To: 0, Duration: time.Second},
+		{From: 10.5, To: 0, Duration: time.Second},
+	}, p)
+}
+
+func TestParseProfile_Errors(t *testing.T) {
+	for _, s := range []string{
+		"",
+		"jump 100 1m",
+		"ramp 0->100 1m",
+		"ramp -1->100 over 1m",
+		"step 100 forever",
+		"step 100 0s",
+		"hold 1m,",
+	} {
+		_, err := tester.ParseProfile(s)
+		assert.ErrorIs(t, err, tester.ErrInvalidProfile, "profile: %q", s)
+	}
+}
+
+func TestParseProfilePoints(t *testing.T) {
+	p, err := tester.ParseProfilePoints(strings.NewReader(`
+# diurnal curve
+0 100
+1m 200
+
+90s 50
+`))
+	require.NoError(t, err)
+	assert.Equal(t, tester.Profile{
+		{From: 100, To: 200, Duration: time.Minute},
+		{From: 200, To: 50, Duration: 30 * time.Second},
+	}, p)
+
+	for _, s := range []string{
+		"",
+		"0 100",
+		"0 100\n1m",
+		"0 100\nlater 100",
+		"0 100\n1m -5",
+		"1m 100\n1m 200",
+	} {
+		_, err := tester.ParseProfilePoints(strings.NewReader(s))
+		assert.ErrorIs(t, err, tester.ErrInvalidProfile, "points: %q", s)
+	}
+}
+
+func TestProfile__IntervalGenerator(t *testing.T) {
+	p, err := tester.ParseProfile("ramp 0->100 over 10s, hold 5s, step 0 1s, step 10 1s")
+	require.NoError(t, err)
+
+	stages := []int{}
+	intervals := p.IntervalGenerator(bender.UniformIntervalGenerator, func(i int, stage *tester.Stage) {
+		stages = append(stages, i)
+	})
+
+	// Collect send times of all the requests
+	var elapsed time.Duration
+
+	times := make([]time.Duration, 0, p.Requests())
+	for i := 0; i < p.Requests(); i++ {
+		elapsed += time.Duration(intervals(0))
+		times = append(times, elapsed)
+	}
+
+	require.Len(t, times, 500+500+10)
+	assert.Equal(t, []int{0, 1, 2, 3}, stages)
+
+	// Ramp from 0 to 100 QPS: n requests are sent after sqrt(n / 5) seconds
+	assert.InDelta(t, time.Second, times[4], float64(time.Millisecond))
+	assert.InDelta(t, 10*time.Second, times[499], float64(time.Millisecond))
+	// Hold 100 QPS
+	assert.InDelta(t, 10*time.Millisecond, times[500]-times[499], float64(time.Microsecond))
+	assert.InDelta(t, 15*time.Second, times[999], float64(time.Millisecond))
+	// No requests are sent during the zero QPS stage
+	assert.InDelta(t, 16*time.Second+100*time.Millisecond, times[1000], float64(time.Millisecond))
+	assert.InDelta(t, 17*time.Second, times[1009], float64(time.Millisecond))
+}
@@ -0,0 +1,118 @@
+/*
+Copyright (c) Facebook, Inc. and its affiliates.
+All rights reserved.
+
+This source code is licensed under the BSD-style license found in the
+LICENSE file in the root directory of this source tree.
+*/
+
+package tester
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pinterest/bender"
+)
+
+// TimedRequest is a request with the time it was originally sent at, it's used
+// to replay traces.
+type TimedRequest struct {
+	Time    time.Time
+	Request interface{}
+}
+
+// ErrInvalidTimestamp is returned when a timestamp cannot be parsed.
+var ErrInvalidTimestamp = errors.New("invalid timestamp")
+
+// ParseTimestamp parses a timestamp either in seconds (e.g. Unix time with
+// a fraction) or in the RFC 3339 format.
+func ParseTimestamp(s string) (time.Time, error) {
+	if seconds, err := strconv.ParseFloat(s, 64); err == nil {
+		whole, fraction := math.Modf(seconds)
+
+		return time.Unix(int64(whole), int64(fraction*float64(time.Second))), nil
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w, want: seconds or RFC 3339, got: %q", ErrInvalidTimestamp, s)
+	}
+
+	return t, nil
+}
+
+// ParseTimedLine splits an input line into the leading timestamp and the rest
+// of the line.
+func ParseTimedLine(line string) (time.Time, string, error) {
+	line = strings.TrimSpace(line)
+
+	timestamp, rest := line, ""
+	if i := strings.IndexAny(line, " \t"); i >= 0 {
+		timestamp, rest = line[:i], strings.TrimSpace(line[i:])
+	}
+
+	t, err := ParseTimestamp(timestamp)
+
+	return t, rest, err
+}
+
+// Trace replays requests with the original intervals between them divided by
+// the speed. The times of the requests are pushed in the same order in which
+// the requests are sent, the interval generator returns the intervals between
+// them. Time going backwards (e.g. when the trace starts over) gives a zero
+// interval.
+type Trace struct {
+	speed float64
+
+	mutex     sync.Mutex
+	intervals []time.Duration
+	last      time.Time
+	offset    time.Duration
+}
+
+// NewTrace returns a new trace replayed at the given speed.
+func NewTrace(speed float64) *Trace {
+	return &Trace{speed: speed}
+}
+
+// Push adds the time of the next request and returns its offset from the
+// beginning of the replay.
+func (t *Trace) Push(at time.Time) time.Duration {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	interval := time.Duration(0)
+	if !t.last.IsZero() && at.After(t.last) {
+		interval = time.Duration(float64(at.Sub(t.last)) / t.speed)
+	}
+
+	t.last = at
+	t.offset += interval
+	t.intervals = append(t.intervals, interval)
+
+	return t.offset
+}
+
+// IntervalGenerator returns an interval generator returning the intervals
+// between the pushed requests. A request must be pushed before it's sent.
+func (t *Trace) IntervalGenerator() bender.IntervalGenerator {
+	return func(_ int64) int64 {
+		t.mutex.Lock()
+		defer t.mutex.Unlock()
+
+		if len(t.intervals) == 0 {
+			return 0
+		}
+
+		interval := t.intervals[0]
+		t.intervals = t.intervals[1:]
+
+		return int64(interval)
+	}
+}
@@ -0,0 +1,63 @@
+/*
+Copyright (c) Facebook, Inc. and its affiliates.
+All rights reserved.
+
+This source code is licensed under the BSD-style license found in the
+LICENSE file in the root directory of this source tree.
+*/
+
+package tester_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/facebookincubator/fbender/tester"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTimestamp(t *testing.T) {
+	ts, err := tester.ParseTimestamp("1600000000.25")
+	require.NoError(t, err)
+	assert.Equal(t, time.Unix(1600000000, int64(250*time.Millisecond)), ts)
+
+	ts, err = tester.ParseTimestamp("2020-09-13T12:26:40.5Z")
+	require.NoError(t, err)
+	assert.Equal(t, time.Unix(1600000000, int64(500*time.Millisecond)).UTC(), ts)
+
+	_, err = tester.ParseTimestamp("yesterday")
+	assert.ErrorIs(t, err, tester.ErrInvalidTimestamp)
+	assert.EqualError(t, err, "invalid timestamp, want: seconds or RFC 3339, got: \"yesterday\"")
+}
+
+func TestParseTimedLine(t *testing.T) {
+	ts, rest, err := tester.ParseTimedLine("  12.5\texample.com  A ")
+	require.NoError(t, err)
+	assert.Equal(t, time.Unix(12, int64(500*time.Millisecond)), ts)
+	assert.Equal(t, "example.com  A", rest)
+
+	ts, rest, err = tester.ParseTimedLine("3")
+	require.NoError(t, err)
+	assert.Equal(t, time.Unix(3, 0), ts)
+	assert.Equal(t, "", rest)
+
+	_, _, err = tester.ParseTimedLine("example.com A")
+	assert.ErrorIs(t, err, tester.ErrInvalidTimestamp)
+}
+
+func TestTrace(t *testing.T) {
+	trace := tester.NewTrace(2)
+	intervals := trace.IntervalGenerator()
+	start := time.Unix(100, 0)
+
+	// Intervals are divided by the speed and time going backwards gives zero.
+	assert.Equal(t, time.Duration(0), trace.Push(start))
+	assert.Equal(t, time.Second, trace.Push(start.Add(2*time.Second)))
+	assert.Equal(t, time.Second, trace.Push(start))
+	assert.Equal(t, 1500*time.Millisecond, trace.Push(start.Add(time.Second)))
+
+	for _, expected := range []time.Duration{0, time.Second, 0, 500 * time.Millisecond, 0} {
+		assert.Equal(t, int64(expected), intervals(0))
+	}
+}
@@ -9,22 +9,195 @@ LICENSE file in the root directory of this source tree.
 package run
 
 import (
+	"context"
+	"errors"
+	"sync/atomic"
 	"time"
 
 	"github.com/facebookincubator/fbender/log"
 	"github.com/facebookincubator/fbender/tester"
 )
 
-// checkConstraints loops through given constraints and returns whether all of
-// them have been met.
-func checkConstraints(start time.Time, duration time.Duration, constraints ...*tester.Constraint) bool {
-	for _, constraint := range constraints {
-		if err := constraint.Check(start, duration); err != nil {
-			log.Errorf("Error checking %q: %v\n", constraint.String(), err)
+// ErrInterrupted is returned when the tests are interrupted before completion.
+var ErrInterrupted = errors.New("tests interrupted")
+
+// TimeoutOptions represents options which provide a requests timeout.
+type TimeoutOptions interface {
+	GetTimeout() time.Duration
+}
+
+// InflightOptions represents options which limit the number of in-flight
+// requests in throughput tests.
+type InflightOptions interface {
+	GetMaxInflight() int
+	GetDropOverflow() bool
+}
+
+// inflightLimit returns the limit of in-flight requests (0 means no limit) and
+// whether the requests over the limit should be dropped.
+func inflightLimit(o interface{}) (int, bool) {
+	opts, ok := o.(InflightOptions)
+	if !ok {
+		return 0, false
+	}
+
+	return opts.GetMaxInflight(), opts.GetDropOverflow()
+}
+
+// AbortOptions represents options which allow to abort a constraints test
+// once the constraints are violated for a sustained period.
+type AbortOptions interface {
+	GetAbortOn() time.Duration
+	GetAbortWindow() time.Duration
+}
+
+// abortInterval is the interval between the live constraints checks.
+const abortInterval = time.Second
+
+// watchConstraints returns a context for a single constraints test. If the
+// options specify an abort period, constraints are checked on a rolling window
+// during the measured part of the test and the context is canceled once they
+// have been violated for the whole period. The first returned function marks
+// the start of the measured part (e.g. after the warm-up), the windows never
+// reach before it. The second one stops watching and reports whether the test
+// has been aborted.
+func watchConstraints(ctx context.Context, o interface{},
+	cs ...*tester.Constraint) (context.Context, func(time.Time), func() bool) {
+	test, cancel := context.WithCancel(ctx)
+
+	opts, ok := o.(AbortOptions)
+	if !ok || opts.GetAbortOn() <= 0 || len(cs) == 0 {
+		return test, func(time.Time) {}, func() bool {
+			cancel()
 
 			return false
 		}
 	}
 
-	return true
+	var aborted int32
+
+	measured := make(chan time.Time, 1)
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+
+	go func() {
+		defer close(stopped)
+
+		var start time.Time
+		select {
+		case <-done:
+			return
+		case <-test.Done():
+			return
+		case start = <-measured:
+		}
+
+		ticker := time.NewTicker(abortInterval)
+		defer ticker.Stop()
+
+		var since time.Time
+
+		for {
+			var now time.Time
+			select {
+			case <-done:
+				return
+			case <-test.Done():
+				return
+			case now = <-ticker.C:
+			}
+
+			from := now.Add(-opts.GetAbortWindow())
+			if from.Before(start) {
+				from = start
+			}
+
+			constraint, err := violatedConstraint(from, now.Sub(from), cs...)
+			if err == nil {
+				since = time.Time{}
+
+				continue
+			}
+
+			if since.IsZero() {
+				since = now
+			}
+
+			if now.Sub(since) >= opts.GetAbortOn() {
+				log.Errorf("Aborting the test, %q violated for %s: %v\n", constraint.String(), opts.GetAbortOn(), err)
+				atomic.StoreInt32(&aborted, 1)
+				cancel()
+
+				return
+			}
+		}
+	}()
+
+	measure := func(start time.Time) {
+		measured <- start
+	}
+
+	return test, measure, func() bool {
+		close(done)
+		<-stopped
+		cancel()
+
+		return atomic.LoadInt32(&aborted) == 1
+	}
+}
+
+// violatedConstraint checks constraints for the given window and returns the
+// first one which is not satisfied. Constraints which cannot be checked, e.g.
+// because there are no data points yet, are not considered violated.
+func violatedConstraint(start time.Time, duration time.Duration, cs ...*tester.Constraint) (*tester.Constraint, error) {
+	for _, constraint := range cs {
+		if err := constraint.Check(start, duration); errors.Is(err, tester.ErrNotSatisfied) {
+			return constraint, err
+		}
+	}
+
+	return nil, nil
+}
+
+// verify verifies a finished test if the runner implements tester.Verifier.
+func verify(r interface{}, test int, o interface{}) error {
+	if verifier, ok := r.(tester.Verifier); ok {
+		//nolint:wrapcheck
+		return verifier.Verify(test, o)
+	}
+
+	return nil
+}
+
+// DrainContext returns a context which is canceled once the requests timeout
+// passes after the parent context is done, giving the in-flight requests time
+// to finish. If options don't specify a timeout the context is canceled only
+// when the returned cancel function is called. The loaders use it for their
+// request executors as well.
+func DrainContext(ctx context.Context, o interface{}) (context.Context, context.CancelFunc) {
+	drain, cancel := context.WithCancel(context.Background())
+
+	opts, ok := o.(TimeoutOptions)
+	if !ok {
+		return drain, cancel
+	}
+
+	go func() {
+		select {
+		case <-drain.Done():
+			return
+		case <-ctx.Done():
+		}
+
+		timer := time.NewTimer(opts.GetTimeout())
+		defer timer.Stop()
+
+		select {
+		case <-drain.Done():
+		case <-timer.C:
+			cancel()
+		}
+	}()
+
+	return drain, cancel
 }
@@ -9,6 +9,7 @@ LICENSE file in the root directory of this source tree.
 package run_test
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"testing"
@@ -43,7 +44,7 @@ func (m *MockedTester) AfterEach(options interface{}) {
 	m.Called(options)
 }
 
-func (m *MockedTester) RequestExecutor(options interface{}) (bender.RequestExecutor, error) {
+func (m *MockedTester) RequestExecutor(_ context.Context, options interface{}) (bender.RequestExecutor, error) {
 	args := m.Called(options)
 
 	return m.DummyExecutor, args.Error(0)
@@ -9,14 +9,15 @@ LICENSE file in the root directory of this source tree.
 package run
 
 import (
-	"time"
+	"context"
 
 	"github.com/facebookincubator/fbender/tester"
 	"github.com/pinterest/bender"
 )
 
 // LoadTestConcurrencyFixed runs predefined set of throughput tests.
-func LoadTestConcurrencyFixed(r tester.ConcurrencyRunner, o interface{}, ws ...tester.Workers) error {
+func LoadTestConcurrencyFixed(ctx context.Context, r tester.ConcurrencyRunner, o interface{},
+	ws ...tester.Workers) error {
 	t := r.Tester()
 	if err := t.Before(o); err != nil {
 		//nolint:wrapcheck
@@ -26,17 +27,28 @@ func LoadTestConcurrencyFixed(r tester.ConcurrencyRunner, o interface{}, ws ...t
 	defer t.After(o)
 
 	for _, workers := range ws {
-		if err := loadTestConcurrency(r, t, o, workers); err != nil {
+		if ctx.Err() != nil {
+			return ErrInterrupted
+		}
+
+		err := repeatTest(ctx, r, o, workers, func(ctx context.Context, measure func()) error {
+			return loadTestConcurrency(ctx, r, t, o, workers, measure)
+		})
+		if err != nil {
 			return err
 		}
 	}
 
+	if ctx.Err() != nil {
+		return ErrInterrupted
+	}
+
 	return nil
 }
 
 // LoadTestConcurrencyConstraints automatically tries to find a breakpoint based on provided constraints checks.
-func LoadTestConcurrencyConstraints(r tester.ConcurrencyRunner, o interface{}, start tester.Workers, g tester.Growth,
-	cs ...*tester.Constraint) error {
+func LoadTestConcurrencyConstraints(ctx context.Context, r tester.ConcurrencyRunner, o interface{},
+	start tester.Workers, g tester.Growth, cs ...*tester.Constraint) error {
 	t := r.Tester()
 	if err := t.Before(o); err != nil {
 		//nolint:wrapcheck
@@ -47,15 +59,23 @@ func LoadTestConcurrencyConstraints(r tester.ConcurrencyRunner, o interface{}, s
 
 	workers := start
 	for workers > 0 {
-		startTime := time.Now()
+		if ctx.Err() != nil {
+			return ErrInterrupted
+		}
 
-		if err := loadTestConcurrency(r, t, o, workers); err != nil {
+		ok, err := checkTrials(ctx, r, o, workers, func(ctx context.Context, measure func()) error {
+			return loadTestConcurrency(ctx, r, t, o, workers, measure)
+		}, cs...)
+		if err != nil {
 			return err
 		}
 
-		duration := time.Since(startTime)
+		// Partial results of an interrupted test are not representative.
+		if ctx.Err() != nil {
+			return ErrInterrupted
+		}
 
-		if checkConstraints(startTime, duration, cs...) {
+		if ok {
 			workers = g.OnSuccess(workers)
 		} else {
 			workers = g.OnFail(workers)
@@ -65,21 +85,37 @@ func LoadTestConcurrencyConstraints(r tester.ConcurrencyRunner, o interface{}, s
 	return nil
 }
 
-// loadTestConcurrency runs a single test for a desired QPS.
-func loadTestConcurrency(r tester.ConcurrencyRunner, t tester.Tester, o interface{}, workers tester.Workers) error {
+// loadTestConcurrency runs a single test for a desired QPS. The measured load
+// starts once the runner is prepared (and the target is warmed up).
+func loadTestConcurrency(ctx context.Context, r tester.ConcurrencyRunner, t tester.Tester, o interface{},
+	workers tester.Workers, measure func()) error {
 	if err := t.BeforeEach(o); err != nil {
 		//nolint:wrapcheck
 		return err
 	}
+
 	defer t.AfterEach(o)
 
-	if err := r.Before(workers, o); err != nil {
+	if err := r.Before(ctx, workers, o); err != nil {
 		//nolint:wrapcheck
 		return err
 	}
+
 	defer r.After(workers, o)
 
-	executor, err := t.RequestExecutor(o)
+	measure()
+
+	if loader, ok := r.(tester.Loader); ok {
+		loader.Load(ctx, r.Recorder())
+		bender.Record(r.Recorder(), r.Recorders()...)
+
+		return nil
+	}
+
+	drain, cancel := DrainContext(ctx, o)
+	defer cancel()
+
+	executor, err := t.RequestExecutor(drain, o)
 	if err != nil {
 		//nolint:wrapcheck
 		return err
@@ -9,6 +9,7 @@ LICENSE file in the root directory of this source tree.
 package run_test
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
 	"testing"
@@ -25,7 +26,7 @@ type MockedConcurrencyRunner struct {
 	mock.Mock
 }
 
-func (m *MockedConcurrencyRunner) Before(qps tester.QPS, options interface{}) error {
+func (m *MockedConcurrencyRunner) Before(_ context.Context, qps tester.QPS, options interface{}) error {
 	args := m.Called(qps, options)
 
 	return args.Error(0)
@@ -112,7 +113,7 @@ func (s *ConcurrencyFixedTestSuite) TestTester__Before_Error() {
 	s.runner.On("Tester").Return(s.tester).Once()
 	s.tester.On("Before", s.options).Return(ErrDummy).Once()
 
-	err := run.LoadTestConcurrencyFixed(s.runner, s.options, 10)
+	err := run.LoadTestConcurrencyFixed(context.Background(), s.runner, s.options, 10)
 	s.Assert().Equal(ErrDummy, err)
 
 	s.tester.AssertExpectations(s.T())
@@ -126,7 +127,7 @@ func (s *ConcurrencyFixedTestSuite) TestTester__BeforeEach_Error() {
 	s.tester.On("After", s.options).Once()
 	s.tester.On("BeforeEach", s.options).Return(ErrDummy).Once()
 
-	err := run.LoadTestConcurrencyFixed(s.runner, s.options, 10)
+	err := run.LoadTestConcurrencyFixed(context.Background(), s.runner, s.options, 10)
 	s.Assert().Equal(ErrDummy, err)
 
 	s.tester.AssertExpectations(s.T())
@@ -138,7 +139,7 @@ func (s *ConcurrencyFixedTestSuite) TestTester__BeforeEach_Error() {
 	s.tester.On("After", s.options).Once()
 	s.tester.On("BeforeEach", s.options).Return(ErrDummy).Once()
 
-	err = run.LoadTestConcurrencyFixed(s.runner, s.options, 10, 20)
+	err = run.LoadTestConcurrencyFixed(context.Background(), s.runner, s.options, 10, 20)
 	s.Assert().Equal(ErrDummy, err)
 
 	s.tester.AssertExpectations(s.T())
@@ -154,7 +155,7 @@ func (s *ConcurrencyFixedTestSuite) TestRunner__Before_Error() {
 	s.tester.On("AfterEach", s.options).Once()
 	s.runner.On("Before", 10, s.options).Return(ErrDummy).Once()
 
-	err := run.LoadTestConcurrencyFixed(s.runner, s.options, 10)
+	err := run.LoadTestConcurrencyFixed(context.Background(), s.runner, s.options, 10)
 	s.Assert().Equal(ErrDummy, err)
 
 	s.tester.AssertExpectations(s.T())
@@ -167,7 +168,7 @@ func (s *ConcurrencyFixedTestSuite) TestRunner__Before_Error() {
 	s.tester.On("BeforeEach", s.options).Return(nil).Once()
 	s.tester.On("AfterEach", s.options).Once()
 	s.runner.On("Before", 10, s.options).Return(ErrDummy).Once()
-	err = run.LoadTestConcurrencyFixed(s.runner, s.options, 10, 20)
+	err = run.LoadTestConcurrencyFixed(context.Background(), s.runner, s.options, 10, 20)
 	s.Assert().Equal(ErrDummy, err)
 	s.tester.AssertExpectations(s.T())
 	s.runner.AssertExpectations(s.T())
@@ -184,7 +185,7 @@ func (s *ConcurrencyFixedTestSuite) TestTester__RequestExecutor_Error() {
 	s.runner.On("After", 10, s.options).Once()
 	s.tester.On("RequestExecutor", s.options).Return(ErrDummy).Once()
 
-	err := run.LoadTestConcurrencyFixed(s.runner, s.options, 10)
+	err := run.LoadTestConcurrencyFixed(context.Background(), s.runner, s.options, 10)
 	s.Assert().Equal(ErrDummy, err)
 
 	s.tester.AssertExpectations(s.T())
@@ -200,7 +201,7 @@ func (s *ConcurrencyFixedTestSuite) TestTester__RequestExecutor_Error() {
 	s.runner.On("After", 10, s.options).Once()
 	s.tester.On("RequestExecutor", s.options).Return(ErrDummy).Once()
 
-	err = run.LoadTestConcurrencyFixed(s.runner, s.options, 10, 20)
+	err = run.LoadTestConcurrencyFixed(context.Background(), s.runner, s.options, 10, 20)
 	s.Assert().Equal(ErrDummy, err)
 
 	s.tester.AssertExpectations(s.T())
@@ -212,7 +213,7 @@ func (s *ConcurrencyFixedTestSuite) TestZero() {
 	s.tester.On("Before", s.options).Return(nil).Once()
 	s.tester.On("After", s.options).Once()
 
-	err := run.LoadTestConcurrencyFixed(s.runner, s.options)
+	err := run.LoadTestConcurrencyFixed(context.Background(), s.runner, s.options)
 	s.Assert().NoError(err)
 
 	s.tester.AssertExpectations(s.T())
@@ -243,7 +244,7 @@ func (s *ConcurrencyFixedTestSuite) TestSingle() {
 	s.runner.On("Recorder").Return(recorder).Twice()
 	s.runner.On("Recorders").Return([]bender.Recorder{}).Once()
 
-	err := run.LoadTestConcurrencyFixed(s.runner, s.options, 10)
+	err := run.LoadTestConcurrencyFixed(context.Background(), s.runner, s.options, 10)
 	s.Assert().NoError(err)
 
 	s.tester.AssertExpectations(s.T())
@@ -271,7 +272,7 @@ func (s *ConcurrencyFixedTestSuite) TestSingle() {
 	s.runner.On("Recorder").Return(recorder).Twice()
 	s.runner.On("Recorders").Return([]bender.Recorder{}).Once()
 
-	err = run.LoadTestConcurrencyFixed(s.runner, s.options, 10)
+	err = run.LoadTestConcurrencyFixed(context.Background(), s.runner, s.options, 10)
 	s.Assert().NoError(err)
 
 	s.tester.AssertExpectations(s.T())
@@ -320,13 +321,29 @@ func (s *ConcurrencyFixedTestSuite) TestMultiple() {
 	s.runner.On("Recorder").Return(recorder).Twice()
 
 	// Run tests
-	err := run.LoadTestConcurrencyFixed(s.runner, s.options, 10, 20)
+	err := run.LoadTestConcurrencyFixed(context.Background(), s.runner, s.options, 10, 20)
 	s.Assert().NoError(err)
 
 	s.tester.AssertExpectations(s.T())
 	s.runner.AssertExpectations(s.T())
 }
 
+func (s *ConcurrencyFixedTestSuite) TestInterrupted() {
+	// Tests should not be started once the context is canceled
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	s.runner.On("Tester").Return(s.tester).Once()
+	s.tester.On("Before", s.options).Return(nil).Once()
+	s.tester.On("After", s.options).Once()
+
+	err := run.LoadTestConcurrencyFixed(ctx, s.runner, s.options, 10, 20)
+	s.Assert().Equal(run.ErrInterrupted, err)
+
+	s.tester.AssertExpectations(s.T())
+	s.runner.AssertExpectations(s.T())
+}
+
 func TestConcurrencyFixedTestSuite(t *testing.T) {
 	suite.Run(t, new(ConcurrencyFixedTestSuite))
 }
@@ -366,7 +383,7 @@ func (s *ConcurrencyConstraintsTestSuite) TestTester__Before_Error() {
 	s.runner.On("Tester").Return(s.tester).Once()
 	s.tester.On("Before", s.options).Return(ErrDummy).Once()
 
-	err := run.LoadTestConcurrencyConstraints(s.runner, s.options, 10, s.growth)
+	err := run.LoadTestConcurrencyConstraints(context.Background(), s.runner, s.options, 10, s.growth)
 	s.Assert().Equal(ErrDummy, err)
 
 	s.tester.AssertExpectations(s.T())
@@ -380,7 +397,7 @@ func (s *ConcurrencyConstraintsTestSuite) TestTester__BeforeEach_Error() {
 	s.tester.On("After", s.options).Once()
 	s.tester.On("BeforeEach", s.options).Return(ErrDummy).Once()
 
-	err := run.LoadTestConcurrencyConstraints(s.runner, s.options, 10, s.growth)
+	err := run.LoadTestConcurrencyConstraints(context.Background(), s.runner, s.options, 10, s.growth)
 	s.Assert().Equal(ErrDummy, err)
 
 	s.tester.AssertExpectations(s.T())
@@ -396,7 +413,7 @@ func (s *ConcurrencyConstraintsTestSuite) TestRunner__Before_Error() {
 	s.tester.On("AfterEach", s.options).Once()
 	s.runner.On("Before", 10, s.options).Return(ErrDummy).Once()
 
-	err := run.LoadTestConcurrencyConstraints(s.runner, s.options, 10, s.growth)
+	err := run.LoadTestConcurrencyConstraints(context.Background(), s.runner, s.options, 10, s.growth)
 	s.Assert().Equal(ErrDummy, err)
 
 	s.tester.AssertExpectations(s.T())
@@ -414,7 +431,7 @@ func (s *ConcurrencyConstraintsTestSuite) TestTester__RequestExecutor_Error() {
 	s.runner.On("After", 10, s.options).Once()
 	s.tester.On("RequestExecutor", s.options).Return(ErrDummy).Once()
 
-	err := run.LoadTestConcurrencyConstraints(s.runner, s.options, 10, s.growth)
+	err := run.LoadTestConcurrencyConstraints(context.Background(), s.runner, s.options, 10, s.growth)
 	s.Assert().Equal(ErrDummy, err)
 
 	s.tester.AssertExpectations(s.T())
@@ -448,7 +465,7 @@ func (s *ConcurrencyConstraintsTestSuite) TestSingle_OnSuccess() {
 
 	s.growth.On("OnSuccess", 10).Return(0).Once()
 
-	err := run.LoadTestConcurrencyConstraints(s.runner, s.options, 10, s.growth, c.Constraint())
+	err := run.LoadTestConcurrencyConstraints(context.Background(), s.runner, s.options, 10, s.growth, c.Constraint())
 	s.Assert().NoError(err)
 
 	s.tester.AssertExpectations(s.T())
@@ -483,7 +500,7 @@ func (s *ConcurrencyConstraintsTestSuite) TestSingle_OnFail() {
 
 	s.growth.On("OnFail", 10).Return(0).Once()
 
-	err := run.LoadTestConcurrencyConstraints(s.runner, s.options, 10, s.growth, c.Constraint())
+	err := run.LoadTestConcurrencyConstraints(context.Background(), s.runner, s.options, 10, s.growth, c.Constraint())
 	s.Assert().NoError(err)
 
 	s.tester.AssertExpectations(s.T())
@@ -539,7 +556,7 @@ func (s *ConcurrencyConstraintsTestSuite) TestMultiple() {
 	s.growth.On("OnSuccess", 10).Return(20).Once()
 	s.growth.On("OnFail", 20).Return(0).Once()
 
-	err := run.LoadTestConcurrencyConstraints(s.runner, s.options, 10, s.growth, c.Constraint())
+	err := run.LoadTestConcurrencyConstraints(context.Background(), s.runner, s.options, 10, s.growth, c.Constraint())
 	s.Assert().NoError(err)
 
 	s.tester.AssertExpectations(s.T())
@@ -548,6 +565,23 @@ func (s *ConcurrencyConstraintsTestSuite) TestMultiple() {
 	c.AssertExpectations(s.T())
 }
 
+func (s *ConcurrencyConstraintsTestSuite) TestInterrupted() {
+	// Growth should not be consulted once the context is canceled
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	s.runner.On("Tester").Return(s.tester).Once()
+	s.tester.On("Before", s.options).Return(nil).Once()
+	s.tester.On("After", s.options).Once()
+
+	err := run.LoadTestConcurrencyConstraints(ctx, s.runner, s.options, 10, s.growth)
+	s.Assert().Equal(run.ErrInterrupted, err)
+
+	s.tester.AssertExpectations(s.T())
+	s.runner.AssertExpectations(s.T())
+	s.growth.AssertExpectations(s.T())
+}
+
 func TestConcurrencyConstraintsTestSuite(t *testing.T) {
 	suite.Run(t, new(ConcurrencyConstraintsTestSuite))
 }
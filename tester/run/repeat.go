@@ -0,0 +1,166 @@
+/*
+Copyright (c) Facebook, Inc. and its affiliates.
+All rights reserved.
+
+This source code is licensed under the BSD-style license found in the
+LICENSE file in the root directory of this source tree.
+*/
+
+package run
+
+import (
+	"context"
+	"time"
+
+	"github.com/facebookincubator/fbender/log"
+	"github.com/facebookincubator/fbender/tester"
+)
+
+// RepeatOptions represents options which make every test run multiple times.
+type RepeatOptions interface {
+	GetRepeat() int
+	GetCooldown() time.Duration
+}
+
+// trial runs a single trial of a test. It calls measure once the runner is
+// prepared (and the target is warmed up), just before the measured load starts.
+type trial func(ctx context.Context, measure func()) error
+
+// repeatOptions returns the number of trials of every test and the cooldown
+// between them.
+func repeatOptions(o interface{}) (int, time.Duration) {
+	opts, ok := o.(RepeatOptions)
+	if !ok || opts.GetRepeat() < 1 {
+		return 1, 0
+	}
+
+	return opts.GetRepeat(), opts.GetCooldown()
+}
+
+// cooldown waits before the next trial. It returns false if the context is
+// canceled in the meantime.
+func cooldown(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// summarize lets the runner summarize the trials of a repeated test.
+func summarize(r interface{}, repeat int, test int, o interface{}) {
+	if summarizer, ok := r.(tester.Summarizer); ok && repeat > 1 {
+		summarizer.Summarize(test, o)
+	}
+}
+
+// repeatTest runs all the trials of a test. It stops once the context is
+// canceled.
+func repeatTest(ctx context.Context, r interface{}, o interface{}, test int, run trial) error {
+	repeat, pause := repeatOptions(o)
+	defer summarize(r, repeat, test, o)
+
+	for i := 0; i < repeat; i++ {
+		if i > 0 && !cooldown(ctx, pause) {
+			return nil
+		}
+
+		if err := run(ctx, func() {}); err != nil {
+			return err
+		}
+
+		if ctx.Err() != nil {
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// checkTrials runs all the trials of a constraints test and returns whether
+// the constraints are satisfied by the data points gathered in all of them.
+// It stops once the context is canceled or one of the trials is aborted.
+func checkTrials(ctx context.Context, r interface{}, o interface{}, test int, run trial,
+	cs ...*tester.Constraint) (bool, error) {
+	repeat, pause := repeatOptions(o)
+	defer summarize(r, repeat, test, o)
+
+	points := make(map[*tester.Constraint][]tester.DataPoint)
+
+	for i := 0; i < repeat; i++ {
+		if i > 0 && !cooldown(ctx, pause) {
+			return false, nil
+		}
+
+		// The constraints cover only the measured part of the trial
+		var start time.Time
+
+		watched, watch, stop := watchConstraints(ctx, o, cs...)
+		err := run(watched, func() {
+			start = time.Now()
+			watch(start)
+		})
+		aborted := stop()
+
+		if err != nil {
+			return false, err
+		}
+
+		// Partial results of an interrupted test are not representative.
+		if ctx.Err() != nil || aborted || start.IsZero() {
+			return false, nil
+		}
+
+		if !fetchConstraints(start, time.Since(start), points, cs...) {
+			return false, nil
+		}
+	}
+
+	return checkPoints(points, cs...), nil
+}
+
+// fetchConstraints appends the data points of every simple constraint metric
+// to the points gathered so far. It returns false if any of the metrics fails.
+func fetchConstraints(start time.Time, duration time.Duration, points map[*tester.Constraint][]tester.DataPoint,
+	cs ...*tester.Constraint) bool {
+	for _, constraint := range cs {
+		for _, simple := range constraint.Simple() {
+			fetched, err := simple.Metric.Fetch(start, duration)
+			if err != nil {
+				log.Errorf("Error checking %q: %v\n", simple.String(), err)
+
+				return false
+			}
+
+			// Keep the empty data points apart from no data points at all
+			if points[simple] == nil && fetched != nil {
+				points[simple] = make([]tester.DataPoint, 0, len(fetched))
+			}
+
+			points[simple] = append(points[simple], fetched...)
+		}
+	}
+
+	return true
+}
+
+// checkPoints loops through given constraints and returns whether all of them
+// are satisfied by the data points of their simple constraints.
+func checkPoints(points map[*tester.Constraint][]tester.DataPoint, cs ...*tester.Constraint) bool {
+	for _, constraint := range cs {
+		err := constraint.Evaluate(func(simple *tester.Constraint) error {
+			return simple.CheckPoints(points[simple])
+		})
+		if err != nil {
+			log.Errorf("Error checking %q: %v\n", constraint.String(), err)
+
+			return false
+		}
+	}
+
+	return true
+}
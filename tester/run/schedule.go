@@ -0,0 +1,118 @@
+/*
+Copyright (c) Facebook, Inc. and its affiliates.
+All rights reserved.
+
+This source code is licensed under the BSD-style license found in the
+LICENSE file in the root directory of this source tree.
+*/
+
+package run
+
+import (
+	"sync"
+	"time"
+
+	"github.com/facebookincubator/fbender/tester"
+	"github.com/pinterest/bender"
+)
+
+// StartThroughput starts a throughput load test outside of the tests run by
+// this package (e.g. a warm-up) with the in-flight requests limit of the
+// options. The recorder is closed once all the requests are completed.
+func StartThroughput(intervals bender.IntervalGenerator, requests chan interface{},
+	executor bender.RequestExecutor, recorder chan interface{}, o interface{}) {
+	maxInflight, drop := inflightLimit(o)
+	startThroughput(intervals, requests, executor, recorder, maxInflight, drop)
+}
+
+// startThroughput starts a load test in which the intervals between requests
+// are controlled by the interval generator. Unlike bender.LoadTestThroughput
+// it keeps track of the schedule, every request is intended to be sent an
+// interval after the previous one was intended to be sent regardless of when
+// it was actually sent. Apart from the standard bender events it sends
+// tester.ScheduledRequestEvent for every completed request.
+//
+// If maxInflight is positive, at most maxInflight requests are executed at the
+// same time. Once the limit is reached the next request either waits for one
+// of the in-flight requests to finish or, if drop is set, is not executed at
+// all and a tester.DroppedRequestEvent is sent instead.
+func startThroughput(intervals bender.IntervalGenerator, requests chan interface{},
+	executor bender.RequestExecutor, recorder chan interface{}, maxInflight int, drop bool) {
+	var inflight chan struct{}
+	if maxInflight > 0 {
+		inflight = make(chan struct{}, maxInflight)
+	}
+
+	go func() {
+		start := time.Now().UnixNano()
+		recorder <- &bender.StartEvent{Start: start}
+
+		var wg sync.WaitGroup
+
+		scheduled := start
+		for request := range requests {
+			scheduled += intervals(scheduled)
+
+			wait, overage := scheduled-time.Now().UnixNano(), int64(0)
+			if wait < 0 {
+				wait, overage = 0, -wait
+			}
+
+			recorder <- &bender.WaitEvent{Wait: wait, Overage: overage}
+			time.Sleep(time.Duration(wait))
+
+			if !acquire(inflight, drop) {
+				recorder <- &tester.DroppedRequestEvent{Time: time.Now().UnixNano(), Request: request}
+
+				continue
+			}
+
+			wg.Add(1)
+
+			go func(request interface{}, scheduled int64) {
+				defer wg.Done()
+				defer release(inflight)
+
+				recorder <- &bender.StartRequestEvent{Time: time.Now().UnixNano(), Request: request}
+				start := time.Now().UnixNano()
+				response, err := executor(start, request)
+				end := time.Now().UnixNano()
+				recorder <- &bender.EndRequestEvent{Start: start, End: end, Response: response, Err: err}
+				recorder <- &tester.ScheduledRequestEvent{Scheduled: scheduled, Start: start, End: end, Err: err}
+			}(request, scheduled)
+		}
+
+		wg.Wait()
+		recorder <- &bender.EndEvent{Start: start, End: time.Now().UnixNano()}
+		close(recorder)
+	}()
+}
+
+// acquire takes an in-flight slot. It blocks until a slot is available unless
+// drop is set, in which case it returns whether a slot has been taken. A nil
+// slots channel means no limit.
+func acquire(slots chan struct{}, drop bool) bool {
+	if slots == nil {
+		return true
+	}
+
+	if !drop {
+		slots <- struct{}{}
+
+		return true
+	}
+
+	select {
+	case slots <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+// release frees an in-flight slot taken by acquire.
+func release(slots chan struct{}) {
+	if slots != nil {
+		<-slots
+	}
+}
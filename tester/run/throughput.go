@@ -9,14 +9,14 @@ LICENSE file in the root directory of this source tree.
 package run
 
 import (
-	"time"
+	"context"
 
 	"github.com/facebookincubator/fbender/tester"
 	"github.com/pinterest/bender"
 )
 
 // LoadTestThroughputFixed runs predefined set of throughput tests.
-func LoadTestThroughputFixed(r tester.ThroughputRunner, o interface{}, qs ...tester.QPS) error {
+func LoadTestThroughputFixed(ctx context.Context, r tester.ThroughputRunner, o interface{}, qs ...tester.QPS) error {
 	t := r.Tester()
 	if err := t.Before(o); err != nil {
 		//nolint:wrapcheck
@@ -26,17 +26,28 @@ func LoadTestThroughputFixed(r tester.ThroughputRunner, o interface{}, qs ...tes
 	defer t.After(o)
 
 	for _, qps := range qs {
-		if err := loadTestThroughput(r, t, o, qps); err != nil {
+		if ctx.Err() != nil {
+			return ErrInterrupted
+		}
+
+		err := repeatTest(ctx, r, o, qps, func(ctx context.Context, measure func()) error {
+			return loadTestThroughput(ctx, r, t, o, qps, measure)
+		})
+		if err != nil {
 			return err
 		}
 	}
 
+	if ctx.Err() != nil {
+		return ErrInterrupted
+	}
+
 	return nil
 }
 
 // LoadTestThroughputConstraints automatically tries to find a breakpoint based on provided constraints checks.
-func LoadTestThroughputConstraints(r tester.ThroughputRunner, o interface{}, start tester.QPS, g tester.Growth,
-	cs ...*tester.Constraint) error {
+func LoadTestThroughputConstraints(ctx context.Context, r tester.ThroughputRunner, o interface{}, start tester.QPS,
+	g tester.Growth, cs ...*tester.Constraint) error {
 	t := r.Tester()
 	if err := t.Before(o); err != nil {
 		//nolint:wrapcheck
@@ -47,15 +58,23 @@ func LoadTestThroughputConstraints(r tester.ThroughputRunner, o interface{}, sta
 
 	qps := start
 	for qps > 0 {
-		startTime := time.Now()
+		if ctx.Err() != nil {
+			return ErrInterrupted
+		}
 
-		if err := loadTestThroughput(r, t, o, qps); err != nil {
+		ok, err := checkTrials(ctx, r, o, qps, func(ctx context.Context, measure func()) error {
+			return loadTestThroughput(ctx, r, t, o, qps, measure)
+		}, cs...)
+		if err != nil {
 			return err
 		}
 
-		duration := time.Since(startTime)
+		// Partial results of an interrupted test are not representative.
+		if ctx.Err() != nil {
+			return ErrInterrupted
+		}
 
-		if checkConstraints(startTime, duration, cs...) {
+		if ok {
 			qps = g.OnSuccess(qps)
 		} else {
 			qps = g.OnFail(qps)
@@ -65,8 +84,10 @@ func LoadTestThroughputConstraints(r tester.ThroughputRunner, o interface{}, sta
 	return nil
 }
 
-// loadTestThroughput runs a single test for a desired QPS.
-func loadTestThroughput(r tester.ThroughputRunner, t tester.Tester, o interface{}, qps tester.QPS) error {
+// loadTestThroughput runs a single test for a desired QPS. The measured load
+// starts once the runner is prepared (and the target is warmed up).
+func loadTestThroughput(ctx context.Context, r tester.ThroughputRunner, t tester.Tester, o interface{},
+	qps tester.QPS, measure func()) error {
 	if err := t.BeforeEach(o); err != nil {
 		//nolint:wrapcheck
 		return err
@@ -74,21 +95,34 @@ func loadTestThroughput(r tester.ThroughputRunner, t tester.Tester, o interface{
 
 	defer t.AfterEach(o)
 
-	if err := r.Before(qps, o); err != nil {
+	if err := r.Before(ctx, qps, o); err != nil {
 		//nolint:wrapcheck
 		return err
 	}
 
 	defer r.After(qps, o)
 
-	executor, err := t.RequestExecutor(o)
+	measure()
+
+	if loader, ok := r.(tester.Loader); ok {
+		loader.Load(ctx, r.Recorder())
+		bender.Record(r.Recorder(), r.Recorders()...)
+
+		return verify(r, qps, o)
+	}
+
+	drain, cancel := DrainContext(ctx, o)
+	defer cancel()
+
+	executor, err := t.RequestExecutor(drain, o)
 	if err != nil {
 		//nolint:wrapcheck
 		return err
 	}
 
-	bender.LoadTestThroughput(r.Intervals(), r.Requests(), executor, r.Recorder())
+	maxInflight, drop := inflightLimit(o)
+	startThroughput(r.Intervals(), r.Requests(), executor, r.Recorder(), maxInflight, drop)
 	bender.Record(r.Recorder(), r.Recorders()...)
 
-	return nil
+	return verify(r, qps, o)
 }
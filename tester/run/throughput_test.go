@@ -9,9 +9,11 @@ LICENSE file in the root directory of this source tree.
 package run_test
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
 	"testing"
+	"time"
 
 	"github.com/facebookincubator/fbender/log"
 	"github.com/facebookincubator/fbender/tester"
@@ -25,7 +27,7 @@ type MockedThroughputRunner struct {
 	mock.Mock
 }
 
-func (m *MockedThroughputRunner) Before(qps tester.QPS, options interface{}) error {
+func (m *MockedThroughputRunner) Before(_ context.Context, qps tester.QPS, options interface{}) error {
 	args := m.Called(qps, options)
 
 	return args.Error(0)
@@ -80,6 +82,70 @@ func (m *MockedThroughputRunner) Recorders() []bender.Recorder {
 	panic(fmt.Sprintf("assert: arguments: RecorderSlice(0) failed because object wasn't correct type: %v", args.Get(0)))
 }
 
+type MockedLoadingThroughputRunner struct {
+	MockedThroughputRunner
+}
+
+func (m *MockedLoadingThroughputRunner) Load(_ context.Context, recorder chan interface{}) {
+	m.Called(recorder)
+	close(recorder)
+}
+
+// MockedVerifyingThroughputRunner verifies the test once it's finished.
+type MockedVerifyingThroughputRunner struct {
+	MockedLoadingThroughputRunner
+}
+
+func (m *MockedVerifyingThroughputRunner) Verify(test int, options interface{}) error {
+	args := m.Called(test, options)
+
+	return args.Error(0)
+}
+
+type repeatOptions struct {
+	repeat int
+}
+
+func (o *repeatOptions) GetRepeat() int {
+	return o.repeat
+}
+
+func (o *repeatOptions) GetCooldown() time.Duration {
+	return time.Millisecond
+}
+
+// MockedSummarizingThroughputRunner summarizes the trials of a repeated test.
+type MockedSummarizingThroughputRunner struct {
+	MockedLoadingThroughputRunner
+}
+
+func (m *MockedSummarizingThroughputRunner) Summarize(test int, options interface{}) {
+	m.Called(test, options)
+}
+
+// repeatedRunner mocks a runner for a single test repeated in trials.
+func repeatedRunner(t *MockedTester, o interface{}, test, trials int) *MockedSummarizingThroughputRunner {
+	r := new(MockedSummarizingThroughputRunner)
+	r.On("Tester").Return(t).Once()
+	t.On("Before", o).Return(nil).Once()
+	t.On("After", o).Once()
+	t.On("BeforeEach", o).Return(nil).Times(trials)
+	t.On("AfterEach", o).Times(trials)
+	r.On("Before", test, o).Return(nil).Times(trials)
+	r.On("After", test, o).Times(trials)
+
+	for i := 0; i < trials; i++ {
+		recorder := make(chan interface{})
+		r.On("Recorder").Return(recorder).Twice()
+		r.On("Load", recorder).Once()
+	}
+
+	r.On("Recorders").Return([]bender.Recorder{}).Times(trials)
+	r.On("Summarize", test, o).Once()
+
+	return r
+}
+
 type ThroughputFixedTestSuite struct {
 	suite.Suite
 	tester  *MockedTester
@@ -112,7 +178,7 @@ func (s *ThroughputFixedTestSuite) TestTester__Before_Error() {
 	s.runner.On("Tester").Return(s.tester).Once()
 	s.tester.On("Before", s.options).Return(ErrDummy).Once()
 
-	err := run.LoadTestThroughputFixed(s.runner, s.options, 10)
+	err := run.LoadTestThroughputFixed(context.Background(), s.runner, s.options, 10)
 	s.Assert().Equal(ErrDummy, err)
 
 	s.tester.AssertExpectations(s.T())
@@ -126,7 +192,7 @@ func (s *ThroughputFixedTestSuite) TestTester__BeforeEach_Error() {
 	s.tester.On("After", s.options).Once()
 	s.tester.On("BeforeEach", s.options).Return(ErrDummy).Once()
 
-	err := run.LoadTestThroughputFixed(s.runner, s.options, 10)
+	err := run.LoadTestThroughputFixed(context.Background(), s.runner, s.options, 10)
 	s.Assert().Equal(ErrDummy, err)
 
 	s.tester.AssertExpectations(s.T())
@@ -138,7 +204,7 @@ func (s *ThroughputFixedTestSuite) TestTester__BeforeEach_Error() {
 	s.tester.On("After", s.options).Once()
 	s.tester.On("BeforeEach", s.options).Return(ErrDummy).Once()
 
-	err = run.LoadTestThroughputFixed(s.runner, s.options, 10, 20)
+	err = run.LoadTestThroughputFixed(context.Background(), s.runner, s.options, 10, 20)
 	s.Assert().Equal(ErrDummy, err)
 
 	s.tester.AssertExpectations(s.T())
@@ -154,7 +220,7 @@ func (s *ThroughputFixedTestSuite) TestRunner__Before_Error() {
 	s.tester.On("AfterEach", s.options).Once()
 	s.runner.On("Before", 10, s.options).Return(ErrDummy).Once()
 
-	err := run.LoadTestThroughputFixed(s.runner, s.options, 10)
+	err := run.LoadTestThroughputFixed(context.Background(), s.runner, s.options, 10)
 	s.Assert().Equal(ErrDummy, err)
 
 	s.tester.AssertExpectations(s.T())
@@ -168,7 +234,7 @@ func (s *ThroughputFixedTestSuite) TestRunner__Before_Error() {
 	s.tester.On("AfterEach", s.options).Once()
 	s.runner.On("Before", 10, s.options).Return(ErrDummy).Once()
 
-	err = run.LoadTestThroughputFixed(s.runner, s.options, 10, 20)
+	err = run.LoadTestThroughputFixed(context.Background(), s.runner, s.options, 10, 20)
 	s.Assert().Equal(ErrDummy, err)
 
 	s.tester.AssertExpectations(s.T())
@@ -186,7 +252,7 @@ func (s *ThroughputFixedTestSuite) TestTester__RequestExecutor_Error() {
 	s.runner.On("After", 10, s.options).Once()
 	s.tester.On("RequestExecutor", s.options).Return(ErrDummy).Once()
 
-	err := run.LoadTestThroughputFixed(s.runner, s.options, 10)
+	err := run.LoadTestThroughputFixed(context.Background(), s.runner, s.options, 10)
 	s.Assert().Equal(ErrDummy, err)
 
 	s.tester.AssertExpectations(s.T())
@@ -202,7 +268,7 @@ func (s *ThroughputFixedTestSuite) TestTester__RequestExecutor_Error() {
 	s.runner.On("After", 10, s.options).Once()
 	s.tester.On("RequestExecutor", s.options).Return(ErrDummy).Once()
 
-	err = run.LoadTestThroughputFixed(s.runner, s.options, 10, 20)
+	err = run.LoadTestThroughputFixed(context.Background(), s.runner, s.options, 10, 20)
 	s.Assert().Equal(ErrDummy, err)
 
 	s.tester.AssertExpectations(s.T())
@@ -214,7 +280,7 @@ func (s *ThroughputFixedTestSuite) TestZero() {
 	s.tester.On("Before", s.options).Return(nil).Once()
 	s.tester.On("After", s.options).Once()
 
-	err := run.LoadTestThroughputFixed(s.runner, s.options)
+	err := run.LoadTestThroughputFixed(context.Background(), s.runner, s.options)
 	s.Assert().NoError(err)
 
 	s.tester.AssertExpectations(s.T())
@@ -240,7 +306,7 @@ func (s *ThroughputFixedTestSuite) TestSingle() {
 	s.runner.On("Recorder").Return(recorder).Twice()
 	s.runner.On("Recorders").Return([]bender.Recorder{}).Once()
 
-	err := run.LoadTestThroughputFixed(s.runner, s.options, 10)
+	err := run.LoadTestThroughputFixed(context.Background(), s.runner, s.options, 10)
 	s.Assert().NoError(err)
 
 	s.tester.AssertExpectations(s.T())
@@ -264,13 +330,60 @@ func (s *ThroughputFixedTestSuite) TestSingle() {
 	s.runner.On("Recorder").Return(recorder).Twice()
 	s.runner.On("Recorders").Return([]bender.Recorder{}).Once()
 
-	err = run.LoadTestThroughputFixed(s.runner, s.options, 10)
+	err = run.LoadTestThroughputFixed(context.Background(), s.runner, s.options, 10)
 	s.Assert().NoError(err)
 
 	s.tester.AssertExpectations(s.T())
 	s.runner.AssertExpectations(s.T())
 }
 
+func (s *ThroughputFixedTestSuite) TestLoader() {
+	// Runner generates the load on its own, the tester executor is not used
+	r := new(MockedLoadingThroughputRunner)
+	r.On("Tester").Return(s.tester).Once()
+	s.tester.On("Before", s.options).Return(nil).Once()
+	s.tester.On("After", s.options).Once()
+	s.tester.On("BeforeEach", s.options).Return(nil).Once()
+	s.tester.On("AfterEach", s.options).Once()
+	r.On("Before", 10, s.options).Return(nil).Once()
+	r.On("After", 10, s.options).Once()
+
+	recorder := make(chan interface{})
+	r.On("Recorder").Return(recorder).Twice()
+	r.On("Load", recorder).Once()
+	r.On("Recorders").Return([]bender.Recorder{}).Once()
+
+	err := run.LoadTestThroughputFixed(context.Background(), r, s.options, 10)
+	s.Assert().NoError(err)
+
+	s.tester.AssertExpectations(s.T())
+	r.AssertExpectations(s.T())
+}
+
+func (s *ThroughputFixedTestSuite) TestVerifier() {
+	// Runner fails the test after it's finished
+	r := new(MockedVerifyingThroughputRunner)
+	r.On("Tester").Return(s.tester).Once()
+	s.tester.On("Before", s.options).Return(nil).Once()
+	s.tester.On("After", s.options).Once()
+	s.tester.On("BeforeEach", s.options).Return(nil).Once()
+	s.tester.On("AfterEach", s.options).Once()
+	r.On("Before", 10, s.options).Return(nil).Once()
+	r.On("After", 10, s.options).Once()
+
+	recorder := make(chan interface{})
+	r.On("Recorder").Return(recorder).Twice()
+	r.On("Load", recorder).Once()
+	r.On("Recorders").Return([]bender.Recorder{}).Once()
+	r.On("Verify", 10, s.options).Return(tester.ErrSaturated).Once()
+
+	err := run.LoadTestThroughputFixed(context.Background(), r, s.options, 10)
+	s.Assert().ErrorIs(err, tester.ErrSaturated)
+
+	s.tester.AssertExpectations(s.T())
+	r.AssertExpectations(s.T())
+}
+
 func (s *ThroughputFixedTestSuite) TestMultiple() {
 	// Make sure Before/After gets called only once
 	s.runner.On("Tester").Return(s.tester).Once()
@@ -307,11 +420,117 @@ func (s *ThroughputFixedTestSuite) TestMultiple() {
 	s.runner.On("Recorder").Return(recorder).Twice()
 
 	// Run tests
-	err := run.LoadTestThroughputFixed(s.runner, s.options, 10, 20)
+	err := run.LoadTestThroughputFixed(context.Background(), s.runner, s.options, 10, 20)
+	s.Assert().NoError(err)
+
+	s.tester.AssertExpectations(s.T())
+	s.runner.AssertExpectations(s.T())
+}
+
+func (s *ThroughputFixedTestSuite) TestInterrupted() {
+	// Tests should not be started once the context is canceled
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	s.runner.On("Tester").Return(s.tester).Once()
+	s.tester.On("Before", s.options).Return(nil).Once()
+	s.tester.On("After", s.options).Once()
+
+	err := run.LoadTestThroughputFixed(ctx, s.runner, s.options, 10, 20)
+	s.Assert().Equal(run.ErrInterrupted, err)
+
+	s.tester.AssertExpectations(s.T())
+	s.runner.AssertExpectations(s.T())
+}
+
+type inflightOptions struct {
+	maxInflight int
+	drop        bool
+}
+
+func (o *inflightOptions) GetMaxInflight() int {
+	return o.maxInflight
+}
+
+func (o *inflightOptions) GetDropOverflow() bool {
+	return o.drop
+}
+
+// inflightTest runs a single test of n requests, each taking 75ms and sent
+// every 50ms. It returns the maximum number of requests in flight and the
+// number of requests dropped.
+func (s *ThroughputFixedTestSuite) inflightTest(o *inflightOptions, n, executed int) (int, int) {
+	s.runner.On("Tester").Return(s.tester).Once()
+	s.tester.On("Before", o).Return(nil).Once()
+	s.tester.On("After", o).Once()
+	s.tester.On("BeforeEach", o).Return(nil).Once()
+	s.tester.On("AfterEach", o).Once()
+	s.runner.On("Before", 20, o).Return(nil).Once()
+	s.runner.On("After", 20, o).Once()
+	s.tester.On("RequestExecutor", o).Return(nil).Once()
+	s.tester.On("DummyExecutor", mock.Anything, mock.Anything).
+		Return(nil, nil).After(75 * time.Millisecond).Times(executed)
+
+	requests := make(chan interface{}, n)
+	for i := 0; i < n; i++ {
+		requests <- i
+	}
+
+	close(requests)
+	s.runner.On("Requests").Return(requests).Once()
+	s.runner.On("Intervals").Return(bender.UniformIntervalGenerator(20)).Once()
+
+	inflight, maxInflight, dropped := 0, 0, 0
+	recorder := make(chan interface{}, 4*n)
+	s.runner.On("Recorder").Return(recorder).Twice()
+	s.runner.On("Recorders").Return([]bender.Recorder{func(msg interface{}) {
+		switch msg.(type) {
+		case *bender.StartRequestEvent:
+			inflight++
+			if inflight > maxInflight {
+				maxInflight = inflight
+			}
+		case *bender.EndRequestEvent:
+			inflight--
+		case *tester.DroppedRequestEvent:
+			dropped++
+		}
+	}}).Once()
+
+	err := run.LoadTestThroughputFixed(context.Background(), s.runner, o, 20)
 	s.Assert().NoError(err)
 
 	s.tester.AssertExpectations(s.T())
 	s.runner.AssertExpectations(s.T())
+
+	return maxInflight, dropped
+}
+
+func (s *ThroughputFixedTestSuite) TestMaxInflight() {
+	// Requests wait for a free slot once the limit is reached
+	maxInflight, dropped := s.inflightTest(&inflightOptions{maxInflight: 1}, 3, 3)
+	s.Assert().Equal(1, maxInflight)
+	s.Assert().Equal(0, dropped)
+}
+
+func (s *ThroughputFixedTestSuite) TestMaxInflight_Drop() {
+	// Requests over the limit are dropped, every other request is sent while
+	// the previous one is still in flight
+	maxInflight, dropped := s.inflightTest(&inflightOptions{maxInflight: 1, drop: true}, 4, 2)
+	s.Assert().Equal(1, maxInflight)
+	s.Assert().Equal(2, dropped)
+}
+
+func (s *ThroughputFixedTestSuite) TestRepeat() {
+	// Every test is run in multiple trials and summarized afterwards
+	o := &repeatOptions{repeat: 3}
+	r := repeatedRunner(s.tester, o, 10, 3)
+
+	err := run.LoadTestThroughputFixed(context.Background(), r, o, 10)
+	s.Assert().NoError(err)
+
+	s.tester.AssertExpectations(s.T())
+	r.AssertExpectations(s.T())
 }
 
 func TestThroughputFixedTestSuite(t *testing.T) {
@@ -353,7 +572,7 @@ func (s *ThroughputConstraintsTestSuite) TestTester__Before_Error() {
 	s.runner.On("Tester").Return(s.tester).Once()
 	s.tester.On("Before", s.options).Return(ErrDummy).Once()
 
-	err := run.LoadTestThroughputConstraints(s.runner, s.options, 10, s.growth)
+	err := run.LoadTestThroughputConstraints(context.Background(), s.runner, s.options, 10, s.growth)
 	s.Assert().Equal(ErrDummy, err)
 
 	s.tester.AssertExpectations(s.T())
@@ -367,7 +586,7 @@ func (s *ThroughputConstraintsTestSuite) TestTester__BeforeEach_Error() {
 	s.tester.On("After", s.options).Once()
 	s.tester.On("BeforeEach", s.options).Return(ErrDummy).Once()
 
-	err := run.LoadTestThroughputConstraints(s.runner, s.options, 10, s.growth)
+	err := run.LoadTestThroughputConstraints(context.Background(), s.runner, s.options, 10, s.growth)
 	s.Assert().Equal(ErrDummy, err)
 
 	s.tester.AssertExpectations(s.T())
@@ -383,7 +602,7 @@ func (s *ThroughputConstraintsTestSuite) TestRunner__Before_Error() {
 	s.tester.On("AfterEach", s.options).Once()
 	s.runner.On("Before", 10, s.options).Return(ErrDummy).Once()
 
-	err := run.LoadTestThroughputConstraints(s.runner, s.options, 10, s.growth)
+	err := run.LoadTestThroughputConstraints(context.Background(), s.runner, s.options, 10, s.growth)
 	s.Assert().Equal(ErrDummy, err)
 
 	s.tester.AssertExpectations(s.T())
@@ -401,7 +620,7 @@ func (s *ThroughputConstraintsTestSuite) TestTester__RequestExecutor_Error() {
 	s.runner.On("After", 10, s.options).Once()
 	s.tester.On("RequestExecutor", s.options).Return(ErrDummy).Once()
 
-	err := run.LoadTestThroughputConstraints(s.runner, s.options, 10, s.growth)
+	err := run.LoadTestThroughputConstraints(context.Background(), s.runner, s.options, 10, s.growth)
 	s.Assert().Equal(ErrDummy, err)
 
 	s.tester.AssertExpectations(s.T())
@@ -431,7 +650,7 @@ func (s *ThroughputConstraintsTestSuite) TestSingle_OnSuccess() {
 
 	s.growth.On("OnSuccess", 10).Return(0).Once()
 
-	err := run.LoadTestThroughputConstraints(s.runner, s.options, 10, s.growth, c.Constraint())
+	err := run.LoadTestThroughputConstraints(context.Background(), s.runner, s.options, 10, s.growth, c.Constraint())
 	s.Assert().NoError(err)
 
 	s.tester.AssertExpectations(s.T())
@@ -440,6 +659,69 @@ func (s *ThroughputConstraintsTestSuite) TestSingle_OnSuccess() {
 	c.AssertExpectations(s.T())
 }
 
+type abortOptions struct {
+	abortOn, abortWindow time.Duration
+}
+
+func (o *abortOptions) GetAbortOn() time.Duration {
+	return o.abortOn
+}
+
+func (o *abortOptions) GetAbortWindow() time.Duration {
+	return o.abortWindow
+}
+
+// MockedWaitingThroughputRunner generates the load until the test is canceled.
+type MockedWaitingThroughputRunner struct {
+	MockedThroughputRunner
+}
+
+func (m *MockedWaitingThroughputRunner) Load(ctx context.Context, recorder chan interface{}) {
+	m.Called(recorder)
+
+	go func() {
+		<-ctx.Done()
+		close(recorder)
+	}()
+}
+
+func (s *ThroughputConstraintsTestSuite) TestSingle_Aborted() {
+	// Test is canceled once the constraints are violated during the test and
+	// it's considered failed without checking the constraints again
+	o := &abortOptions{abortOn: time.Nanosecond, abortWindow: time.Second}
+	r := new(MockedWaitingThroughputRunner)
+	r.On("Tester").Return(s.tester).Once()
+	s.tester.On("Before", o).Return(nil).Once()
+	s.tester.On("After", o).Once()
+	s.tester.On("BeforeEach", o).Return(nil).Once()
+	s.tester.On("AfterEach", o).Once()
+	r.On("Before", 10, o).Return(nil).Once()
+	r.On("After", 10, o).Once()
+
+	recorder := make(chan interface{})
+	r.On("Recorder").Return(recorder).Twice()
+	r.On("Load", recorder).Once()
+	r.On("Recorders").Return([]bender.Recorder{}).Once()
+
+	// The first violation starts the abort period, the second one ends it and
+	// only then the violated constraint is logged
+	c := NewMockedConstraint(false)
+	c.Metric.On("Fetch", mock.Anything, mock.Anything).Return([]tester.DataPoint{}, nil).Once()
+	c.Aggregator.On("Aggregate", []tester.DataPoint{}).Return(float64(50)).Once()
+	c.Comparator.On("Compare", float64(50), float64(100)).Return(false).Once()
+	c.Comparator.On("Name").Return("?").Once()
+
+	s.growth.On("OnFail", 10).Return(0).Once()
+
+	err := run.LoadTestThroughputConstraints(context.Background(), r, o, 10, s.growth, c.Constraint())
+	s.Assert().NoError(err)
+
+	s.tester.AssertExpectations(s.T())
+	r.AssertExpectations(s.T())
+	s.growth.AssertExpectations(s.T())
+	c.AssertExpectations(s.T())
+}
+
 func (s *ThroughputConstraintsTestSuite) TestSingle_OnFail() {
 	s.runner.On("Tester").Return(s.tester).Once()
 	s.tester.On("Before", s.options).Return(nil).Once()
@@ -462,7 +744,7 @@ func (s *ThroughputConstraintsTestSuite) TestSingle_OnFail() {
 
 	s.growth.On("OnFail", 10).Return(0).Once()
 
-	err := run.LoadTestThroughputConstraints(s.runner, s.options, 10, s.growth, c.Constraint())
+	err := run.LoadTestThroughputConstraints(context.Background(), s.runner, s.options, 10, s.growth, c.Constraint())
 	s.Assert().NoError(err)
 
 	s.tester.AssertExpectations(s.T())
@@ -513,7 +795,7 @@ func (s *ThroughputConstraintsTestSuite) TestMultiple() {
 	s.growth.On("OnSuccess", 10).Return(20).Once()
 	s.growth.On("OnFail", 20).Return(0).Once()
 
-	err := run.LoadTestThroughputConstraints(s.runner, s.options, 10, s.growth, c.Constraint())
+	err := run.LoadTestThroughputConstraints(context.Background(), s.runner, s.options, 10, s.growth, c.Constraint())
 	s.Assert().NoError(err)
 
 	s.tester.AssertExpectations(s.T())
@@ -522,6 +804,52 @@ func (s *ThroughputConstraintsTestSuite) TestMultiple() {
 	c.AssertExpectations(s.T())
 }
 
+func (s *ThroughputConstraintsTestSuite) TestInterrupted() {
+	// Growth should not be consulted once the context is canceled
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	s.runner.On("Tester").Return(s.tester).Once()
+	s.tester.On("Before", s.options).Return(nil).Once()
+	s.tester.On("After", s.options).Once()
+
+	err := run.LoadTestThroughputConstraints(ctx, s.runner, s.options, 10, s.growth)
+	s.Assert().Equal(run.ErrInterrupted, err)
+
+	s.tester.AssertExpectations(s.T())
+	s.runner.AssertExpectations(s.T())
+	s.growth.AssertExpectations(s.T())
+}
+
+func (s *ThroughputConstraintsTestSuite) TestRepeat() {
+	// Constraints are checked once on the data points of all the trials
+	o := &repeatOptions{repeat: 2}
+	r := repeatedRunner(s.tester, o, 10, 2)
+
+	p1 := []tester.DataPoint{{Value: 1}}
+	p2 := []tester.DataPoint{{Value: 2}}
+	c := &MockedConstraint{
+		Metric:     new(MockedMetric),
+		Aggregator: new(MockedAggregator),
+		Comparator: new(MockedComparator),
+		Threshold:  float64(100),
+	}
+	c.Metric.On("Fetch", mock.Anything, mock.Anything).Return(p1, nil).Once()
+	c.Metric.On("Fetch", mock.Anything, mock.Anything).Return(p2, nil).Once()
+	c.Aggregator.On("Aggregate", append(p1, p2...)).Return(float64(50)).Once()
+	c.Comparator.On("Compare", float64(50), float64(100)).Return(true).Once()
+
+	s.growth.On("OnSuccess", 10).Return(0).Once()
+
+	err := run.LoadTestThroughputConstraints(context.Background(), r, o, 10, s.growth, c.Constraint())
+	s.Assert().NoError(err)
+
+	s.tester.AssertExpectations(s.T())
+	r.AssertExpectations(s.T())
+	s.growth.AssertExpectations(s.T())
+	c.AssertExpectations(s.T())
+}
+
 func TestThroughputConstraintsTestSuite(t *testing.T) {
 	suite.Run(t, new(ThroughputConstraintsTestSuite))
 }
@@ -0,0 +1,257 @@
+/*
+Copyright (c) Facebook, Inc. and its affiliates.
+All rights reserved.
+
+This source code is licensed under the BSD-style license found in the
+LICENSE file in the root directory of this source tree.
+*/
+
+package tester
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pinterest/bender"
+)
+
+// Target is an endpoint to load test. Weight is used only by the weighted
+// target selection.
+type Target struct {
+	Address string
+	Weight  float64
+}
+
+func (t *Target) String() string {
+	return t.Address
+}
+
+// Targets is a list of endpoints to load test.
+type Targets []*Target
+
+// Addresses returns the target addresses.
+func (t Targets) Addresses() []string {
+	addresses := make([]string, 0, len(t))
+	for _, target := range t {
+		addresses = append(addresses, target.Address)
+	}
+
+	return addresses
+}
+
+// Weights returns the target weights.
+func (t Targets) Weights() []float64 {
+	weights := make([]float64, 0, len(t))
+	for _, target := range t {
+		weights = append(weights, target.Weight)
+	}
+
+	return weights
+}
+
+// ErrInvalidTarget is returned when a target cannot be parsed.
+var ErrInvalidTarget = errors.New("invalid target")
+
+// ParseTarget creates a target from its string representation "Address" or
+// "Address Weight". The weight defaults to 1. Whitespace never appears in the
+// addresses (or URLs), so it separates the weight unambiguously.
+func ParseTarget(s string) (*Target, error) {
+	fields := strings.Fields(s)
+
+	switch len(fields) {
+	case 0:
+		return nil, fmt.Errorf("%w, empty address: %q", ErrInvalidTarget, s)
+	case 1:
+		return &Target{Address: fields[0], Weight: 1}, nil
+	case 2:
+	default:
+		return nil, fmt.Errorf("%w, want: \"Address [Weight]\", got: %q", ErrInvalidTarget, s)
+	}
+
+	w, err := strconv.ParseFloat(fields[1], 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w, invalid weight: %v", ErrInvalidTarget, err)
+	}
+
+	if w <= 0 {
+		return nil, fmt.Errorf("%w, non-positive weight: %q", ErrInvalidTarget, s)
+	}
+
+	return &Target{Address: fields[0], Weight: w}, nil
+}
+
+// ParseTargets creates targets from "Address [Weight]" lines. Empty lines and
+// lines starting with # are skipped.
+func ParseTargets(r io.Reader) (Targets, error) {
+	targets := Targets{}
+	scanner := bufio.NewScanner(r)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		target, err := ParseTarget(line)
+		if err != nil {
+			return nil, err
+		}
+
+		targets = append(targets, target)
+	}
+
+	if err := scanner.Err(); err != nil {
+		//nolint:wrapcheck
+		return nil, err
+	}
+
+	return targets, nil
+}
+
+// Selector returns an index of the target for the i-th request.
+type Selector func(i int) int
+
+// Selection creates a selector for targets with the given weights.
+type Selection func(weights []float64) Selector
+
+// RoundRobinSelection cycles through the targets ignoring their weights.
+func RoundRobinSelection(weights []float64) Selector {
+	return func(i int) int {
+		return i % len(weights)
+	}
+}
+
+// RandomSelection picks targets at random ignoring their weights.
+func RandomSelection(weights []float64) Selector {
+	return func(_ int) int {
+		//nolint:gosec
+		return rand.Intn(len(weights))
+	}
+}
+
+// WeightedSelection picks targets at random with probability proportional to
+// their weights.
+func WeightedSelection(weights []float64) Selector {
+	cumulative := make([]float64, len(weights))
+	sum := 0.
+
+	for i, weight := range weights {
+		sum += weight
+		cumulative[i] = sum
+	}
+
+	return func(_ int) int {
+		//nolint:gosec
+		x := rand.Float64() * sum
+
+		return sort.Search(len(cumulative)-1, func(i int) bool {
+			return cumulative[i] > x
+		})
+	}
+}
+
+// TargetedRequest is a request bound to one of the targets of a MultiTester.
+type TargetedRequest struct {
+	Target  int
+	Request interface{}
+}
+
+// TargetedResponse is a response returned by a MultiTester executor. It holds
+// the address of the target the request was sent to.
+type TargetedResponse struct {
+	Target   string
+	Response interface{}
+}
+
+// ErrInvalidRequest is returned when a MultiTester executor gets a request
+// which is not bound to any of its targets.
+var ErrInvalidRequest = errors.New("invalid request")
+
+// MultiTester spreads the load among multiple targets, each of them tested by
+// its own tester. It executes only TargetedRequests and wraps all responses in
+// TargetedResponses.
+type MultiTester struct {
+	Targets Targets
+	Testers []Tester
+}
+
+// Before is called before the first test.
+func (t *MultiTester) Before(options interface{}) error {
+	for i, tester := range t.Testers {
+		if err := tester.Before(options); err != nil {
+			for _, tester := range t.Testers[:i] {
+				tester.After(options)
+			}
+
+			//nolint:wrapcheck
+			return err
+		}
+	}
+
+	return nil
+}
+
+// After is called after all tests are finished.
+func (t *MultiTester) After(options interface{}) {
+	for _, tester := range t.Testers {
+		tester.After(options)
+	}
+}
+
+// BeforeEach is called before every test.
+func (t *MultiTester) BeforeEach(options interface{}) error {
+	for i, tester := range t.Testers {
+		if err := tester.BeforeEach(options); err != nil {
+			for _, tester := range t.Testers[:i] {
+				tester.AfterEach(options)
+			}
+
+			//nolint:wrapcheck
+			return err
+		}
+	}
+
+	return nil
+}
+
+// AfterEach is called after every test.
+func (t *MultiTester) AfterEach(options interface{}) {
+	for _, tester := range t.Testers {
+		tester.AfterEach(options)
+	}
+}
+
+// RequestExecutor returns a request executor which sends every request to the
+// executor of its target.
+func (t *MultiTester) RequestExecutor(ctx context.Context, options interface{}) (bender.RequestExecutor, error) {
+	executors := make([]bender.RequestExecutor, 0, len(t.Testers))
+
+	for _, tester := range t.Testers {
+		executor, err := tester.RequestExecutor(ctx, options)
+		if err != nil {
+			//nolint:wrapcheck
+			return nil, err
+		}
+
+		executors = append(executors, executor)
+	}
+
+	return func(n int64, request interface{}) (interface{}, error) {
+		targeted, ok := request.(*TargetedRequest)
+		if !ok || targeted.Target < 0 || targeted.Target >= len(executors) {
+			return nil, fmt.Errorf("%w, want: *TargetedRequest, got: %T", ErrInvalidRequest, request)
+		}
+
+		response, err := executors[targeted.Target](n, targeted.Request)
+
+		//nolint:wrapcheck
+		return &TargetedResponse{Target: t.Targets[targeted.Target].Address, Response: response}, err
+	}, nil
+}
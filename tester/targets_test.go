@@ -0,0 +1,145 @@
+/*
+Copyright (c) Facebook, Inc. and its affiliates.
+All rights reserved.
+
+This source code is licensed under the BSD-style license found in the
+LICENSE file in the root directory of this source tree.
+*/
+
+package tester_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/facebookincubator/fbender/tester"
+	"github.com/pinterest/bender"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTarget(t *testing.T) {
+	target, err := tester.ParseTarget("[::1]:53")
+	require.NoError(t, err)
+	assert.Equal(t, &tester.Target{Address: "[::1]:53", Weight: 1}, target)
+
+	target, err = tester.ParseTarget("example.com 2.5")
+	require.NoError(t, err)
+	assert.Equal(t, &tester.Target{Address: "example.com", Weight: 2.5}, target)
+
+	// Equal signs are a part of the address.
+	target, err = tester.ParseTarget("http://example.com/p?q=1")
+	require.NoError(t, err)
+	assert.Equal(t, &tester.Target{Address: "http://example.com/p?q=1", Weight: 1}, target)
+
+	for _, s := range []string{"", " ", "example.com 0", "example.com -1", "example.com heavy", "example.com 1 2"} {
+		_, err := tester.ParseTarget(s)
+		assert.ErrorIs(t, err, tester.ErrInvalidTarget, "target: %q", s)
+	}
+}
+
+func TestParseTargets(t *testing.T) {
+	targets, err := tester.ParseTargets(strings.NewReader(`
+# anycast pool
+10.0.0.1:53
+10.0.0.2:53 3
+`))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.1:53", "10.0.0.2:53"}, targets.Addresses())
+	assert.Equal(t, []float64{1, 3}, targets.Weights())
+
+	_, err = tester.ParseTargets(strings.NewReader("10.0.0.1:53 1 2"))
+	assert.ErrorIs(t, err, tester.ErrInvalidTarget)
+}
+
+func TestSelections(t *testing.T) {
+	weights := []float64{1, 0.000001, 3}
+
+	roundRobin := tester.RoundRobinSelection(weights)
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, i%3, roundRobin(i))
+	}
+
+	counts := make([]int, 3)
+	random := tester.RandomSelection(weights)
+
+	for i := 0; i < 3000; i++ {
+		counts[random(i)]++
+	}
+
+	for _, count := range counts {
+		assert.InDelta(t, 1000, count, 200)
+	}
+
+	counts = make([]int, 3)
+	weighted := tester.WeightedSelection(weights)
+
+	for i := 0; i < 4000; i++ {
+		counts[weighted(i)]++
+	}
+
+	assert.InDelta(t, 1000, counts[0], 200)
+	assert.InDelta(t, 0, counts[1], 5)
+	assert.InDelta(t, 3000, counts[2], 200)
+}
+
+type targetTester struct {
+	before, after int
+}
+
+func (t *targetTester) Before(_ interface{}) error {
+	t.before++
+
+	return nil
+}
+
+func (t *targetTester) After(_ interface{}) {
+	t.after++
+}
+
+func (t *targetTester) BeforeEach(_ interface{}) error {
+	return assert.AnError
+}
+
+func (t *targetTester) AfterEach(_ interface{}) {
+	t.after++
+}
+
+func (t *targetTester) RequestExecutor(_ context.Context, _ interface{}) (bender.RequestExecutor, error) {
+	return func(_ int64, request interface{}) (interface{}, error) {
+		return request, nil
+	}, nil
+}
+
+func TestMultiTester(t *testing.T) {
+	testers := []*targetTester{{}, {}}
+	multi := &tester.MultiTester{
+		Targets: tester.Targets{{Address: "a", Weight: 1}, {Address: "b", Weight: 1}},
+		Testers: []tester.Tester{testers[0], testers[1]},
+	}
+
+	require.NoError(t, multi.Before(nil))
+	assert.Equal(t, 1, testers[0].before)
+	assert.Equal(t, 1, testers[1].before)
+
+	// The first tester fails, no cleanup is needed
+	assert.ErrorIs(t, multi.BeforeEach(nil), assert.AnError)
+	assert.Equal(t, 0, testers[0].after)
+
+	executor, err := multi.RequestExecutor(context.Background(), nil)
+	require.NoError(t, err)
+
+	response, err := executor(0, &tester.TargetedRequest{Target: 1, Request: "request"})
+	require.NoError(t, err)
+	assert.Equal(t, &tester.TargetedResponse{Target: "b", Response: "request"}, response)
+
+	_, err = executor(0, "request")
+	assert.ErrorIs(t, err, tester.ErrInvalidRequest)
+	_, err = executor(0, &tester.TargetedRequest{Target: 2})
+	assert.ErrorIs(t, err, tester.ErrInvalidRequest)
+
+	multi.After(nil)
+	assert.Equal(t, 1, testers[0].after)
+	assert.Equal(t, 1, testers[1].after)
+}
@@ -9,6 +9,7 @@ LICENSE file in the root directory of this source tree.
 package tester
 
 import (
+	"context"
 	"errors"
 
 	"github.com/pinterest/bender"
@@ -17,6 +18,10 @@ import (
 // ErrInvalidOptions is thrown when options don't implement the required interface.
 var ErrInvalidOptions = errors.New("invalid options")
 
+// ErrSaturated is returned when the load generator couldn't keep up with the
+// requested load.
+var ErrSaturated = errors.New("load generator saturated")
+
 // Tester is used to setup the test for a specific endpoint.
 type Tester interface {
 	// Before is called once, before any tests.
@@ -30,7 +35,9 @@ type Tester interface {
 	// be used to cleanup everything that was set up in the BeforeEach.
 	AfterEach(options interface{})
 	// RequestExecutor is called every time a test is to be ran to get an executor.
-	RequestExecutor(options interface{}) (bender.RequestExecutor, error)
+	// The context is canceled once the test is interrupted and the in-flight
+	// requests had their time to finish.
+	RequestExecutor(ctx context.Context, options interface{}) (bender.RequestExecutor, error)
 }
 
 // QPS is the test desired queries per second.
@@ -38,8 +45,9 @@ type QPS = int
 
 // ThroughputRunner is used to setup the test execution.
 type ThroughputRunner interface {
-	// Before is called before running a test.
-	Before(qps QPS, options interface{}) error
+	// Before is called before running a test. The runner should stop
+	// generating requests once the context is canceled.
+	Before(ctx context.Context, qps QPS, options interface{}) error
 	// After is called after test finishes. This should be used to clean up
 	// everything that was ser up in the Before.
 	After(qps QPS, options interface{})
@@ -54,13 +62,35 @@ type ThroughputRunner interface {
 	Recorders() []bender.Recorder
 }
 
+// Loader may be implemented by a runner which generates the load on its own
+// (e.g. delegates it to remote agents) instead of using the tester request
+// executor. Load should send all the events to the recorder and close it once
+// the load is finished. The load should be stopped once the context is canceled.
+type Loader interface {
+	Load(ctx context.Context, recorder chan interface{})
+}
+
+// Summarizer may be implemented by a runner to summarize repeated trials of
+// a single test. Summarize is called once all the trials are finished.
+type Summarizer interface {
+	Summarize(test int, options interface{})
+}
+
+// Verifier may be implemented by a runner to verify a finished test, e.g. that
+// the load was generated as requested. An error returned by Verify fails the
+// test.
+type Verifier interface {
+	Verify(test int, options interface{}) error
+}
+
 // Workers is the test desired concurrent workers.
 type Workers = int
 
 // ConcurrencyRunner is used to setup concurrency test execution.
 type ConcurrencyRunner interface {
-	// Before is called before running a test.
-	Before(workers Workers, options interface{}) error
+	// Before is called before running a test. The runner should stop
+	// generating requests once the context is canceled.
+	Before(ctx context.Context, workers Workers, options interface{}) error
 	// After is called after test finishes. This should be used to clean up
 	// everything that was ser up in the Before.
 	After(workers Workers, options interface{})
@@ -9,6 +9,7 @@ LICENSE file in the root directory of this source tree.
 package tftp
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -53,6 +54,6 @@ func (t *Tester) BeforeEach(_ interface{}) error {
 func (t *Tester) AfterEach(_ interface{}) {}
 
 // RequestExecutor returns a request executor.
-func (t *Tester) RequestExecutor(_ interface{}) (bender.RequestExecutor, error) {
+func (t *Tester) RequestExecutor(_ context.Context, _ interface{}) (bender.RequestExecutor, error) {
 	return protocol.CreateExecutor(t.client, protocol.DiscardingValidator), nil
 }
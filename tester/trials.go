@@ -0,0 +1,129 @@
+/*
+Copyright (c) Facebook, Inc. and its affiliates.
+All rights reserved.
+
+This source code is licensed under the BSD-style license found in the
+LICENSE file in the root directory of this source tree.
+*/
+
+package tester
+
+import (
+	"math"
+)
+
+// Summary describes the samples of a measure gathered in repeated trials of
+// a single test.
+type Summary struct {
+	Name   string
+	Trials int
+	Mean   float64
+	StdDev float64
+	// Bounds of the 95% confidence interval of the mean
+	Low, High float64
+}
+
+// tCritical holds the two-sided 95% critical values of the Student's
+// t-distribution for 1 to 30 degrees of freedom.
+//nolint:gochecknoglobals
+var tCritical = []float64{
+	12.706, 4.303, 3.182, 2.776, 2.571, 2.447, 2.365, 2.306, 2.262, 2.228,
+	2.201, 2.179, 2.160, 2.145, 2.131, 2.120, 2.110, 2.101, 2.093, 2.086,
+	2.080, 2.074, 2.069, 2.064, 2.060, 2.056, 2.052, 2.048, 2.045, 2.042,
+}
+
+// critical returns the two-sided 95% critical value of the Student's
+// t-distribution. For more than 30 degrees of freedom the value for the
+// nearest lower tabulated degrees of freedom is used.
+func critical(df int) float64 {
+	switch {
+	case df <= len(tCritical):
+		return tCritical[df-1]
+	case df < 40:
+		return tCritical[len(tCritical)-1]
+	case df < 60:
+		return 2.021
+	case df < 120:
+		return 2.000
+	default:
+		return 1.980
+	}
+}
+
+// Summarize calculates the mean, the sample standard deviation and the 95%
+// confidence interval of the mean of the samples.
+func Summarize(name string, samples []float64) *Summary {
+	summary := &Summary{Name: name, Trials: len(samples)}
+	if len(samples) == 0 {
+		return summary
+	}
+
+	for _, sample := range samples {
+		summary.Mean += sample
+	}
+
+	summary.Mean /= float64(len(samples))
+	summary.Low, summary.High = summary.Mean, summary.Mean
+
+	if len(samples) < 2 {
+		return summary
+	}
+
+	for _, sample := range samples {
+		summary.StdDev += (sample - summary.Mean) * (sample - summary.Mean)
+	}
+
+	summary.StdDev = math.Sqrt(summary.StdDev / float64(len(samples)-1))
+
+	margin := critical(len(samples)-1) * summary.StdDev / math.Sqrt(float64(len(samples)))
+	summary.Low, summary.High = summary.Mean-margin, summary.Mean+margin
+
+	return summary
+}
+
+// Trials gathers samples of measures in repeated trials of a single test.
+type Trials struct {
+	names   []string
+	samples map[string][]float64
+	count   int
+}
+
+// Add adds the measures of a single trial.
+func (t *Trials) Add(names []string, values []float64) {
+	if t.samples == nil {
+		t.samples = make(map[string][]float64)
+	}
+
+	for i, name := range names {
+		if _, ok := t.samples[name]; !ok {
+			t.names = append(t.names, name)
+		}
+
+		t.samples[name] = append(t.samples[name], values[i])
+	}
+
+	t.count++
+}
+
+// Count returns the number of trials added.
+func (t *Trials) Count() int {
+	return t.count
+}
+
+// Summaries returns the summaries of all the measures in the order they were
+// first added.
+func (t *Trials) Summaries() []*Summary {
+	summaries := make([]*Summary, 0, len(t.names))
+	for _, name := range t.names {
+		summaries = append(summaries, Summarize(name, t.samples[name]))
+	}
+
+	return summaries
+}
+
+// Reset removes all the samples.
+func (t *Trials) Reset() {
+	t.names = nil
+	t.samples = nil
+	t.count = 0
+}
@@ -0,0 +1,51 @@
+/*
+Copyright (c) Facebook, Inc. and its affiliates.
+All rights reserved.
+
+This source code is licensed under the BSD-style license found in the
+LICENSE file in the root directory of this source tree.
+*/
+
+package tester_test
+
+import (
+	"testing"
+
+	"github.com/facebookincubator/fbender/tester"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSummarize(t *testing.T) {
+	summary := tester.Summarize("latency", []float64{10, 12, 14})
+	assert.Equal(t, "latency", summary.Name)
+	assert.Equal(t, 3, summary.Trials)
+	assert.InDelta(t, 12, summary.Mean, 1e-9)
+	assert.InDelta(t, 2, summary.StdDev, 1e-9)
+	// 12 +- 4.303 * 2 / sqrt(3)
+	assert.InDelta(t, 7.031, summary.Low, 1e-3)
+	assert.InDelta(t, 16.969, summary.High, 1e-3)
+
+	summary = tester.Summarize("errors", []float64{5})
+	assert.Equal(t, &tester.Summary{Name: "errors", Trials: 1, Mean: 5, Low: 5, High: 5}, summary)
+
+	summary = tester.Summarize("errors", nil)
+	assert.Equal(t, &tester.Summary{Name: "errors"}, summary)
+}
+
+func TestTrials(t *testing.T) {
+	trials := new(tester.Trials)
+	trials.Add([]string{"errors", "p50"}, []float64{1, 10})
+	trials.Add([]string{"errors", "p50"}, []float64{3, 20})
+	assert.Equal(t, 2, trials.Count())
+
+	summaries := trials.Summaries()
+	assert.Len(t, summaries, 2)
+	assert.Equal(t, "errors", summaries[0].Name)
+	assert.InDelta(t, 2, summaries[0].Mean, 1e-9)
+	assert.Equal(t, "p50", summaries[1].Name)
+	assert.InDelta(t, 15, summaries[1].Mean, 1e-9)
+
+	trials.Reset()
+	assert.Equal(t, 0, trials.Count())
+	assert.Empty(t, trials.Summaries())
+}
@@ -9,6 +9,7 @@ LICENSE file in the root directory of this source tree.
 package udp
 
 import (
+	"context"
 	"time"
 
 	protocol "github.com/facebookincubator/fbender/protocols/udp"
@@ -43,7 +44,7 @@ func validator(_ *protocol.Datagram, _ []byte) error {
 }
 
 // RequestExecutor returns a request executor.
-func (t *Tester) RequestExecutor(options interface{}) (bender.RequestExecutor, error) {
+func (t *Tester) RequestExecutor(_ context.Context, options interface{}) (bender.RequestExecutor, error) {
 	if t.Validator == nil {
 		return protocol.CreateExecutor(t.Timeout, validator, t.Target), nil
 	}